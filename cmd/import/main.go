@@ -0,0 +1,95 @@
+// Command import upserts products by sku from a CSV or NDJSON file into the product storage
+// configured like for the server, and prints the import report as JSON.
+//
+//	import [-format csv|ndjson] [-dry-run] [-batch-size n] [-actor name] [-reason text] file
+//
+// The format defaults to the file extension; a file of "-" reads standard input.
+// The actor and reason are recorded in the history of every product the import changes.
+// The exit status is 1 when the import stopped early and 2 when rows failed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dariuszdroba/go-from-template/config"
+	"github.com/dariuszdroba/go-from-template/internal/app"
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+)
+
+func main() {
+	format := flag.String("format", "", "input format, csv or ndjson (default: from the file extension)")
+	dryRun := flag.Bool("dry-run", false, "roll back every batch and only report what would change")
+	batchSize := flag.Int("batch-size", 0, "rows per transaction (default 500)")
+	actor := flag.String("actor", os.Getenv("USER"), "who is recorded as having made the changes")
+	reason := flag.String("reason", "", `why the changes are made (default "import of <file>")`)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] file\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// Configuration
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("Config error: %s", err)
+	}
+
+	name := flag.Arg(0)
+	if *format == "" {
+		*format = formatOf(name)
+	}
+
+	var in io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			log.Fatalf("Open error: %s", err)
+		}
+		defer f.Close()
+
+		in = f
+	}
+
+	if *reason == "" {
+		*reason = "import of " + filepath.Base(name)
+	}
+
+	report, err := app.Import(cfg, in, *format, usecase.ImportOptions{DryRun: *dryRun, BatchSize: *batchSize},
+		entity.Change{Actor: *actor, Reason: *reason})
+	if report != nil {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	}
+
+	switch {
+	case err != nil:
+		log.Printf("Import error: %s", err)
+		os.Exit(1) //nolint:gocritic // the file is only read
+	case report.Failed > 0:
+		os.Exit(2)
+	}
+}
+
+// formatOf returns the import format of a file name by its extension.
+func formatOf(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".ndjson", ".jsonl":
+		return usecase.ImportFormatNDJSON
+	default:
+		return usecase.ImportFormatCSV
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -9,11 +10,14 @@ import (
 type (
 	// Config -.
 	Config struct {
-		App  `yaml:"app"`
-		HTTP `yaml:"http"`
-		Log  `yaml:"logger"`
-		PG   `yaml:"postgres"`
-		RMQ  `yaml:"rabbitmq"`
+		App         `yaml:"app"`
+		HTTP        `yaml:"http"`
+		Log         `yaml:"logger"`
+		PG          `yaml:"postgres"`
+		MySQL       `yaml:"mysql"`
+		RMQ         `yaml:"rabbitmq"`
+		Translation `yaml:"translation"`
+		Product     `yaml:"product"`
 	}
 
 	// App -.
@@ -24,7 +28,8 @@ type (
 
 	// HTTP -.
 	HTTP struct {
-		Port string `env-required:"true" yaml:"port" env:"HTTP_PORT"`
+		Port        string   `env-required:"true" yaml:"port"         env:"HTTP_PORT"`
+		CORSOrigins []string `                    yaml:"cors_origins" env:"HTTP_CORS_ORIGINS"`
 	}
 
 	// Log -.
@@ -38,11 +43,41 @@ type (
 		URL     string `env-required:"true"                 env:"PG_URL"`
 	}
 
+	// MySQL -.
+	MySQL struct {
+		PoolMax int    `yaml:"pool_max" env:"MYSQL_POOL_MAX"`
+		URL     string `                env:"MYSQL_URL"`
+	}
+
 	// RMQ -.
 	RMQ struct {
 		ServerExchange string `env-required:"true" yaml:"rpc_server_exchange" env:"RMQ_RPC_SERVER"`
 		ClientExchange string `env-required:"true" yaml:"rpc_client_exchange" env:"RMQ_RPC_CLIENT"`
 		URL            string `env-required:"true"                            env:"RMQ_URL"`
+		// EventsExchange, when set, is the topic exchange product events are published to.
+		EventsExchange string `yaml:"events_exchange" env:"RMQ_EVENTS_EXCHANGE"`
+	}
+
+	// Translation -.
+	Translation struct {
+		Enabled bool `yaml:"enabled" env:"TRANSLATION_ENABLED"`
+	}
+
+	// Product -.
+	Product struct {
+		Enabled          bool          `                    yaml:"enabled"           env:"PRODUCT_ENABLED"`
+		Storage          string        `env-required:"true" yaml:"storage"           env:"PRODUCT_STORAGE"`
+		ScheduleInterval time.Duration `env-required:"true" yaml:"schedule_interval" env:"PRODUCT_SCHEDULE_INTERVAL"`
+		// RatesFile, when set, is the JSON file exchange rates are synced from every RatesSyncInterval.
+		RatesFile         string        `yaml:"rates_file"          env:"PRODUCT_RATES_FILE"`
+		RatesSyncInterval time.Duration `yaml:"rates_sync_interval" env:"PRODUCT_RATES_SYNC_INTERVAL"`
+		// DefaultLocale is the locale products are written in; other locales are served from translations.
+		DefaultLocale string `env-default:"en" yaml:"default_locale" env:"PRODUCT_DEFAULT_LOCALE"`
+		// TranslateLocales, when set and translation is enabled, are machine-translated into every TranslateInterval.
+		TranslateLocales  []string      `yaml:"translate_locales"  env:"PRODUCT_TRANSLATE_LOCALES"  env-separator:","`
+		TranslateInterval time.Duration `yaml:"translate_interval" env:"PRODUCT_TRANSLATE_INTERVAL"`
+		// EventsInterval is how often product events are relayed to rabbitmq.events_exchange.
+		EventsInterval time.Duration `yaml:"events_interval" env:"PRODUCT_EVENTS_INTERVAL"`
 	}
 )
 
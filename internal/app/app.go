@@ -7,22 +7,26 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
 	"github.com/dariuszdroba/go-from-template/config"
 	amqprpc "github.com/dariuszdroba/go-from-template/internal/controller/amqp_rpc"
 	v1 "github.com/dariuszdroba/go-from-template/internal/controller/http/v1"
+	v2 "github.com/dariuszdroba/go-from-template/internal/controller/http/v2"
 	"github.com/dariuszdroba/go-from-template/internal/usecase"
 	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
 	"github.com/dariuszdroba/go-from-template/internal/usecase/webapi"
 	"github.com/dariuszdroba/go-from-template/pkg/httpserver"
 	"github.com/dariuszdroba/go-from-template/pkg/logger"
 	"github.com/dariuszdroba/go-from-template/pkg/postgres"
+	"github.com/dariuszdroba/go-from-template/pkg/rabbitmq/publisher"
 	"github.com/dariuszdroba/go-from-template/pkg/rabbitmq/rmq_rpc/server"
+	"github.com/dariuszdroba/go-from-template/pkg/worker"
 )
 
 // Run creates objects via constructors.
-func Run(cfg *config.Config) {
+func Run(cfg *config.Config) { //nolint:funlen,cyclop // wires every module
 	l := logger.New(cfg.Log.Level)
 
 	// Repository
@@ -33,34 +37,137 @@ func Run(cfg *config.Config) {
 	defer pg.Close()
 
 	// Use case
-	translationUseCase := usecase.New(
-		repository.New(pg),
-		webapi.New(),
+	var (
+		translationUseCase usecase.Translation
+		productTranslator  usecase.Translation
 	)
+	if cfg.Translation.Enabled {
+		translationRepo, translationWebAPI := repository.New(pg), webapi.New()
+		translationUseCase = usecase.New(
+			translationRepo,
+			translationWebAPI,
+		)
+		// Machine translations of products are kept with the products, not in the translation history.
+		productTranslator = usecase.New(translationRepo, translationWebAPI, usecase.SkipHistory())
+	}
+
+	var (
+		productUseCase      usecase.ProductUseCase
+		exchangeRateUseCase usecase.ExchangeRateUseCase
+		catalogUseCase      usecase.CatalogUseCase
+		variantUseCase      usecase.VariantUseCase
+		inventoryUseCase    usecase.InventoryUseCase
+		productTranslations usecase.ProductTranslationUseCase
+		productEvents       usecase.ProductEventUseCase
+		eventPublisher      *publisher.Publisher
+	)
+	if cfg.Product.Enabled {
+		repos, closeRepos, err := newProductRepositories(cfg, pg)
+		if err != nil {
+			l.Fatal(fmt.Errorf("app - Run - newProductRepositories: %w", err))
+		}
+		defer closeRepos()
+
+		productUseCase = usecase.NewProductUseCase(repos.products)
+
+		var rates usecase.ExchangeRateProvider
+		if cfg.Product.RatesFile != "" {
+			rates = webapi.NewExchangeRatesFile(cfg.Product.RatesFile)
+		}
+		exchangeRateUseCase = usecase.NewExchangeRateUseCase(repos.exchangeRates, rates)
+		catalogUseCase = usecase.NewCatalogUseCase(repos.catalog, repos.products)
+		variantUseCase = usecase.NewVariantUseCase(repos.variants, repos.products)
+		inventoryUseCase = usecase.NewInventoryUseCase(repos.inventory, repos.products)
+		productTranslations = usecase.NewProductTranslationUseCase(repos.translations, repos.products,
+			productTranslator, cfg.Product.DefaultLocale, cfg.Product.TranslateLocales)
+
+		// Without an exchange, events stay in the outbox until one is configured.
+		var events usecase.EventPublisher
+		if cfg.RMQ.EventsExchange != "" {
+			eventPublisher = publisher.New(cfg.RMQ.URL, cfg.RMQ.EventsExchange)
+			events = eventPublisher
+		}
+		productEvents = usecase.NewProductEventUseCase(repos.events, events)
+	}
 
 	// RabbitMQ RPC Server
-	rmqRouter := amqprpc.NewRouter(translationUseCase)
+	var rmqServer *server.Server
+	if translationUseCase != nil {
+		rmqRouter := amqprpc.NewRouter(translationUseCase)
 
-	rmqServer, err := server.New(cfg.RMQ.URL, cfg.RMQ.ServerExchange, rmqRouter, l)
-	if err != nil {
-		l.Fatal(fmt.Errorf("app - Run - rmqServer - server.New: %w", err))
+		rmqServer, err = server.New(cfg.RMQ.URL, cfg.RMQ.ServerExchange, rmqRouter, l)
+		if err != nil {
+			l.Fatal(fmt.Errorf("app - Run - rmqServer - server.New: %w", err))
+		}
+	}
+
+	// Scheduled product changes, exchange rates, product translations and product events
+	var scheduleWorker, ratesWorker, translateWorker, eventsWorker *worker.Worker
+	if productUseCase != nil {
+		scheduleWorker = worker.New("product schedule", applyDueChanges(productUseCase, l), l,
+			worker.Interval(cfg.Product.ScheduleInterval))
+
+		if cfg.Product.RatesFile != "" {
+			opts := []worker.Option{worker.RunOnStart()}
+			if cfg.Product.RatesSyncInterval > 0 {
+				opts = append(opts, worker.Interval(cfg.Product.RatesSyncInterval))
+			}
+			ratesWorker = worker.New("exchange rates", syncExchangeRates(exchangeRateUseCase, l), l, opts...)
+		}
+
+		if productTranslator != nil && len(cfg.Product.TranslateLocales) > 0 {
+			opts := []worker.Option{worker.RunOnStart()}
+			if cfg.Product.TranslateInterval > 0 {
+				opts = append(opts, worker.Interval(cfg.Product.TranslateInterval))
+			}
+			translateWorker = worker.New("product translations", fillTranslations(productTranslations, l), l, opts...)
+		}
+
+		if eventPublisher != nil {
+			opts := []worker.Option{worker.RunOnStart()}
+			if cfg.Product.EventsInterval > 0 {
+				opts = append(opts, worker.Interval(cfg.Product.EventsInterval))
+			}
+			eventsWorker = worker.New("product events", publishProductEvents(productEvents, l), l, opts...)
+		}
 	}
 
 	// HTTP Server
 	handler := gin.New()
+	if len(cfg.HTTP.CORSOrigins) > 0 {
+		handler.Use(cors.New(cors.Config{
+			AllowOrigins:     cfg.HTTP.CORSOrigins,
+			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "If-Match", "X-Actor", "X-Change-Reason", "X-Request-ID"},
+			ExposeHeaders:    []string{"Content-Length", "ETag", "X-Request-ID"},
+			AllowCredentials: true,
+		}))
+	}
+
 	v1.NewRouter(handler, l, translationUseCase)
+	if productUseCase != nil {
+		v2.NewRouter(handler, l, productUseCase, exchangeRateUseCase, catalogUseCase, variantUseCase, inventoryUseCase,
+			productTranslations)
+	}
+
 	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
 
 	// Waiting signal
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
 
+	// A nil channel never fires, so a disabled RPC server is simply ignored here.
+	var rmqNotify <-chan error
+	if rmqServer != nil {
+		rmqNotify = rmqServer.Notify()
+	}
+
 	select {
 	case s := <-interrupt:
 		l.Info("app - Run - signal: " + s.String())
 	case err = <-httpServer.Notify():
 		l.Error(fmt.Errorf("app - Run - httpServer.Notify: %w", err))
-	case err = <-rmqServer.Notify():
+	case err = <-rmqNotify:
 		l.Error(fmt.Errorf("app - Run - rmqServer.Notify: %w", err))
 	}
 
@@ -70,8 +177,45 @@ func Run(cfg *config.Config) {
 		l.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err))
 	}
 
-	err = rmqServer.Shutdown()
-	if err != nil {
-		l.Error(fmt.Errorf("app - Run - rmqServer.Shutdown: %w", err))
+	if rmqServer != nil {
+		err = rmqServer.Shutdown()
+		if err != nil {
+			l.Error(fmt.Errorf("app - Run - rmqServer.Shutdown: %w", err))
+		}
+	}
+
+	if scheduleWorker != nil {
+		err = scheduleWorker.Shutdown()
+		if err != nil {
+			l.Error(fmt.Errorf("app - Run - scheduleWorker.Shutdown: %w", err))
+		}
+	}
+
+	if ratesWorker != nil {
+		err = ratesWorker.Shutdown()
+		if err != nil {
+			l.Error(fmt.Errorf("app - Run - ratesWorker.Shutdown: %w", err))
+		}
+	}
+
+	if translateWorker != nil {
+		err = translateWorker.Shutdown()
+		if err != nil {
+			l.Error(fmt.Errorf("app - Run - translateWorker.Shutdown: %w", err))
+		}
+	}
+
+	if eventsWorker != nil {
+		err = eventsWorker.Shutdown()
+		if err != nil {
+			l.Error(fmt.Errorf("app - Run - eventsWorker.Shutdown: %w", err))
+		}
+	}
+
+	if eventPublisher != nil {
+		err = eventPublisher.Shutdown()
+		if err != nil {
+			l.Error(fmt.Errorf("app - Run - eventPublisher.Shutdown: %w", err))
+		}
 	}
 }
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/dariuszdroba/go-from-template/config"
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/pkg/postgres"
+)
+
+// Import upserts products from r into the configured product storage, without starting the server;
+// change is recorded with the versions it creates.
+func Import(cfg *config.Config, r io.Reader, format string, opts usecase.ImportOptions, change entity.Change) (*usecase.ImportReport, error) {
+	var pg *postgres.Postgres
+	if cfg.Product.Storage == _productStoragePostgres {
+		var err error
+
+		pg, err = postgres.New(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.PoolMax))
+		if err != nil {
+			return nil, fmt.Errorf("app - Import - postgres.New: %w", err)
+		}
+		defer pg.Close()
+	}
+
+	repos, closeRepos, err := newProductRepositories(cfg, pg)
+	if err != nil {
+		return nil, fmt.Errorf("app - Import - newProductRepositories: %w", err)
+	}
+	defer closeRepos()
+
+	reader, err := usecase.NewImportReader(format, r)
+	if err != nil {
+		return nil, fmt.Errorf("app - Import - usecase.NewImportReader: %w", err)
+	}
+
+	ctx := entity.WithChange(context.Background(), change)
+
+	report, err := usecase.NewProductUseCase(repos.products).Import(ctx, reader, opts)
+	if err != nil {
+		return report, fmt.Errorf("app - Import - uc.Import: %w", err)
+	}
+
+	return report, nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/golang-migrate/migrate/v4"
 	// migrate tools
+	_ "github.com/golang-migrate/migrate/v4/database/mysql"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
@@ -27,6 +28,16 @@ func init() {
 
 	databaseURL += "?sslmode=disable"
 
+	migrateUp("postgres", "file://migrations", databaseURL)
+
+	// MySQL is optional: products may still live in a separate MySQL database.
+	mysqlURL, ok := os.LookupEnv("MYSQL_URL")
+	if ok && len(mysqlURL) != 0 {
+		migrateUp("mysql", "file://migrations/mysql", "mysql://"+mysqlURL)
+	}
+}
+
+func migrateUp(driver, sourceURL, databaseURL string) {
 	var (
 		attempts = _defaultAttempts
 		err      error
@@ -34,30 +45,30 @@ func init() {
 	)
 
 	for attempts > 0 {
-		m, err = migrate.New("file://migrations", databaseURL)
+		m, err = migrate.New(sourceURL, databaseURL)
 		if err == nil {
 			break
 		}
 
-		log.Printf("Migrate: postgres is trying to connect, attempts left: %d", attempts)
+		log.Printf("Migrate: %s is trying to connect, attempts left: %d", driver, attempts)
 		time.Sleep(_defaultTimeout)
 		attempts--
 	}
 
 	if err != nil {
-		log.Fatalf("Migrate: postgres connect error: %s", err)
+		log.Fatalf("Migrate: %s connect error: %s", driver, err)
 	}
 
 	err = m.Up()
 	defer m.Close()
 	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		log.Fatalf("Migrate: up error: %s", err)
+		log.Fatalf("Migrate: %s up error: %s", driver, err)
 	}
 
 	if errors.Is(err, migrate.ErrNoChange) {
-		log.Printf("Migrate: no change")
+		log.Printf("Migrate: %s no change", driver)
 		return
 	}
 
-	log.Printf("Migrate: up success")
+	log.Printf("Migrate: %s up success", driver)
 }
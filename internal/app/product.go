@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dariuszdroba/go-from-template/config"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+	"github.com/dariuszdroba/go-from-template/pkg/logger"
+	"github.com/dariuszdroba/go-from-template/pkg/mysql"
+	"github.com/dariuszdroba/go-from-template/pkg/postgres"
+	"github.com/dariuszdroba/go-from-template/pkg/worker"
+)
+
+const (
+	_productStoragePostgres = "postgres"
+	_productStorageMySQL    = "mysql"
+)
+
+var errUnknownProductStorage = errors.New("unknown product storage")
+
+// productRepositories are the repositories of the product module, all in the store selected by product.storage.
+type productRepositories struct {
+	products      repository.ProductRepository
+	exchangeRates repository.ExchangeRateRepository
+	catalog       repository.CatalogRepository
+	variants      repository.VariantRepository
+	inventory     repository.InventoryRepository
+	translations  repository.ProductTranslationRepository
+	events        repository.ProductEventRepository
+}
+
+// newProductRepositories returns the product repositories and a func releasing any connection opened just for them.
+func newProductRepositories(cfg *config.Config, pg *postgres.Postgres) (productRepositories, func(), error) {
+	switch cfg.Product.Storage {
+	case _productStoragePostgres:
+		return productRepositories{
+			products:      repository.NewProductPostgresRepository(pg),
+			exchangeRates: repository.NewExchangeRatePostgresRepository(pg),
+			catalog:       repository.NewCatalogPostgresRepository(pg),
+			variants:      repository.NewVariantPostgresRepository(pg),
+			inventory:     repository.NewInventoryPostgresRepository(pg),
+			translations:  repository.NewProductTranslationPostgresRepository(pg),
+			events:        repository.NewProductEventPostgresRepository(pg),
+		}, func() {}, nil
+	case _productStorageMySQL:
+		my, err := mysql.New(cfg.MySQL.URL, mysql.MaxPoolSize(cfg.MySQL.PoolMax))
+		if err != nil {
+			return productRepositories{}, nil, fmt.Errorf("mysql.New: %w", err)
+		}
+
+		return productRepositories{
+			products:      repository.NewProductRepository(my.DB),
+			exchangeRates: repository.NewExchangeRateRepository(my.DB),
+			catalog:       repository.NewCatalogRepository(my.DB),
+			variants:      repository.NewVariantRepository(my.DB),
+			inventory:     repository.NewInventoryRepository(my.DB),
+			translations:  repository.NewProductTranslationRepository(my.DB),
+			events:        repository.NewProductEventRepository(my.DB),
+		}, my.Close, nil
+	default:
+		return productRepositories{}, nil, fmt.Errorf("%w: %q", errUnknownProductStorage, cfg.Product.Storage)
+	}
+}
+
+// applyDueChanges is the worker job applying scheduled product changes.
+func applyDueChanges(uc usecase.ProductUseCase, l logger.Interface) worker.Job {
+	return func(ctx context.Context) error {
+		n, err := uc.ApplyDueChanges(ctx)
+		if n > 0 {
+			l.Info("app - applyDueChanges - applied %d scheduled product changes", n)
+		}
+
+		if err != nil {
+			return fmt.Errorf("app - applyDueChanges - uc.ApplyDueChanges: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// syncExchangeRates is the worker job storing the rates of the configured provider.
+func syncExchangeRates(uc usecase.ExchangeRateUseCase, l logger.Interface) worker.Job {
+	return func(ctx context.Context) error {
+		n, err := uc.SyncRates(ctx)
+		if n > 0 {
+			l.Info("app - syncExchangeRates - stored %d exchange rates", n)
+		}
+
+		if err != nil {
+			return fmt.Errorf("app - syncExchangeRates - uc.SyncRates: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// fillTranslations is the worker job machine-translating products into the configured locales.
+func fillTranslations(uc usecase.ProductTranslationUseCase, l logger.Interface) worker.Job {
+	return func(ctx context.Context) error {
+		n, err := uc.FillMissing(ctx)
+		if n > 0 {
+			l.Info("app - fillTranslations - stored %d product translations", n)
+		}
+
+		if err != nil {
+			return fmt.Errorf("app - fillTranslations - uc.FillMissing: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// publishProductEvents is the worker job relaying product events from the outbox to the broker.
+func publishProductEvents(uc usecase.ProductEventUseCase, l logger.Interface) worker.Job {
+	return func(ctx context.Context) error {
+		n, err := uc.PublishPending(ctx)
+		if n > 0 {
+			l.Info("app - publishProductEvents - published %d product events", n)
+		}
+
+		if err != nil {
+			return fmt.Errorf("app - publishProductEvents - uc.PublishPending: %w", err)
+		}
+
+		return nil
+	}
+}
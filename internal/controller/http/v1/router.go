@@ -38,6 +38,11 @@ func NewRouter(handler *gin.Engine, l logger.Interface, t usecase.Translation) {
 	handler.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// Routers
+	// Translation is optional, see translation.enabled
+	if t == nil {
+		return
+	}
+
 	h := handler.Group("/v1")
 	{
 		newTranslationRoutes(h, t, l)
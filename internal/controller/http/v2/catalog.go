@@ -0,0 +1,273 @@
+package v2
+
+import (
+	"context"
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type CatalogHandler struct {
+	uc usecase.CatalogUseCase
+	l  logger.Interface
+}
+
+func NewCatalogHandler(uc usecase.CatalogUseCase, l logger.Interface) *CatalogHandler {
+	return &CatalogHandler{uc: uc, l: l}
+}
+
+func (h *CatalogHandler) RegisterRoutes(r gin.IRouter) {
+	categories := r.Group("/categories")
+	{
+		categories.GET("/", h.ListCategories)
+		categories.POST("/", h.CreateCategory)
+		categories.GET("/:id", h.GetCategory)
+		categories.PUT("/:id", h.UpdateCategory)
+		categories.DELETE("/:id", h.DeleteCategory)
+	}
+	tags := r.Group("/tags")
+	{
+		tags.GET("/", h.ListTags)
+		tags.POST("/", h.CreateTag)
+		tags.PUT("/:id", h.RenameTag)
+		tags.DELETE("/:id", h.DeleteTag)
+	}
+	products := r.Group("/products")
+	{
+		products.GET("/:id/categories", h.ProductCategories)
+		products.PUT("/:id/categories/:categoryId", h.AssignCategory)
+		products.DELETE("/:id/categories/:categoryId", h.UnassignCategory)
+		products.GET("/:id/tags", h.ProductTags)
+		products.PUT("/:id/tags/:tag", h.TagProduct)
+		products.DELETE("/:id/tags/:tag", h.UntagProduct)
+	}
+}
+
+// categoryRequest is the body of CreateCategory and UpdateCategory; a category without parent_id is a root.
+type categoryRequest struct {
+	ParentID  *uint64     `json:"parent_id" example:"1"`
+	Name      string      `json:"name" binding:"required,max=255" example:"Shoes"`
+	ID        interface{} `json:"id" binding:"isdefault" swaggerignore:"true"`
+	CreatedAt interface{} `json:"created_at" binding:"isdefault" swaggerignore:"true"`
+}
+
+func (h *CatalogHandler) ListCategories(c *gin.Context) {
+	ctx := context.Background()
+	categories, err := h.uc.ListCategories(ctx)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "ListCategories")
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+func (h *CatalogHandler) CreateCategory(c *gin.Context) {
+	var req categoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	ctx := context.Background()
+	category, err := h.uc.CreateCategory(ctx, &entity.Category{ParentID: req.ParentID, Name: req.Name})
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "CreateCategory")
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+func (h *CatalogHandler) GetCategory(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	category, err := h.uc.GetCategory(ctx, id)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "GetCategory")
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// UpdateCategory renames a category and sets its parent; moving it below one of its own subcategories is refused.
+func (h *CatalogHandler) UpdateCategory(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req categoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	ctx := context.Background()
+	category, err := h.uc.UpdateCategory(ctx, &entity.Category{ID: id, ParentID: req.ParentID, Name: req.Name})
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "UpdateCategory")
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory deletes a category without subcategories and removes it from its products.
+func (h *CatalogHandler) DeleteCategory(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	if err := h.uc.DeleteCategory(ctx, id); err != nil {
+		useCaseErrorResponse(c, h.l, err, "DeleteCategory")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type tagRequest struct {
+	Name string `json:"name" binding:"required,max=64" example:"summer-sale"`
+}
+
+func (h *CatalogHandler) ListTags(c *gin.Context) {
+	ctx := context.Background()
+	tags, err := h.uc.ListTags(ctx)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "ListTags")
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+func (h *CatalogHandler) CreateTag(c *gin.Context) {
+	var req tagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	ctx := context.Background()
+	tag, err := h.uc.CreateTag(ctx, req.Name)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "CreateTag")
+		return
+	}
+	c.JSON(http.StatusCreated, tag)
+}
+
+// RenameTag renames a tag on every product that has it.
+func (h *CatalogHandler) RenameTag(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req tagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	ctx := context.Background()
+	tag, err := h.uc.RenameTag(ctx, id, req.Name)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "RenameTag")
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+func (h *CatalogHandler) DeleteTag(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	if err := h.uc.DeleteTag(ctx, id); err != nil {
+		useCaseErrorResponse(c, h.l, err, "DeleteTag")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CatalogHandler) ProductCategories(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	categories, err := h.uc.ProductCategories(ctx, id)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "ProductCategories")
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// AssignCategory puts a product in a category; assigning it again changes nothing.
+func (h *CatalogHandler) AssignCategory(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	categoryID, ok := paramID(c, "categoryId")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	if err := h.uc.AssignCategory(ctx, id, categoryID); err != nil {
+		useCaseErrorResponse(c, h.l, err, "AssignCategory")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+func (h *CatalogHandler) UnassignCategory(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	categoryID, ok := paramID(c, "categoryId")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	if err := h.uc.UnassignCategory(ctx, id, categoryID); err != nil {
+		useCaseErrorResponse(c, h.l, err, "UnassignCategory")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+func (h *CatalogHandler) ProductTags(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	tags, err := h.uc.ProductTags(ctx, id)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "ProductTags")
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// TagProduct adds a tag to a product by name, creating the tag when it is new, and returns the tag.
+func (h *CatalogHandler) TagProduct(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	tag, err := h.uc.TagProduct(ctx, id, c.Param("tag"))
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "TagProduct")
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+func (h *CatalogHandler) UntagProduct(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	if err := h.uc.UntagProduct(ctx, id, c.Param("tag")); err != nil {
+		useCaseErrorResponse(c, h.l, err, "UntagProduct")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -1,13 +1,107 @@
 package v2
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/pkg/logger"
 )
 
 type response struct {
-	Error string `json:"error" example:"message"`
+	Error  string               `json:"error" example:"message"`
+	Fields []usecase.FieldError `json:"fields,omitempty"`
 }
 
 func errorResponse(c *gin.Context, code int, msg string) {
-	c.AbortWithStatusJSON(code, response{msg})
+	c.AbortWithStatusJSON(code, response{Error: msg})
+}
+
+// useCaseErrorResponse maps an error returned by the product use case to its status by kind.
+// Unexpected errors are logged under op and answered with a generic message.
+func useCaseErrorResponse(c *gin.Context, l logger.Interface, err error, op string) {
+	var ve *usecase.ValidationError
+
+	switch {
+	case errors.As(err, &ve):
+		c.AbortWithStatusJSON(http.StatusBadRequest, response{Error: usecase.ErrValidation.Error(), Fields: ve.Fields})
+	case errors.Is(err, usecase.ErrVersionConflict):
+		errorResponse(c, http.StatusPreconditionFailed, err.Error())
+	case errors.Is(err, usecase.ErrNotFound):
+		errorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, usecase.ErrValidation):
+		errorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, usecase.ErrConflict):
+		errorResponse(c, http.StatusConflict, err.Error())
+	default:
+		l.Error(err, "http - v2 - "+op)
+		errorResponse(c, http.StatusInternalServerError, "database problems")
+	}
+}
+
+// bindingErrorResponse answers 400 for a request that could not be bound,
+// listing the offending fields when the error names them.
+func bindingErrorResponse(c *gin.Context, err error) {
+	var (
+		verrs   validator.ValidationErrors
+		typeErr *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &verrs):
+		fields := make([]usecase.FieldError, 0, len(verrs))
+		for _, fe := range verrs {
+			fields = append(fields, usecase.FieldError{Field: bindingField(fe), Message: bindingMessage(fe)})
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, response{Error: usecase.ErrValidation.Error(), Fields: fields})
+	case errors.As(err, &typeErr):
+		c.AbortWithStatusJSON(http.StatusBadRequest, response{
+			Error:  usecase.ErrValidation.Error(),
+			Fields: []usecase.FieldError{{Field: typeErr.Field, Message: "must be of type " + typeErr.Type.String()}},
+		})
+	default:
+		errorResponse(c, http.StatusBadRequest, err.Error())
+	}
+}
+
+// bindingField is the dotted path of a field below the request, e.g. "price.amount".
+func bindingField(fe validator.FieldError) string {
+	_, field, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+
+	return field
+}
+
+func bindingMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "isdefault":
+		return "is read-only"
+	case "max":
+		if fe.Kind().String() == "string" {
+			return "must be at most " + fe.Param() + " characters"
+		}
+
+		return "must be at most " + fe.Param()
+	case "min":
+		return "must be at least " + fe.Param()
+	case "len":
+		return "must be exactly " + fe.Param() + " characters"
+	case "uppercase":
+		return "must be upper case"
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	default:
+		return fmt.Sprintf("failed the %q rule", fe.Tag())
+	}
 }
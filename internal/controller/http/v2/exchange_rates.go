@@ -0,0 +1,33 @@
+package v2
+
+import (
+	"context"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ExchangeRateHandler struct {
+	uc usecase.ExchangeRateUseCase
+	l  logger.Interface
+}
+
+func NewExchangeRateHandler(uc usecase.ExchangeRateUseCase, l logger.Interface) *ExchangeRateHandler {
+	return &ExchangeRateHandler{uc: uc, l: l}
+}
+
+func (h *ExchangeRateHandler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/exchange-rates/:base/:quote", h.ListRates)
+}
+
+// ListRates returns every rate a currency pair has had with its validity interval, oldest first.
+func (h *ExchangeRateHandler) ListRates(c *gin.Context) {
+	ctx := context.Background()
+	rates, err := h.uc.ListRates(ctx, c.Param("base"), c.Param("quote"))
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "ListRates")
+		return
+	}
+	c.JSON(http.StatusOK, rates)
+}
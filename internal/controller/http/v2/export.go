@@ -0,0 +1,294 @@
+package v2
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/pkg/logger"
+)
+
+// Export formats.
+const (
+	_exportCSV     = "csv"
+	_exportNDJSON  = "ndjson"
+	_exportParquet = "parquet"
+)
+
+const (
+	// _parquetBatchSize rows are buffered before they are handed to the parquet writer.
+	_parquetBatchSize = 1024
+	// _parquetRowGroupSize bounds the rows a parquet writer holds before it writes a row group.
+	_parquetRowGroupSize = 64 * 1024
+)
+
+// exportContentTypes -.
+var exportContentTypes = map[string]string{ //nolint:gochecknoglobals // immutable
+	_exportCSV:     "text/csv; charset=utf-8",
+	_exportNDJSON:  "application/x-ndjson",
+	_exportParquet: "application/vnd.apache.parquet",
+}
+
+type exportRequest struct {
+	Format         string    `form:"format" binding:"omitempty,oneof=csv ndjson parquet"`
+	UpdatedSince   time.Time `form:"updated_since"`
+	AsOf           time.Time `form:"as_of"`
+	IncludeDeleted bool      `form:"include_deleted"`
+}
+
+func (r *exportRequest) filter() *entity.ExportFilter {
+	return &entity.ExportFilter{UpdatedSince: r.UpdatedSince, AsOf: r.AsOf, IncludeDeleted: r.IncludeDeleted}
+}
+
+// ExportProducts streams the products ordered by id as CSV (the default), NDJSON or Parquet, with
+// updated_since keeping those updated since then and as_of (RFC 3339) exporting them as stored at that time.
+// Soft-deleted products are exported with include_deleted=true. An export stops when the client goes away.
+func (h *ProductHandler) ExportProducts(c *gin.Context) {
+	var req exportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+
+	enc := startExport[productRecord](c, req.Format, "products")
+	err := h.uc.ExportProducts(c.Request.Context(), req.filter(), func(p *entity.Product) error {
+		return enc.Encode(newProductRecord(p))
+	})
+	finishExport(c, h.l, enc, err, "ExportProducts")
+}
+
+// ExportHistory streams every product version like ExportProducts streams products. updated_since keeps the
+// versions opened or closed since then and as_of the history as it was known at that time.
+func (h *ProductHandler) ExportHistory(c *gin.Context) {
+	var req exportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+
+	enc := startExport[historyRecord](c, req.Format, "product_history")
+	err := h.uc.ExportHistory(c.Request.Context(), req.filter(), func(ph *entity.ProductHistory) error {
+		return enc.Encode(newHistoryRecord(ph))
+	})
+	finishExport(c, h.l, enc, err, "ExportHistory")
+}
+
+// startExport sets the headers of an export download named name and returns its encoder.
+func startExport[T exportRecord](c *gin.Context, format, name string) exportEncoder[T] {
+	if format == "" {
+		format = _exportCSV
+	}
+
+	c.Header("Content-Type", exportContentTypes[format])
+	c.Header("Content-Disposition", `attachment; filename="`+name+"."+format+`"`)
+	c.Status(http.StatusOK)
+
+	return newExportEncoder[T](format, c.Writer)
+}
+
+// finishExport completes an export that ended with err. The status cannot change once rows were sent,
+// so an export failing midway is cut short and only logged.
+func finishExport[T exportRecord](c *gin.Context, l logger.Interface, enc exportEncoder[T], err error, op string) {
+	if err == nil {
+		err = enc.Close()
+	}
+
+	switch {
+	case err == nil:
+	case c.Writer.Written():
+		l.Error(err, "http - v2 - "+op)
+		c.Abort()
+	default:
+		// The error is JSON, which gin only declares when no Content-Type is set yet.
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		useCaseErrorResponse(c, l, err, op)
+	}
+}
+
+// exportRecord is a flat row of an export, the same in every format.
+type exportRecord interface {
+	csvHeader() []string
+	csvRecord() []string
+}
+
+// exportEncoder writes records in one format. Close writes what is still buffered, e.g. the parquet footer.
+type exportEncoder[T exportRecord] interface {
+	Encode(rec T) error
+	Close() error
+}
+
+func newExportEncoder[T exportRecord](format string, w io.Writer) exportEncoder[T] {
+	switch format {
+	case _exportNDJSON:
+		return &ndjsonEncoder[T]{enc: json.NewEncoder(w)}
+	case _exportParquet:
+		return &parquetEncoder[T]{
+			w:    parquet.NewGenericWriter[T](w, parquet.MaxRowsPerRowGroup(_parquetRowGroupSize)),
+			rows: make([]T, 0, _parquetBatchSize),
+		}
+	default:
+		return &csvEncoder[T]{w: csv.NewWriter(w)}
+	}
+}
+
+type csvEncoder[T exportRecord] struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (e *csvEncoder[T]) Encode(rec T) error {
+	if !e.wroteHeader {
+		if err := e.w.Write(rec.csvHeader()); err != nil {
+			return err
+		}
+		e.wroteHeader = true
+	}
+	return e.w.Write(rec.csvRecord())
+}
+
+// Close -. An empty export still has its header.
+func (e *csvEncoder[T]) Close() error {
+	if !e.wroteHeader {
+		var zero T
+		if err := e.w.Write(zero.csvHeader()); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonEncoder[T exportRecord] struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder[T]) Encode(rec T) error {
+	return e.enc.Encode(rec)
+}
+
+func (e *ndjsonEncoder[T]) Close() error {
+	return nil
+}
+
+type parquetEncoder[T exportRecord] struct {
+	w    *parquet.GenericWriter[T]
+	rows []T
+}
+
+func (e *parquetEncoder[T]) Encode(rec T) error {
+	e.rows = append(e.rows, rec)
+	if len(e.rows) < cap(e.rows) {
+		return nil
+	}
+	return e.flush()
+}
+
+func (e *parquetEncoder[T]) flush() error {
+	_, err := e.w.Write(e.rows)
+	e.rows = e.rows[:0]
+	return err
+}
+
+func (e *parquetEncoder[T]) Close() error {
+	if err := e.flush(); err != nil {
+		return err
+	}
+	return e.w.Close()
+}
+
+// productRecord is a product in an export.
+type productRecord struct {
+	ID            uint64 `json:"id" parquet:"id"`
+	SKU           string `json:"sku,omitempty" parquet:"sku,optional"`
+	Name          string `json:"name" parquet:"name"`
+	Description   string `json:"description" parquet:"description"`
+	PriceAmount   int64  `json:"price_amount" parquet:"price_amount"`
+	PriceCurrency string `json:"price_currency" parquet:"price_currency"`
+	EffectiveFrom string `json:"effective_from,omitempty" parquet:"effective_from,optional"`
+	CreatedAt     string `json:"created_at" parquet:"created_at"`
+	UpdatedAt     string `json:"updated_at" parquet:"updated_at"`
+	Version       uint64 `json:"version" parquet:"version"`
+	DeletedAt     string `json:"deleted_at,omitempty" parquet:"deleted_at,optional"`
+}
+
+func newProductRecord(p *entity.Product) productRecord {
+	id, _ := strconv.ParseUint(p.ID, 10, 64)
+	return productRecord{
+		ID:            id,
+		SKU:           p.SKU,
+		Name:          p.Name,
+		Description:   p.Description,
+		PriceAmount:   p.Price.Amount,
+		PriceCurrency: p.Price.Currency,
+		EffectiveFrom: p.EffectiveFrom,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+		Version:       p.Version,
+		DeletedAt:     p.DeletedAt,
+	}
+}
+
+func (productRecord) csvHeader() []string {
+	return []string{"id", "sku", "name", "description", "price_amount", "price_currency",
+		"effective_from", "created_at", "updated_at", "version", "deleted_at"}
+}
+
+func (r productRecord) csvRecord() []string {
+	return []string{strconv.FormatUint(r.ID, 10), r.SKU, r.Name, r.Description,
+		strconv.FormatInt(r.PriceAmount, 10), r.PriceCurrency, r.EffectiveFrom, r.CreatedAt, r.UpdatedAt,
+		strconv.FormatUint(r.Version, 10), r.DeletedAt}
+}
+
+// historyRecord is a product version in an export.
+type historyRecord struct {
+	ID            int    `json:"id" parquet:"id"`
+	ProductID     int    `json:"product_id" parquet:"product_id"`
+	SKU           string `json:"sku,omitempty" parquet:"sku,optional"`
+	Name          string `json:"name" parquet:"name"`
+	Description   string `json:"description" parquet:"description"`
+	PriceAmount   int64  `json:"price_amount" parquet:"price_amount"`
+	PriceCurrency string `json:"price_currency" parquet:"price_currency"`
+	EffectiveFrom string `json:"effective_from,omitempty" parquet:"effective_from,optional"`
+	ValidFrom     string `json:"valid_from" parquet:"valid_from"`
+	ValidTo       string `json:"valid_to,omitempty" parquet:"valid_to,optional"`
+	CreatedAt     string `json:"created_at" parquet:"created_at"`
+	Version       uint64 `json:"version" parquet:"version"`
+	DeletedAt     string `json:"deleted_at,omitempty" parquet:"deleted_at,optional"`
+}
+
+func newHistoryRecord(h *entity.ProductHistory) historyRecord {
+	return historyRecord{
+		ID:            h.ID,
+		ProductID:     h.ProductID,
+		SKU:           h.SKU,
+		Name:          h.Name,
+		Description:   h.Description,
+		PriceAmount:   h.Price.Amount,
+		PriceCurrency: h.Price.Currency,
+		EffectiveFrom: h.EffectiveFrom,
+		ValidFrom:     h.ValidFrom,
+		ValidTo:       h.ValidTo,
+		CreatedAt:     h.CreatedAt,
+		Version:       h.Version,
+		DeletedAt:     h.DeletedAt,
+	}
+}
+
+func (historyRecord) csvHeader() []string {
+	return []string{"id", "product_id", "sku", "name", "description", "price_amount", "price_currency",
+		"effective_from", "valid_from", "valid_to", "created_at", "version", "deleted_at"}
+}
+
+func (r historyRecord) csvRecord() []string {
+	return []string{strconv.Itoa(r.ID), strconv.Itoa(r.ProductID), r.SKU, r.Name, r.Description,
+		strconv.FormatInt(r.PriceAmount, 10), r.PriceCurrency, r.EffectiveFrom, r.ValidFrom, r.ValidTo,
+		r.CreatedAt, strconv.FormatUint(r.Version, 10), r.DeletedAt}
+}
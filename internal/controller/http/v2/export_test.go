@@ -0,0 +1,170 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/pkg/logger"
+)
+
+// roundTrip encodes recs in format and decodes them back.
+func roundTrip[T exportRecord](t *testing.T, format string, recs []T) []T {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	enc := newExportEncoder[T](format, &buf)
+	for _, rec := range recs {
+		require.NoError(t, enc.Encode(rec))
+	}
+	require.NoError(t, enc.Close())
+
+	decoded := []T{}
+
+	switch format {
+	case _exportCSV:
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+
+		// The header is written even without records. There is no csv decoder of records, so they are
+		// compared here as their fields.
+		var zero T
+		require.NotEmpty(t, rows)
+		require.Equal(t, zero.csvHeader(), rows[0])
+		require.Len(t, rows, len(recs)+1)
+		for i, rec := range recs {
+			require.Equal(t, rec.csvRecord(), rows[i+1])
+		}
+
+		return recs
+	case _exportNDJSON:
+		dec := json.NewDecoder(&buf)
+		for {
+			var rec T
+			err := dec.Decode(&rec)
+			if errors.Is(err, io.EOF) {
+				return decoded
+			}
+			require.NoError(t, err)
+			decoded = append(decoded, rec)
+		}
+	default:
+		rows, err := parquet.Read[T](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+
+		return append(decoded, rows...)
+	}
+}
+
+func exportedProducts(n int) []productRecord {
+	recs := []productRecord{
+		{
+			ID: 1, SKU: "CHAIR-1", Name: `Chair, "oak"`, Description: "Seats one;\nlegs, four",
+			PriceAmount: 1999, PriceCurrency: "EUR", EffectiveFrom: "2030-01-01 00:00:00",
+			CreatedAt: "2020-01-01 00:00:00", UpdatedAt: "2020-01-02 00:00:00", Version: 2,
+		},
+		{
+			ID: 2, Name: "Zażółć", PriceCurrency: "PLN",
+			CreatedAt: "2020-01-01 00:00:00", UpdatedAt: "2020-01-01 00:00:00", Version: 3, DeletedAt: "2020-01-03 00:00:00",
+		},
+	}
+	for i := len(recs); i < n; i++ {
+		recs = append(recs, productRecord{
+			ID: uint64(i + 1), Name: "product " + strconv.Itoa(i+1), PriceAmount: int64(i), PriceCurrency: "USD",
+			CreatedAt: "2020-01-01 00:00:00", UpdatedAt: "2020-01-01 00:00:00", Version: 1,
+		})
+	}
+
+	return recs[:n]
+}
+
+func TestExportProductRecordsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		recs []productRecord
+	}{
+		{
+			name: "escaping and empty descriptions",
+			recs: exportedProducts(2),
+		},
+		{
+			name: "nothing to export",
+			recs: []productRecord{},
+		},
+		{
+			name: "more rows than a parquet batch",
+			recs: exportedProducts(_parquetBatchSize + 3),
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		for _, format := range []string{_exportCSV, _exportNDJSON, _exportParquet} {
+			format := format
+
+			t.Run(tc.name+"/"+format, func(t *testing.T) {
+				t.Parallel()
+
+				require.Equal(t, tc.recs, roundTrip(t, format, tc.recs))
+			})
+		}
+	}
+}
+
+func TestExportHistoryRecordsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	recs := []historyRecord{
+		{
+			ID: 1, ProductID: 1, SKU: "CHAIR-1", Name: "Chair", Description: `"Oak",
+sanded`,
+			PriceAmount: 1999, PriceCurrency: "EUR", ValidFrom: "2020-01-01 00:00:00", ValidTo: "2020-01-02 00:00:00",
+			CreatedAt: "2020-01-01 00:00:00", Version: 1,
+		},
+		{
+			ID: 2, ProductID: 1, SKU: "CHAIR-1", Name: "Chair", PriceAmount: 2499, PriceCurrency: "EUR",
+			EffectiveFrom: "2020-01-03 00:00:00", ValidFrom: "2020-01-02 00:00:00", CreatedAt: "2020-01-01 00:00:00", Version: 2,
+		},
+	}
+
+	for _, format := range []string{_exportCSV, _exportNDJSON, _exportParquet} {
+		format := format
+
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, recs, roundTrip(t, format, recs))
+		})
+	}
+}
+
+func TestFinishExportBeforeRows(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	enc := startExport[productRecord](c, _exportCSV, "products")
+	finishExport(c, logger.New("error"), enc, &usecase.Error{Kind: usecase.ErrValidation, Msg: "bad filter"}, "ExportProducts")
+
+	// An export failing before its first row answers like any other request.
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Empty(t, w.Header().Get("Content-Disposition"))
+	require.JSONEq(t, `{"error":"bad filter"}`, w.Body.String())
+}
@@ -0,0 +1,160 @@
+package v2
+
+import (
+	"context"
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type InventoryHandler struct {
+	uc usecase.InventoryUseCase
+	l  logger.Interface
+}
+
+func NewInventoryHandler(uc usecase.InventoryUseCase, l logger.Interface) *InventoryHandler {
+	return &InventoryHandler{uc: uc, l: l}
+}
+
+func (h *InventoryHandler) RegisterRoutes(r gin.IRouter) {
+	products := r.Group("/products")
+	{
+		products.GET("/:id/stock", h.GetStock)
+		products.GET("/:id/stock/movements", h.ListStockMovements)
+		products.POST("/:id/stock/receipts", h.ReceiveStock)
+		products.POST("/:id/stock/adjustments", h.AdjustStock)
+	}
+	stock := r.Group("/stock")
+	{
+		stock.POST("/reservations", h.ReserveStock)
+		stock.POST("/releases", h.ReleaseStock)
+		stock.POST("/fulfilments", h.FulfilStock)
+		stock.GET("/low", h.ListLowStock)
+	}
+}
+
+// stockQuantityRequest is the body of ReceiveStock and AdjustStock; adjustments may be negative.
+type stockQuantityRequest struct {
+	Quantity int64 `json:"quantity" binding:"required" example:"10"`
+}
+
+// stockItemsRequest is the body of ReserveStock, ReleaseStock and FulfilStock.
+type stockItemsRequest struct {
+	Reference string             `json:"reference" binding:"required" example:"order-1042"`
+	Items     []entity.StockItem `json:"items" binding:"required"`
+}
+
+type lowStockRequest struct {
+	Threshold int64  `form:"threshold" binding:"min=0"`
+	Limit     uint64 `form:"limit"`
+}
+
+func (h *InventoryHandler) GetStock(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	level, err := h.uc.Stock(ctx, id)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "GetStock")
+		return
+	}
+	c.JSON(http.StatusOK, level)
+}
+
+// ListStockMovements returns the stock ledger of a product, oldest first.
+func (h *InventoryHandler) ListStockMovements(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	movements, err := h.uc.Movements(ctx, id)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "ListStockMovements")
+		return
+	}
+	c.JSON(http.StatusOK, movements)
+}
+func (h *InventoryHandler) ReceiveStock(c *gin.Context) {
+	h.recordQuantity(c, h.uc.Receive, "ReceiveStock")
+}
+func (h *InventoryHandler) AdjustStock(c *gin.Context) {
+	h.recordQuantity(c, h.uc.Adjust, "AdjustStock")
+}
+
+// ReserveStock reserves every item for the reference, or none of them when one has too little
+// available stock, which answers 409.
+func (h *InventoryHandler) ReserveStock(c *gin.Context) {
+	h.recordItems(c, h.uc.Reserve, "ReserveStock")
+}
+
+// ReleaseStock returns items reserved for the reference to the available stock, all or none.
+func (h *InventoryHandler) ReleaseStock(c *gin.Context) {
+	h.recordItems(c, h.uc.Release, "ReleaseStock")
+}
+
+// FulfilStock takes items reserved for the reference off the stock on hand as they ship, all or none.
+// Repeating a reference that fulfilled an item with the same quantity changes nothing.
+func (h *InventoryHandler) FulfilStock(c *gin.Context) {
+	h.recordItems(c, h.uc.Fulfil, "FulfilStock")
+}
+
+// ListLowStock returns the products with at most ?threshold= available, 0 by default, lowest first.
+func (h *InventoryHandler) ListLowStock(c *gin.Context) {
+	var req lowStockRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	ctx := context.Background()
+	levels, err := h.uc.LowStock(ctx, req.Threshold, req.Limit)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "ListLowStock")
+		return
+	}
+	c.JSON(http.StatusOK, levels)
+}
+
+// recordQuantity binds a stockQuantityRequest and records it against the product of the path with record.
+func (h *InventoryHandler) recordQuantity(c *gin.Context,
+	record func(ctx context.Context, productID uint64, quantity int64) (*entity.StockLevel, error), op string,
+) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req stockQuantityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	ctx := changeContext(c)
+	level, err := record(ctx, id, req.Quantity)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, op)
+		return
+	}
+	c.JSON(http.StatusOK, level)
+}
+
+// recordItems binds a stockItemsRequest and records it with record.
+func (h *InventoryHandler) recordItems(c *gin.Context,
+	record func(ctx context.Context, reference string, items []entity.StockItem) ([]*entity.StockLevel, error), op string,
+) {
+	var req stockItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	ctx := changeContext(c)
+	levels, err := record(ctx, req.Reference, req.Items)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, op)
+		return
+	}
+	c.JSON(http.StatusOK, levels)
+}
@@ -4,17 +4,26 @@ import (
 	"context"
 	"github.com/dariuszdroba/go-from-template/internal/entity"
 	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/pkg/logger"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type ProductHandler struct {
-	uc usecase.ProductUseCase
+	uc           usecase.ProductUseCase
+	rates        usecase.ExchangeRateUseCase
+	translations usecase.ProductTranslationUseCase
+	l            logger.Interface
 }
 
-func NewProductHandler(uc usecase.ProductUseCase) *ProductHandler {
-	return &ProductHandler{uc: uc}
+func NewProductHandler(uc usecase.ProductUseCase, rates usecase.ExchangeRateUseCase, translations usecase.ProductTranslationUseCase,
+	l logger.Interface,
+) *ProductHandler {
+	return &ProductHandler{uc: uc, rates: rates, translations: translations, l: l}
 }
 
 func (h *ProductHandler) RegisterRoutes(r gin.IRouter) {
@@ -22,160 +31,685 @@ func (h *ProductHandler) RegisterRoutes(r gin.IRouter) {
 	{
 		products.GET("/", h.ListProducts)
 		products.POST("/", h.CreateProduct)
+		products.POST("/import", h.ImportProducts)
 		products.GET("/:id", h.GetProduct)
+		products.GET("/search", h.SearchProducts)
+		products.GET("/export", h.ExportProducts)
+		products.GET("/price-series", h.GetPriceSeries)
+		products.GET("/history/export", h.ExportHistory)
 		products.GET("/history/:id", h.GetProductHistory)
 		products.PUT("/:id", h.UpdateProduct)
 		products.DELETE("/:id", h.DeleteProduct)
+		products.POST("/:id/restore", h.RestoreProduct)
 		products.GET("/maxPrice/:id", h.GetHighestPrice)
 		products.GET("/timeDiff/:id", h.GetTimeDiff)
 		products.POST("/referenceDate/:id", h.GetByDate)
+		products.GET("/:id/price-stats", h.GetPriceStats)
+		products.GET("/:id/diff", h.DiffVersions)
+		products.GET("/:id/changelog", h.GetChangelog)
+		products.POST("/:id/revert", h.RevertProduct)
+		products.POST("/:id/schedule", h.ScheduleChange)
+		products.GET("/:id/schedule", h.ListScheduledChanges)
+		products.DELETE("/:id/schedule/:changeId", h.CancelScheduledChange)
 	}
 }
 
+// productRequest is the body of CreateProduct and UpdateProduct. The id comes from the path, the
+// version from If-Match and the timestamps are kept by the server, so clients may not send them.
+// Nor may they send back a product read in another locale, whose name and description are a
+// translation. A product read and sent back keeps its effective_from; without one the values apply
+// from the time they are written.
+type productRequest struct {
+	SKU           string        `json:"sku" binding:"max=64" example:"DARIUS-001"`
+	Name          string        `json:"name" binding:"required,max=255" example:"Darius"`
+	Description   string        `json:"description" binding:"max=2000" example:"A great product"`
+	Price         *moneyRequest `json:"price" binding:"required"`
+	EffectiveFrom string        `json:"effective_from" example:"2020-01-01 00:00:00"`
+	ID            interface{}   `json:"id" binding:"isdefault" swaggerignore:"true"`
+	CreatedAt     interface{}   `json:"created_at" binding:"isdefault" swaggerignore:"true"`
+	UpdatedAt     interface{}   `json:"updated_at" binding:"isdefault" swaggerignore:"true"`
+	DeletedAt     interface{}   `json:"deleted_at" binding:"isdefault" swaggerignore:"true"`
+	Locale        interface{}   `json:"locale" binding:"isdefault" swaggerignore:"true"`
+}
+
+func (r *productRequest) product() entity.Product {
+	return entity.Product{SKU: r.SKU, Name: r.Name, Description: r.Description, Price: r.Price.money(), EffectiveFrom: r.EffectiveFrom}
+}
+
+// moneyRequest is a price in minor units of an ISO 4217 currency.
+type moneyRequest struct {
+	Amount   *int64 `json:"amount" binding:"required,min=0" example:"1999"`
+	Currency string `json:"currency" binding:"required,len=3,uppercase" example:"EUR"`
+}
+
+func (m *moneyRequest) money() entity.Money {
+	return entity.Money{Amount: *m.Amount, Currency: m.Currency}
+}
+
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
-	var p entity.Product
-	if err := c.ShouldBind(&p); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req productRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
 		return
 	}
+	p := req.product()
 
-	ctx := context.Background()
+	ctx := changeContext(c)
 	id, err := h.uc.Create(ctx, &p)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		useCaseErrorResponse(c, h.l, err, "CreateProduct")
 		return
 	}
 	p.ID = strconv.FormatUint(id, 10)
 	c.JSON(http.StatusCreated, p)
 }
-func (h *ProductHandler) GetProductHistory(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
+
+type importProductsRequest struct {
+	Format    string `form:"format" binding:"omitempty,oneof=csv ndjson"`
+	DryRun    bool   `form:"dry_run"`
+	BatchSize int    `form:"batch_size" binding:"omitempty,min=1,max=5000"`
+}
+
+// ImportProducts upserts products by sku from a CSV or NDJSON body, streamed in batches of batch_size
+// rows per transaction. The format comes from ?format= or else the Content-Type. Rows that fail are
+// listed in the report; with dry_run=true nothing is kept.
+func (h *ProductHandler) ImportProducts(c *gin.Context) {
+	var req importProductsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	format := req.Format
+	if format == "" {
+		switch c.ContentType() {
+		case "text/csv":
+			format = usecase.ImportFormatCSV
+		case "application/x-ndjson", "application/ndjson":
+			format = usecase.ImportFormatNDJSON
+		}
+	}
+	r, err := usecase.NewImportReader(format, c.Request.Body)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		useCaseErrorResponse(c, h.l, err, "ImportProducts")
 		return
 	}
-	ctx := context.Background()
-	product, history, err := h.uc.GetProductHistory(ctx, id)
+	ctx := changeContext(c)
+	report, err := h.uc.Import(ctx, r, usecase.ImportOptions{DryRun: req.DryRun, BatchSize: req.BatchSize})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		useCaseErrorResponse(c, h.l, err, "ImportProducts")
 		return
 	}
-	if product == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *ProductHandler) GetProductHistory(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	product, history, err := h.uc.GetProductHistory(ctx, id)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "GetProductHistory")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"product": product, "history": history})
 }
 
+// GetProduct returns a product; ?currency= adds its price converted at the current rate. Its name and
+// description are translated into the first language of Accept-Language that it has a translation into.
 func (h *ProductHandler) GetProduct(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+	id, ok := paramID(c, "id")
+	if !ok {
 		return
 	}
+	includeDeleted, _ := strconv.ParseBool(c.Query("include_deleted"))
 	ctx := context.Background()
-	product, err := h.uc.GetByID(ctx, id)
+	product, err := h.uc.GetByID(ctx, id, includeDeleted)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		useCaseErrorResponse(c, h.l, err, "GetProduct")
 		return
 	}
-	if product == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+	if currency := c.Query("currency"); currency != "" {
+		if err := h.rates.ConvertProducts(ctx, []*entity.Product{product}, currency, ""); err != nil {
+			useCaseErrorResponse(c, h.l, err, "GetProduct")
+			return
+		}
+	}
+	if !h.localize(ctx, c, []*entity.Product{product}, "GetProduct") {
 		return
 	}
+	if product.Locale != "" {
+		c.Header("Content-Language", product.Locale)
+	}
+	c.Header("ETag", productETag(product.Version))
 	c.JSON(http.StatusOK, product)
 }
+
+// UpdateProduct replaces a product. If-Match must name the version read, as GetProduct tags it; the
+// ETag of the version written is returned.
 func (h *ProductHandler) UpdateProduct(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req productRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
 		return
 	}
-	var p entity.Product
-	if err := c.ShouldBind(&p); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	p := req.product()
+	p.Version, ok = requiredIfMatchVersion(c)
+	if !ok {
 		return
 	}
 	p.ID = strconv.FormatUint(id, 10)
-	ctx := context.Background()
+	ctx := changeContext(c)
 	if err := h.uc.Update(ctx, &p); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		useCaseErrorResponse(c, h.l, err, "UpdateProduct")
 		return
 	}
-	c.JSON(http.StatusNoContent, nil)
+	c.Header("ETag", productETag(p.Version+1))
+	c.Status(http.StatusNoContent)
 }
+
+// DeleteProduct soft-deletes a product; like UpdateProduct it requires If-Match and returns the new ETag.
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	version, ok := requiredIfMatchVersion(c)
+	if !ok {
+		return
+	}
+	ctx := changeContext(c)
+	if err := h.uc.Delete(ctx, id, version); err != nil {
+		useCaseErrorResponse(c, h.l, err, "DeleteProduct")
+		return
+	}
+	c.Header("ETag", productETag(version+1))
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreProduct undoes DeleteProduct; like it, it requires If-Match and returns the new ETag.
+func (h *ProductHandler) RestoreProduct(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	version, ok := requiredIfMatchVersion(c)
+	if !ok {
+		return
+	}
+	ctx := changeContext(c)
+	if err := h.uc.Restore(ctx, id, version); err != nil {
+		useCaseErrorResponse(c, h.l, err, "RestoreProduct")
+		return
+	}
+	c.Header("ETag", productETag(version+1))
+	c.Status(http.StatusNoContent)
+}
+
+// paramID parses the named path parameter as an id, answering 400 when it is not one.
+func paramID(c *gin.Context, name string) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		errorResponse(c, http.StatusBadRequest, "invalid "+name)
+		return 0, false
+	}
+	return id, true
+}
+
+// productETag is the strong entity tag of a product version.
+func productETag(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// localize translates products per Accept-Language, answering the error and returning false when it fails.
+func (h *ProductHandler) localize(ctx context.Context, c *gin.Context, products []*entity.Product, op string) bool {
+	c.Header("Vary", "Accept-Language")
+	if err := h.translations.Localize(ctx, products, acceptedLanguages(c.GetHeader("Accept-Language"))); err != nil {
+		useCaseErrorResponse(c, h.l, err, op)
+		return false
+	}
+	return true
+}
+
+// ifMatchVersion returns the version named by If-Match, or 0 when the header is absent or "*".
+// It answers 412 and returns false when the header can never match, e.g. a weak tag or a list of tags.
+func ifMatchVersion(c *gin.Context) (uint64, bool) {
+	tag := strings.TrimSpace(c.GetHeader("If-Match"))
+	if tag == "" || tag == "*" {
+		return 0, true
+	}
+	if len(tag) >= 2 && tag[0] == '"' && tag[len(tag)-1] == '"' {
+		version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 64)
+		if err == nil && version != 0 {
+			return version, true
+		}
+	}
+	errorResponse(c, http.StatusPreconditionFailed, "If-Match does not name a product version")
+	return 0, false
+}
+
+// requiredIfMatchVersion is ifMatchVersion for writes that may not overwrite blindly: it answers 428
+// when If-Match is absent or "*". Every write creates the next version, so the ETag of the version
+// written is that of the returned one plus one.
+func requiredIfMatchVersion(c *gin.Context) (uint64, bool) {
+	version, ok := ifMatchVersion(c)
+	if ok && version == 0 {
+		errorResponse(c, http.StatusPreconditionRequired, "If-Match must name the version to change")
+		return 0, false
+	}
+	return version, ok
+}
+
+type listProductsRequest struct {
+	Name           string    `form:"name"`
+	MinPrice       *int64    `form:"min_price" binding:"omitempty,min=0"`
+	MaxPrice       *int64    `form:"max_price" binding:"omitempty,min=0"`
+	Currency       string    `form:"currency" binding:"omitempty,len=3,uppercase"`
+	ConvertTo      string    `form:"convert_to" binding:"omitempty,len=3,uppercase"`
+	CreatedAfter   time.Time `form:"created_after"`
+	CreatedBefore  time.Time `form:"created_before"`
+	UpdatedAfter   time.Time `form:"updated_after"`
+	UpdatedBefore  time.Time `form:"updated_before"`
+	Sort           string    `form:"sort" binding:"omitempty,oneof=id name description price created_at updated_at"`
+	Order          string    `form:"order" binding:"omitempty,oneof=asc desc"`
+	Limit          uint64    `form:"limit" binding:"omitempty,max=100"`
+	Offset         uint64    `form:"offset"`
+	Cursor         string    `form:"cursor"`
+	IncludeDeleted bool      `form:"include_deleted"`
+	CategoryID     uint64    `form:"category_id"`
+	Tag            string    `form:"tag"`
+}
+
+func (r *listProductsRequest) filter() *entity.ProductFilter {
+	return &entity.ProductFilter{
+		Name:           r.Name,
+		MinPrice:       r.MinPrice,
+		MaxPrice:       r.MaxPrice,
+		Currency:       r.Currency,
+		CreatedAfter:   r.CreatedAfter,
+		CreatedBefore:  r.CreatedBefore,
+		UpdatedAfter:   r.UpdatedAfter,
+		UpdatedBefore:  r.UpdatedBefore,
+		SortBy:         r.Sort,
+		Desc:           r.Order == "desc",
+		Limit:          r.Limit,
+		Offset:         r.Offset,
+		Cursor:         r.Cursor,
+		IncludeDeleted: r.IncludeDeleted,
+		CategoryID:     r.CategoryID,
+		Tag:            r.Tag,
+	}
+}
+
+// ListProducts supports filtering by name substring, currency, price range in minor units and created/updated windows (RFC 3339),
+// sorting by any column and either offset or cursor pagination. Soft-deleted products are listed with include_deleted=true.
+// category_id lists the products of a category and its subcategories and tag those with a tag.
+// convert_to adds every price converted at the current rate; it is not ?currency= like on GetProduct and GetByDate
+// because currency already filters the list. Products are translated like in GetProduct.
+func (h *ProductHandler) ListProducts(c *gin.Context) {
+	var req listProductsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
 	}
 	ctx := context.Background()
-	if err := h.uc.Delete(ctx, id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	page, err := h.uc.List(ctx, req.filter())
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "ListProducts")
+		return
+	}
+	if req.ConvertTo != "" {
+		if err := h.rates.ConvertProducts(ctx, page.Products, req.ConvertTo, ""); err != nil {
+			useCaseErrorResponse(c, h.l, err, "ListProducts")
+			return
+		}
+	}
+	if !h.localize(ctx, c, page.Products, "ListProducts") {
 		return
 	}
-	c.JSON(http.StatusNoContent, nil)
+	c.JSON(http.StatusOK, page)
 }
-func (h *ProductHandler) ListProducts(c *gin.Context) {
+
+// searchProductsRequest takes the filters and pagination of listProductsRequest; sort and order are ignored.
+type searchProductsRequest struct {
+	Q string `form:"q" binding:"required"`
+	listProductsRequest
+}
+
+// SearchProducts ranks the products whose name or description have words starting with the words of
+// ?q=, with the words less their last letter or two, or with the words of products one typo away from
+// them, and highlights the words that matched.
+func (h *ProductHandler) SearchProducts(c *gin.Context) {
+	var req searchProductsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
 	ctx := context.Background()
-	products, err := h.uc.List(ctx)
+	page, err := h.uc.Search(ctx, req.Q, req.filter())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		useCaseErrorResponse(c, h.l, err, "SearchProducts")
 		return
 	}
-	c.JSON(http.StatusOK, products)
+	if req.ConvertTo != "" {
+		products := make([]*entity.Product, len(page.Hits))
+		for i, hit := range page.Hits {
+			products[i] = hit.Product
+		}
+		if err := h.rates.ConvertProducts(ctx, products, req.ConvertTo, ""); err != nil {
+			useCaseErrorResponse(c, h.l, err, "SearchProducts")
+			return
+		}
+	}
+	c.JSON(http.StatusOK, page)
 }
 
 func (h *ProductHandler) GetHighestPrice(c *gin.Context) {
 	ctx := context.Background()
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
 	}
 	pMax, err := h.uc.GetHighestPrice(ctx, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		useCaseErrorResponse(c, h.l, err, "GetHighestPrice")
+		return
 	}
 	c.JSON(http.StatusOK, pMax)
 }
 
 func (h *ProductHandler) GetTimeDiff(c *gin.Context) {
 	ctx := context.Background()
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
 	}
 	tDiffs, err := h.uc.GetTimeDiff(ctx, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		useCaseErrorResponse(c, h.l, err, "GetTimeDiff")
+		return
 	}
 	c.JSON(http.StatusOK, tDiffs)
 }
-func (h *ProductHandler) GetByDate(c *gin.Context) {
+
+type priceStatsRequest struct {
+	From time.Time `form:"from"`
+	To   time.Time `form:"to"`
+}
+
+// GetPriceStats summarises the prices of a product between from and to (RFC 3339); by default from its
+// first version until now.
+func (h *ProductHandler) GetPriceStats(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req priceStatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	ctx := context.Background()
+	stats, err := h.uc.GetPriceStats(ctx, id, req.From, req.To)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "GetPriceStats")
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+type priceSeriesRequest struct {
+	IDs         []uint64  `form:"id" binding:"required,min=1,max=50,unique,dive,min=1"`
+	From        time.Time `form:"from" binding:"required"`
+	To          time.Time `form:"to"`
+	Bucket      string    `form:"bucket" binding:"omitempty,oneof=hour day week month"`
+	Aggregation string    `form:"aggregation" binding:"omitempty,oneof=last time_weighted"`
+}
+
+// GetPriceSeries returns the prices of the products named by repeated ?id= between from and to (RFC 3339),
+// resampled into hour, day (default), week or month buckets with the last or time_weighted price of each.
+func (h *ProductHandler) GetPriceSeries(c *gin.Context) {
+	var req priceSeriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	ctx := context.Background()
+	series, err := h.uc.GetPriceSeries(ctx, &entity.PriceSeriesQuery{
+		ProductIDs:  req.IDs,
+		From:        req.From,
+		To:          req.To,
+		Bucket:      req.Bucket,
+		Aggregation: req.Aggregation,
+	})
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "GetPriceSeries")
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// DiffVersions returns the field changes between the versions named by ?from= and ?to=, each a history id
+// or a time (RFC 3339 or "2006-01-02 15:04:05" UTC). They default to the first and the current version.
+func (h *ProductHandler) DiffVersions(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	from, ok := queryVersionRef(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := queryVersionRef(c, "to")
+	if !ok {
+		return
+	}
 	ctx := context.Background()
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
+	diff, err := h.uc.Diff(ctx, id, from, to)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		useCaseErrorResponse(c, h.l, err, "DiffVersions")
 		return
 	}
-	var ph *entity.ProductHistory
-	var rd *entity.ReferenceDate
+	c.JSON(http.StatusOK, diff)
+}
+
+// GetChangelog returns the history of a product with the field changes of every version.
+func (h *ProductHandler) GetChangelog(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	changelog, err := h.uc.Changelog(ctx, id)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "GetChangelog")
+		return
+	}
+	c.JSON(http.StatusOK, changelog)
+}
+
+type revertProductRequest struct {
+	HistoryID uint64    `json:"history_id" example:"12"`
+	At        time.Time `json:"at" example:"2020-01-01T00:00:00Z"`
+}
+
+// RevertProduct restores the values of the version named by history_id, or stored at at (RFC 3339), as a new
+// version recorded with the actor of the X-Actor header; like UpdateProduct it requires If-Match and answers
+// 428 without it.
+func (h *ProductHandler) RevertProduct(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req revertProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	version, ok := requiredIfMatchVersion(c)
+	if !ok {
+		return
+	}
+	to := entity.VersionRef{HistoryID: req.HistoryID}
+	if !req.At.IsZero() {
+		to.At = req.At.UTC().Format(entity.DateTimeLayout)
+	}
+	ctx := changeContext(c)
+	product, err := h.uc.Revert(ctx, id, to, version)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "RevertProduct")
+		return
+	}
+	c.Header("ETag", productETag(product.Version))
+	c.JSON(http.StatusOK, product)
+}
+
+// changeContext returns the context of a write, carrying its entity.Change: the user authenticated by
+// gin.BasicAuth, else the X-Actor header, the X-Change-Reason header and the X-Request-ID header, which
+// is generated when missing and echoed in the response.
+func changeContext(c *gin.Context) context.Context {
+	actor := c.GetString(gin.AuthUserKey)
+	if actor == "" {
+		actor = strings.TrimSpace(c.GetHeader("X-Actor"))
+	}
+	requestID := strings.TrimSpace(c.GetHeader("X-Request-ID"))
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	c.Header("X-Request-ID", requestID)
+	return entity.WithChange(context.Background(), entity.Change{
+		Actor:     actor,
+		Reason:    strings.TrimSpace(c.GetHeader("X-Change-Reason")),
+		RequestID: requestID,
+	})
+}
+
+// queryVersionRef parses the named query parameter as a history id or a time, answering 400 when it is neither.
+func queryVersionRef(c *gin.Context, name string) (entity.VersionRef, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return entity.VersionRef{}, true
+	}
+	if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+		return entity.VersionRef{HistoryID: id}, true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return entity.VersionRef{At: t.UTC().Format(entity.DateTimeLayout)}, true
+	}
+	if _, err := time.Parse(entity.DateTimeLayout, v); err == nil {
+		return entity.VersionRef{At: v}, true
+	}
+	errorResponse(c, http.StatusBadRequest, "invalid "+name)
+	return entity.VersionRef{}, false
+}
+
+// GetByDate returns the version stored at date_time; ?currency= adds its price converted at the rate of that time.
+func (h *ProductHandler) GetByDate(c *gin.Context) {
+	ctx := context.Background()
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var rd entity.ReferenceDate
 	if err := c.ShouldBind(&rd); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		bindingErrorResponse(c, err)
 		return
 	}
-	ph, err = h.uc.GetByDate(ctx, id, rd)
+	ph, err := h.uc.GetByDate(ctx, id, &rd)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		useCaseErrorResponse(c, h.l, err, "GetByDate")
 		return
 	}
+	if currency := c.Query("currency"); currency != "" {
+		converted, err := h.rates.Convert(ctx, ph.Price, currency, rd.DateTime)
+		if err != nil {
+			useCaseErrorResponse(c, h.l, err, "GetByDate")
+			return
+		}
+		ph.ConvertedPrice = &converted
+	}
 	c.JSON(http.StatusOK, ph)
+}
+
+type scheduleChangeRequest struct {
+	Name        *string       `json:"name" binding:"omitempty,min=1,max=255"`
+	Description *string       `json:"description" binding:"omitempty,max=2000"`
+	Price       *moneyRequest `json:"price"`
+	EffectiveAt time.Time     `json:"effective_at" binding:"required"`
+}
 
+// ScheduleChange queues a change of name, description and/or price that takes effect at effective_at (RFC 3339).
+func (h *ProductHandler) ScheduleChange(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req scheduleChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	var price *entity.Money
+	if req.Price != nil {
+		m := req.Price.money()
+		price = &m
+	}
+	ctx := context.Background()
+	change, err := h.uc.ScheduleChange(ctx, &entity.ScheduledChange{
+		ProductID:   id,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       price,
+		EffectiveAt: req.EffectiveAt.UTC().Format(entity.DateTimeLayout),
+	})
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "ScheduleChange")
+		return
+	}
+	c.JSON(http.StatusCreated, change)
+}
+
+// ListScheduledChanges lists the scheduled changes of a product, optionally only those with ?status=.
+func (h *ProductHandler) ListScheduledChanges(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	status := c.Query("status")
+	switch status {
+	case "", entity.ScheduledChangePending, entity.ScheduledChangeApplied, entity.ScheduledChangeCancelled:
+	default:
+		errorResponse(c, http.StatusBadRequest, "invalid status")
+		return
+	}
+	ctx := context.Background()
+	changes, err := h.uc.ListScheduledChanges(ctx, id, status)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "ListScheduledChanges")
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
+
+// CancelScheduledChange cancels a change that has not been applied yet.
+func (h *ProductHandler) CancelScheduledChange(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	changeID, ok := paramID(c, "changeId")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	if err := h.uc.CancelScheduledChange(ctx, id, changeID); err != nil {
+		useCaseErrorResponse(c, h.l, err, "CancelScheduledChange")
+		return
+	}
+	c.Status(http.StatusNoContent)
 }
@@ -0,0 +1,45 @@
+// Package v2 implements routing paths for the product API.
+package v2
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/pkg/logger"
+)
+
+// NewRouter -.
+// Common middleware, probes and metrics are registered by v1.NewRouter.
+func NewRouter(handler *gin.Engine, l logger.Interface, p usecase.ProductUseCase, r usecase.ExchangeRateUseCase, cat usecase.CatalogUseCase, v usecase.VariantUseCase, inv usecase.InventoryUseCase, t usecase.ProductTranslationUseCase) {
+	// Report binding errors under the names clients use.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+
+	h := handler.Group("/v2")
+	{
+		NewProductHandler(p, r, t, l).RegisterRoutes(h)
+		NewExchangeRateHandler(r, l).RegisterRoutes(h)
+		NewCatalogHandler(cat, l).RegisterRoutes(h)
+		NewVariantHandler(v, l).RegisterRoutes(h)
+		NewInventoryHandler(inv, l).RegisterRoutes(h)
+		NewProductTranslationHandler(t, l).RegisterRoutes(h)
+	}
+}
+
+// fieldName is the json, else form, name of a request field.
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return f.Name
+}
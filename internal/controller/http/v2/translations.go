@@ -0,0 +1,120 @@
+package v2
+
+import (
+	"context"
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ProductTranslationHandler struct {
+	uc usecase.ProductTranslationUseCase
+	l  logger.Interface
+}
+
+func NewProductTranslationHandler(uc usecase.ProductTranslationUseCase, l logger.Interface) *ProductTranslationHandler {
+	return &ProductTranslationHandler{uc: uc, l: l}
+}
+
+func (h *ProductTranslationHandler) RegisterRoutes(r gin.IRouter) {
+	products := r.Group("/products")
+	{
+		products.GET("/:id/translations", h.ListTranslations)
+		products.PUT("/:id/translations/:locale", h.PutTranslation)
+		products.DELETE("/:id/translations/:locale", h.DeleteTranslation)
+	}
+}
+
+// translationRequest is the body of PutTranslation; the product and locale come from the path.
+type translationRequest struct {
+	Name        string `json:"name" binding:"required,max=255" example:"Darius"`
+	Description string `json:"description" binding:"max=2000" example:"Ein tolles Produkt"`
+}
+
+// ListTranslations returns the human and machine translations of a product ordered by locale.
+func (h *ProductTranslationHandler) ListTranslations(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	translations, err := h.uc.List(ctx, id)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "ListTranslations")
+		return
+	}
+	c.JSON(http.StatusOK, translations)
+}
+
+// PutTranslation stores a human translation of a product, which machine translations never replace.
+func (h *ProductTranslationHandler) PutTranslation(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req translationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	ctx := context.Background()
+	t, err := h.uc.Put(ctx, &entity.ProductTranslation{ProductID: id, Locale: c.Param("locale"), Name: req.Name, Description: req.Description})
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "PutTranslation")
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// DeleteTranslation removes a translation; a configured locale is machine-translated again later.
+func (h *ProductTranslationHandler) DeleteTranslation(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	if err := h.uc.Delete(ctx, id, c.Param("locale")); err != nil {
+		useCaseErrorResponse(c, h.l, err, "DeleteTranslation")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// acceptedLanguages returns the language ranges of an Accept-Language header by decreasing quality,
+// keeping the order of the header among equal ones. The wildcard and ranges with q=0 are dropped.
+func acceptedLanguages(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(part, ";")
+		lang = strings.TrimSpace(lang)
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if lang == "" || lang == "*" || q <= 0 {
+			continue
+		}
+		ranges = append(ranges, weighted{lang: lang, q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	langs := make([]string, len(ranges))
+	for i, r := range ranges {
+		langs[i] = r.lang
+	}
+	return langs
+}
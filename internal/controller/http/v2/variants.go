@@ -0,0 +1,203 @@
+package v2
+
+import (
+	"context"
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type VariantHandler struct {
+	uc usecase.VariantUseCase
+	l  logger.Interface
+}
+
+func NewVariantHandler(uc usecase.VariantUseCase, l logger.Interface) *VariantHandler {
+	return &VariantHandler{uc: uc, l: l}
+}
+
+func (h *VariantHandler) RegisterRoutes(r gin.IRouter) {
+	products := r.Group("/products")
+	{
+		products.GET("/:id/variants", h.ListVariants)
+		products.POST("/:id/variants", h.CreateVariant)
+		products.GET("/:id/variants/:variantId", h.GetVariant)
+		products.PUT("/:id/variants/:variantId", h.UpdateVariant)
+		products.DELETE("/:id/variants/:variantId", h.DeleteVariant)
+		products.GET("/:id/variants/:variantId/history", h.GetVariantHistory)
+		products.GET("/:id/variants/:variantId/as-of", h.GetVariantAt)
+	}
+}
+
+// variantRequest is the body of CreateVariant and UpdateVariant. The ids come from the path, the
+// version from If-Match and the timestamps are kept by the server, so clients may not send them.
+type variantRequest struct {
+	SKU        string            `json:"sku" binding:"required,max=64" example:"DARIUS-001-M-RED"`
+	Attributes map[string]string `json:"attributes" binding:"max=20"`
+	Price      *moneyRequest     `json:"price" binding:"required"`
+	ID         interface{}       `json:"id" binding:"isdefault" swaggerignore:"true"`
+	ProductID  interface{}       `json:"product_id" binding:"isdefault" swaggerignore:"true"`
+	CreatedAt  interface{}       `json:"created_at" binding:"isdefault" swaggerignore:"true"`
+	UpdatedAt  interface{}       `json:"updated_at" binding:"isdefault" swaggerignore:"true"`
+	DeletedAt  interface{}       `json:"deleted_at" binding:"isdefault" swaggerignore:"true"`
+}
+
+func (r *variantRequest) variant(productID uint64) entity.Variant {
+	return entity.Variant{ProductID: productID, SKU: r.SKU, Attributes: r.Attributes, Price: r.Price.money()}
+}
+
+// ListVariants returns the variants of a product. Every ?attr=name:value narrows them to the variants
+// with that attribute; soft-deleted variants are listed with include_deleted=true.
+func (h *VariantHandler) ListVariants(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	attributes := map[string]string{}
+	for _, attr := range c.QueryArray("attr") {
+		name, value, found := strings.Cut(attr, ":")
+		if !found || name == "" {
+			errorResponse(c, http.StatusBadRequest, "invalid attr")
+			return
+		}
+		attributes[name] = value
+	}
+	includeDeleted, _ := strconv.ParseBool(c.Query("include_deleted"))
+	ctx := context.Background()
+	variants, err := h.uc.List(ctx, id, attributes, includeDeleted)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "ListVariants")
+		return
+	}
+	c.JSON(http.StatusOK, variants)
+}
+func (h *VariantHandler) CreateVariant(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req variantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	v := req.variant(id)
+	ctx := changeContext(c)
+	variant, err := h.uc.Create(ctx, &v)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "CreateVariant")
+		return
+	}
+	c.Header("ETag", productETag(variant.Version))
+	c.JSON(http.StatusCreated, variant)
+}
+func (h *VariantHandler) GetVariant(c *gin.Context) {
+	id, variantID, ok := variantPath(c)
+	if !ok {
+		return
+	}
+	includeDeleted, _ := strconv.ParseBool(c.Query("include_deleted"))
+	ctx := context.Background()
+	variant, err := h.uc.Get(ctx, id, variantID, includeDeleted)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "GetVariant")
+		return
+	}
+	c.Header("ETag", productETag(variant.Version))
+	c.JSON(http.StatusOK, variant)
+}
+
+// UpdateVariant replaces the sku, attributes and price of a variant; like UpdateProduct it requires If-Match.
+func (h *VariantHandler) UpdateVariant(c *gin.Context) {
+	id, variantID, ok := variantPath(c)
+	if !ok {
+		return
+	}
+	var req variantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindingErrorResponse(c, err)
+		return
+	}
+	v := req.variant(id)
+	v.ID = variantID
+	v.Version, ok = requiredIfMatchVersion(c)
+	if !ok {
+		return
+	}
+	ctx := changeContext(c)
+	variant, err := h.uc.Update(ctx, &v)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "UpdateVariant")
+		return
+	}
+	c.Header("ETag", productETag(variant.Version))
+	c.JSON(http.StatusOK, variant)
+}
+
+// DeleteVariant soft-deletes a variant; like DeleteProduct it requires If-Match and returns the new ETag.
+func (h *VariantHandler) DeleteVariant(c *gin.Context) {
+	id, variantID, ok := variantPath(c)
+	if !ok {
+		return
+	}
+	version, ok := requiredIfMatchVersion(c)
+	if !ok {
+		return
+	}
+	ctx := changeContext(c)
+	if err := h.uc.Delete(ctx, id, variantID, version); err != nil {
+		useCaseErrorResponse(c, h.l, err, "DeleteVariant")
+		return
+	}
+	c.Header("ETag", productETag(version+1))
+	c.Status(http.StatusNoContent)
+}
+
+// GetVariantHistory returns every version of a variant with its validity interval, oldest first.
+func (h *VariantHandler) GetVariantHistory(c *gin.Context) {
+	id, variantID, ok := variantPath(c)
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	history, err := h.uc.History(ctx, id, variantID)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "GetVariantHistory")
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// GetVariantAt returns the version of a variant that was current at ?at=, RFC 3339 or "2006-01-02 15:04:05" UTC.
+func (h *VariantHandler) GetVariantAt(c *gin.Context) {
+	id, variantID, ok := variantPath(c)
+	if !ok {
+		return
+	}
+	at := c.Query("at")
+	if t, err := time.Parse(time.RFC3339, at); err == nil {
+		at = t.UTC().Format(entity.DateTimeLayout)
+	}
+	ctx := context.Background()
+	version, err := h.uc.GetAt(ctx, id, variantID, at)
+	if err != nil {
+		useCaseErrorResponse(c, h.l, err, "GetVariantAt")
+		return
+	}
+	c.JSON(http.StatusOK, version)
+}
+
+// variantPath parses the product and variant ids of the path.
+func variantPath(c *gin.Context) (uint64, uint64, bool) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return 0, 0, false
+	}
+	variantID, ok := paramID(c, "variantId")
+	return id, variantID, ok
+}
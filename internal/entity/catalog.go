@@ -0,0 +1,15 @@
+package entity
+
+// Category is a node of the category tree; ParentID is nil for a root category.
+type Category struct {
+	ID        uint64  `json:"id" example:"1"`
+	ParentID  *uint64 `json:"parent_id,omitempty" example:"1"`
+	Name      string  `json:"name" example:"Shoes"`
+	CreatedAt string  `json:"created_at" example:"2020-01-01 00:00:00"`
+}
+
+// Tag is a free-form label of products. Names are unique and stored in lower case.
+type Tag struct {
+	ID   uint64 `json:"id" example:"1"`
+	Name string `json:"name" example:"summer-sale"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "context"
+
+// Change describes who made a write to a product, why and in which request. It travels in the
+// context of the write and is recorded with the version the write creates.
+type Change struct {
+	Actor     string
+	Reason    string
+	RequestID string
+}
+
+// Limits of the Change fields; they match the product_history columns.
+const (
+	MaxChangeActorLen     = 255
+	MaxChangeReasonLen    = 1000
+	MaxChangeRequestIDLen = 255
+)
+
+type changeKey struct{}
+
+// WithChange returns a copy of ctx carrying c.
+func WithChange(ctx context.Context, c Change) context.Context {
+	return context.WithValue(ctx, changeKey{}, c)
+}
+
+// ChangeFromContext returns the Change carried by ctx, or the zero Change.
+func ChangeFromContext(ctx context.Context) Change {
+	c, _ := ctx.Value(changeKey{}).(Change)
+
+	return c
+}
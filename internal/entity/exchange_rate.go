@@ -0,0 +1,12 @@
+package entity
+
+// ExchangeRate is the price of one unit of Base in Quote, in major units, during the half-open
+// interval ValidFrom/ValidTo; ValidTo is empty for the current rate of the pair.
+// Rate is a decimal string so that no precision is lost on the way to and from the store.
+type ExchangeRate struct {
+	Base      string `json:"base" example:"EUR"`
+	Quote     string `json:"quote" example:"USD"`
+	Rate      string `json:"rate" example:"1.0850"`
+	ValidFrom string `json:"valid_from" example:"2020-01-01 00:00:00"`
+	ValidTo   string `json:"valid_to,omitempty" example:"2020-01-02 00:00:00"`
+}
@@ -0,0 +1,47 @@
+package entity
+
+// Kinds of stock movements.
+const (
+	// StockReceipt adds goods to the stock on hand.
+	StockReceipt = "receipt"
+	// StockAdjustment corrects the stock on hand by a signed quantity, e.g. after a stocktake.
+	StockAdjustment = "adjustment"
+	// StockReservation sets stock on hand aside for a reference, e.g. an order.
+	StockReservation = "reservation"
+	// StockRelease returns stock reserved for a reference to the available stock.
+	StockRelease = "release"
+	// StockFulfilment removes stock reserved for a reference from the stock on hand when it ships.
+	StockFulfilment = "fulfilment"
+)
+
+// StockMovement is an entry of the stock ledger of a product. Quantity is positive except for
+// adjustments, whose sign tells whether stock was added or removed. A product has at most one
+// movement of each kind for a reference.
+type StockMovement struct {
+	ID        uint64 `json:"id" example:"1"`
+	ProductID uint64 `json:"product_id" example:"1"`
+	Kind      string `json:"kind" example:"reservation"`
+	Quantity  int64  `json:"quantity" example:"2"`
+	Reference string `json:"reference,omitempty" example:"order-1042"`
+	CreatedAt string `json:"created_at" example:"2020-01-01 00:00:00"`
+	// ChangedBy, ChangeReason and RequestID record the entity.Change of the write that recorded the movement, when known.
+	ChangedBy    string `json:"changed_by,omitempty" example:"jane@example.com"`
+	ChangeReason string `json:"change_reason,omitempty" example:"stocktake"`
+	RequestID    string `json:"request_id,omitempty" example:"0b6c1a8e-2f4d-4c7e-9a51-3d2f0e8b7c61"`
+}
+
+// StockLevel is the stock of a product as its ledger left it. Available is OnHand less Reserved and
+// is never negative; UpdatedAt is empty for a product that never had stock.
+type StockLevel struct {
+	ProductID uint64 `json:"product_id" example:"1"`
+	OnHand    int64  `json:"on_hand" example:"10"`
+	Reserved  int64  `json:"reserved" example:"2"`
+	Available int64  `json:"available" example:"8"`
+	UpdatedAt string `json:"updated_at,omitempty" example:"2020-01-01 00:00:00"`
+}
+
+// StockItem is a quantity of a product, e.g. a line of an order to reserve.
+type StockItem struct {
+	ProductID uint64 `json:"product_id" example:"1"`
+	Quantity  int64  `json:"quantity" example:"2"`
+}
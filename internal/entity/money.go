@@ -0,0 +1,25 @@
+package entity
+
+// Money is an amount in the minor units of an ISO 4217 currency, e.g. 1999 EUR is 19.99 €.
+type Money struct {
+	Amount   int64  `json:"amount" example:"1999"`
+	Currency string `json:"currency" example:"EUR"`
+}
+
+// CurrencyExponent returns the number of minor unit digits of an ISO 4217 currency code
+// and whether the code is supported.
+func CurrencyExponent(code string) (int, bool) {
+	exp, ok := _currencyExponents[code]
+
+	return exp, ok
+}
+
+// _currencyExponents lists the supported ISO 4217 currencies and their minor unit digits.
+var _currencyExponents = map[string]int{ //nolint:gochecknoglobals // immutable
+	"AED": 2, "ARS": 2, "AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2,
+	"CLP": 0, "CNY": 2, "COP": 2, "CZK": 2, "DKK": 2, "EGP": 2, "EUR": 2, "GBP": 2,
+	"HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "ISK": 0, "JOD": 3, "JPY": 0,
+	"KRW": 0, "KWD": 3, "MXN": 2, "MYR": 2, "NOK": 2, "NZD": 2, "OMR": 3, "PHP": 2,
+	"PLN": 2, "RON": 2, "RSD": 2, "RUB": 2, "SAR": 2, "SEK": 2, "SGD": 2, "THB": 2,
+	"TND": 3, "TRY": 2, "TWD": 2, "UAH": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
@@ -0,0 +1,35 @@
+package entity
+
+// Types of product events; they are also the routing keys the events are published with.
+const (
+	ProductCreated  = "product.created"
+	ProductUpdated  = "product.updated"
+	ProductDeleted  = "product.deleted"
+	ProductRestored = "product.restored"
+)
+
+// ProductEvent is a change of a product as published to downstream services. Before is the product
+// as it was and After as the change left it; Before is unset for product.created.
+type ProductEvent struct {
+	Type      string `json:"type" example:"product.updated"`
+	ProductID uint64 `json:"product_id" example:"1"`
+	// Version is the version of the product the change created.
+	Version    uint64   `json:"version" example:"2"`
+	OccurredAt string   `json:"occurred_at" example:"2020-01-01 00:00:00"`
+	Before     *Product `json:"before,omitempty"`
+	After      *Product `json:"after"`
+	// ChangedBy, ChangeReason and RequestID are the Change the write was made with.
+	ChangedBy    string `json:"changed_by,omitempty" example:"alice"`
+	ChangeReason string `json:"change_reason,omitempty" example:"Price correction"`
+	RequestID    string `json:"request_id,omitempty" example:"7d3c0e52"`
+}
+
+// OutboxEvent is a product event stored with the write it describes until it is published.
+// Payload is the ProductEvent encoded as JSON.
+type OutboxEvent struct {
+	ID        uint64
+	Type      string
+	ProductID uint64
+	Payload   []byte
+	CreatedAt string
+}
@@ -0,0 +1,29 @@
+package entity
+
+// Sources of product translations.
+const (
+	// TranslationSourceHuman marks a translation written by a person; machine translations never replace it.
+	TranslationSourceHuman = "human"
+	// TranslationSourceMachine marks a translation filled in by the translation use case.
+	TranslationSourceMachine = "machine"
+)
+
+// ProductTranslation is the name and description of a product in a locale, a BCP 47 tag such as "de" or "pt-BR".
+type ProductTranslation struct {
+	ProductID   uint64 `json:"product_id" example:"1"`
+	Locale      string `json:"locale" example:"de"`
+	Name        string `json:"name" example:"Darius"`
+	Description string `json:"description" example:"Ein tolles Produkt"`
+	Source      string `json:"source" example:"human"`
+	// SourceVersion is the version of the product a machine translation was made from; a newer version makes it stale.
+	SourceVersion uint64 `json:"source_version,omitempty" example:"3"`
+	UpdatedAt     string `json:"updated_at" example:"2020-01-01 00:00:00"`
+}
+
+// ProductLocale is a product and a locale it is missing a translation into. Attempts counts the machine
+// translations into the locale that failed since the last one that did not.
+type ProductLocale struct {
+	ProductID uint64
+	Locale    string
+	Attempts  int
+}
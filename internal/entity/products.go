@@ -2,6 +2,11 @@
 // HTTP response objects if suitable. Each logic group entities in own file.
 package entity
 
+import "time"
+
+// DateTimeLayout is the text form of product timestamps.
+const DateTimeLayout = "2006-01-02 15:04:05"
+
 // Translation -.
 type Translation struct {
 	Source      string `json:"source"       example:"auto"`
@@ -10,36 +15,264 @@ type Translation struct {
 	Translation string `json:"translation"  example:"text for translation"`
 }
 type Product struct {
-	ID          string `json:"id" example:"1"`
+	ID string `json:"id" example:"1"`
+	// SKU is an optional key unique among products, used to match them on import.
+	SKU         string `json:"sku,omitempty" example:"DARIUS-001"`
 	Name        string `json:"name" example:"Darius"`
 	Description string `json:"description" example:"A great product"`
-	Price       int    `json:"price" example:"100"`
+	Price       Money  `json:"price"`
 	CreatedAt   string `json:"created_at" example:"2020-01-01"`
 	UpdatedAt   string `json:"updated_at" example:"2020-01-01"`
+	// EffectiveFrom is the business time the current values apply from, when it differs from UpdatedAt.
+	EffectiveFrom string `json:"effective_from,omitempty" example:"2020-01-01 00:00:00"`
+	// Version is incremented by every update; a non-zero Version makes Update fail unless it is still current.
+	Version uint64 `json:"version" example:"1"`
+	// DeletedAt is set while the product is soft-deleted.
+	DeletedAt string `json:"deleted_at,omitempty" example:"2020-01-01 00:00:00"`
+	// ConvertedPrice is Price in the currency a client asked for; it is never stored.
+	ConvertedPrice *Money `json:"converted_price,omitempty"`
+	// Locale is set when Name and Description were replaced by their translation into that locale.
+	Locale string `json:"locale,omitempty" example:"de"`
 }
 
+// ProductHistory is one version of a product. ValidFrom/ValidTo is the half-open interval
+// during which it was the stored version; ValidTo is empty for the current version.
+// A version with DeletedAt set records the deletion of the product.
 type ProductHistory struct {
-	ID          int    `json:"id" example:"1"`
-	ProductID   int    `json:"product_id" example:"1"`
-	Name        string `json:"name" example:"Darius"`
-	Description string `json:"description" example:"A great product"`
-	Price       int    `json:"price" example:"100"`
-	ValidFrom   string `json:"valid_from" example:"2020-01-01"`
-	ValidTo     string `json:"valid_to" example:"2020-01-01"`
-	CreatedAt   string `json:"created_at" example:"2020-01-01"`
+	ID            int    `json:"id" example:"1"`
+	ProductID     int    `json:"product_id" example:"1"`
+	SKU           string `json:"sku,omitempty" example:"DARIUS-001"`
+	Name          string `json:"name" example:"Darius"`
+	Description   string `json:"description" example:"A great product"`
+	Price         Money  `json:"price"`
+	EffectiveFrom string `json:"effective_from,omitempty" example:"2020-01-01 00:00:00"`
+	ValidFrom     string `json:"valid_from" example:"2020-01-01"`
+	ValidTo       string `json:"valid_to,omitempty" example:"2020-01-01"`
+	CreatedAt     string `json:"created_at" example:"2020-01-01"`
+	Version       uint64 `json:"version" example:"1"`
+	DeletedAt     string `json:"deleted_at,omitempty" example:"2020-01-01 00:00:00"`
+	// ChangedBy, ChangeReason and RequestID record the entity.Change of the write that created the version, when known.
+	ChangedBy    string `json:"changed_by,omitempty" example:"jane@example.com"`
+	ChangeReason string `json:"change_reason,omitempty" example:"supplier price increase"`
+	RequestID    string `json:"request_id,omitempty" example:"0b6c1a8e-2f4d-4c7e-9a51-3d2f0e8b7c61"`
+	// ConvertedPrice is Price in the currency a client asked for; it is never stored.
+	ConvertedPrice *Money `json:"converted_price,omitempty"`
 }
 
 type ProductMaxValue struct {
-	Price    int    `json:"price"`
+	Price    Money  `json:"price"`
 	Duration string `json:"duration" example:"10"`
 }
 
 type TimeDiff struct {
 	ValidFrom string `json:"valid_from"`
-	ValidTo   string `json:"valid_to"`
-	Price     int    `json:"price"`
+	ValidTo   string `json:"valid_to,omitempty"`
+	Price     Money  `json:"price"`
+}
+
+// PriceStats summarises the prices a product had during [From, To). Consecutive versions with the
+// same price are one price period, so edits of other fields are not counted as changes.
+type PriceStats struct {
+	ProductID uint64 `json:"product_id" example:"1"`
+	From      string `json:"from" example:"2020-01-01 00:00:00"`
+	To        string `json:"to" example:"2020-02-01 00:00:00"`
+	Min       Money  `json:"min"`
+	Max       Money  `json:"max"`
+	// Average is the mean of the price periods and TimeWeightedAverage weighs each by how long it
+	// lasted; both are rounded to minor units.
+	Average             Money `json:"average"`
+	TimeWeightedAverage Money `json:"time_weighted_average"`
+	First               Money `json:"first"`
+	Last                Money `json:"last"`
+	Changes             int   `json:"changes" example:"3"`
+	// LongestHeld is the price held for the longest total time; ties go to the higher price.
+	LongestHeld HeldPrice `json:"longest_held"`
+	// PercentChange is the change from First to Last in percent; it is omitted when First is zero.
+	PercentChange *float64 `json:"percent_change,omitempty" example:"-12.5"`
+}
+
+// HeldPrice is a price and the total time it was held.
+type HeldPrice struct {
+	Price    Money  `json:"price"`
+	Duration string `json:"duration" example:"168:00:00"`
+	Seconds  int64  `json:"seconds" example:"604800"`
+}
+
+// PriceInterval is the price of a live product version and the half-open interval during which it
+// was stored; ValidTo is zero while it is current.
+type PriceInterval struct {
+	ProductID uint64
+	Price     Money
+	ValidFrom time.Time
+	ValidTo   time.Time
+}
+
+// Price series bucket sizes and aggregations.
+const (
+	PriceBucketHour  = "hour"
+	PriceBucketDay   = "day"
+	PriceBucketWeek  = "week"
+	PriceBucketMonth = "month"
+
+	// PriceAggLast takes the last price held in a bucket, PriceAggTimeWeighted the average of the
+	// prices held weighted by how long they were held.
+	PriceAggLast         = "last"
+	PriceAggTimeWeighted = "time_weighted"
+)
+
+// PriceSeriesQuery selects the price series of products resampled into buckets of UTC calendar
+// time; weeks start on Monday. The buckets cover [From, To).
+type PriceSeriesQuery struct {
+	ProductIDs  []uint64
+	From        time.Time
+	To          time.Time
+	Bucket      string
+	Aggregation string
+}
+
+// PriceSeries is the price of a product per bucket.
+type PriceSeries struct {
+	ProductID   uint64       `json:"product_id" example:"1"`
+	Bucket      string       `json:"bucket" example:"day"`
+	Aggregation string       `json:"aggregation" example:"last"`
+	Points      []PricePoint `json:"points"`
+}
+
+// PricePoint is the price of a bucket starting at Time; Price is null when the product had no
+// price during the bucket.
+type PricePoint struct {
+	Time  string `json:"time" example:"2020-01-01 00:00:00"`
+	Price *Money `json:"price"`
+}
+
+// VersionRef names a version of a product by its history id or by a time it was stored at, in
+// DateTimeLayout. The zero VersionRef names no version in particular.
+type VersionRef struct {
+	HistoryID uint64
+	At        string
+}
+
+// FieldChange is the change of one field between two versions; Old and New are strings,
+// or Money for the price.
+type FieldChange struct {
+	Field string      `json:"field" example:"name"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// ProductDiff lists the fields that differ between two versions of a product.
+type ProductDiff struct {
+	ProductID uint64          `json:"product_id" example:"1"`
+	From      *ProductHistory `json:"from"`
+	To        *ProductHistory `json:"to"`
+	Changes   []FieldChange   `json:"changes"`
+}
+
+// ChangelogEntry is a version annotated with what changed since the version before it.
+type ChangelogEntry struct {
+	*ProductHistory
+	Changes []FieldChange `json:"changes"`
 }
 
 type ReferenceDate struct {
 	DateTime string `json:"date_time" example:"2020-01-01"`
 }
+
+// ProductFilter narrows, orders and pages a product listing. Zero values mean "no constraint".
+// MinPrice and MaxPrice bound the amount in minor units, so they require Currency.
+type ProductFilter struct {
+	Name          string
+	MinPrice      *int64
+	MaxPrice      *int64
+	Currency      string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+	SortBy        string
+	Desc          bool
+	Limit         uint64
+	Offset        uint64
+	Cursor        string
+	After         *ProductCursor
+	// IncludeDeleted lists soft-deleted products too.
+	IncludeDeleted bool
+	// CategoryID lists the products of a category and of its descendants.
+	CategoryID uint64
+	// Tag lists the products with a tag.
+	Tag string
+}
+
+// ExportFilter selects what a product or history export streams. Zero values mean "no constraint".
+type ExportFilter struct {
+	// UpdatedSince keeps products updated, and versions opened or closed, at or after it.
+	UpdatedSince time.Time
+	// AsOf exports the products as they were stored at that time, and the history as it was known then.
+	AsOf time.Time
+	// IncludeDeleted exports soft-deleted products too; the history always includes them.
+	IncludeDeleted bool
+}
+
+// ProductCursor is a keyset position: the sort column value and id of the last product seen.
+type ProductCursor struct {
+	SortBy string `json:"s"`
+	Value  string `json:"v"`
+	ID     uint64 `json:"id"`
+}
+
+// ProductPage -.
+type ProductPage struct {
+	Products   []*Product `json:"products"`
+	Total      uint64     `json:"total" example:"42"`
+	NextCursor string     `json:"next_cursor,omitempty" example:"eyJzIjoiaWQiLCJ2IjoiMjAiLCJpZCI6MjB9"`
+}
+
+// ProductSearch is a full-text search over the names and descriptions of products. Each of Terms
+// is a word searched for as alternative prefixes: a product matches when its name or description has,
+// for every term, a word starting with one of its prefixes. Filter narrows and pages the results like
+// a listing, but they are always ordered by relevance.
+type ProductSearch struct {
+	Terms  [][]string
+	Filter ProductFilter
+}
+
+// ProductSearchHit is a product found by a search. Score ranks it within that search only.
+type ProductSearchHit struct {
+	Product    *Product          `json:"product"`
+	Score      float64           `json:"score" example:"0.6079271"`
+	Highlights ProductHighlights `json:"highlights"`
+}
+
+// ProductHighlights are the name of a product and an excerpt of its description, HTML-escaped, with
+// the words that matched a search wrapped in <mark> tags.
+type ProductHighlights struct {
+	Name        string `json:"name" example:"<mark>Dar</mark>ius"`
+	Description string `json:"description,omitempty" example:"A great <mark>product</mark>"`
+}
+
+// ProductSearchPage -.
+type ProductSearchPage struct {
+	Hits       []*ProductSearchHit `json:"hits"`
+	Total      uint64              `json:"total" example:"42"`
+	NextCursor string              `json:"next_cursor,omitempty" example:"eyJzIjoicmVsZXZhbmNlIiwidiI6IjAuNSIsImlkIjoyMH0"`
+}
+
+// Scheduled change statuses.
+const (
+	ScheduledChangePending   = "pending"
+	ScheduledChangeApplied   = "applied"
+	ScheduledChangeCancelled = "cancelled"
+)
+
+// ScheduledChange is a future edit of a product. Nil fields are left unchanged when it is applied.
+type ScheduledChange struct {
+	ID              uint64  `json:"id" example:"1"`
+	ProductID       uint64  `json:"product_id" example:"1"`
+	Name            *string `json:"name,omitempty" example:"Darius"`
+	Description     *string `json:"description,omitempty" example:"A great product"`
+	Price           *Money  `json:"price,omitempty"`
+	EffectiveAt     string  `json:"effective_at" example:"2030-01-01 00:00:00"`
+	Status          string  `json:"status" example:"pending"`
+	CreatedAt       string  `json:"created_at" example:"2020-01-01 00:00:00"`
+	StatusChangedAt string  `json:"status_changed_at" example:"2020-01-01 00:00:00"`
+}
@@ -0,0 +1,33 @@
+package entity
+
+// Variant is a sellable SKU of a product with its own price, told apart from the other variants of the
+// product by its attributes, e.g. {"size": "M", "colour": "red"}. Like a product it is versioned.
+type Variant struct {
+	ID         uint64            `json:"id" example:"1"`
+	ProductID  uint64            `json:"product_id" example:"1"`
+	SKU        string            `json:"sku" example:"DARIUS-001-M-RED"`
+	Attributes map[string]string `json:"attributes"`
+	Price      Money             `json:"price"`
+	CreatedAt  string            `json:"created_at" example:"2020-01-01 00:00:00"`
+	UpdatedAt  string            `json:"updated_at" example:"2020-01-01 00:00:00"`
+	Version    uint64            `json:"version" example:"1"`
+	DeletedAt  string            `json:"deleted_at,omitempty" example:"2020-01-01 00:00:00"`
+}
+
+// VariantHistory is a version of a variant, current during [ValidFrom, ValidTo); the current version has no ValidTo.
+type VariantHistory struct {
+	ID         uint64            `json:"id" example:"1"`
+	VariantID  uint64            `json:"variant_id" example:"1"`
+	ProductID  uint64            `json:"product_id" example:"1"`
+	SKU        string            `json:"sku" example:"DARIUS-001-M-RED"`
+	Attributes map[string]string `json:"attributes"`
+	Price      Money             `json:"price"`
+	ValidFrom  string            `json:"valid_from" example:"2020-01-01 00:00:00"`
+	ValidTo    string            `json:"valid_to,omitempty" example:"2020-01-01 00:00:00"`
+	Version    uint64            `json:"version" example:"1"`
+	DeletedAt  string            `json:"deleted_at,omitempty" example:"2020-01-01 00:00:00"`
+	// ChangedBy, ChangeReason and RequestID record the entity.Change of the write that created the version, when known.
+	ChangedBy    string `json:"changed_by,omitempty" example:"jane@example.com"`
+	ChangeReason string `json:"change_reason,omitempty" example:"supplier price increase"`
+	RequestID    string `json:"request_id,omitempty" example:"0b6c1a8e-2f4d-4c7e-9a51-3d2f0e8b7c61"`
+}
@@ -0,0 +1,353 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+// Limits of category and tag names; they match their VARCHAR columns.
+const (
+	MaxCategoryNameLen = 255
+	MaxTagNameLen      = 64
+)
+
+// CatalogUseCase organises products into a tree of categories and labels them with free-form tags.
+// Products are addressed like in ProductUseCase, so soft-deleted products are not found.
+type CatalogUseCase interface {
+	CreateCategory(ctx context.Context, c *entity.Category) (*entity.Category, error)
+	GetCategory(ctx context.Context, id uint64) (*entity.Category, error)
+	ListCategories(ctx context.Context) ([]*entity.Category, error)
+	// UpdateCategory renames a category or moves it to another parent, but not below itself.
+	UpdateCategory(ctx context.Context, c *entity.Category) (*entity.Category, error)
+	// DeleteCategory deletes a category without subcategories; its products keep their other categories.
+	DeleteCategory(ctx context.Context, id uint64) error
+	ProductCategories(ctx context.Context, productID uint64) ([]*entity.Category, error)
+	AssignCategory(ctx context.Context, productID, categoryID uint64) error
+	UnassignCategory(ctx context.Context, productID, categoryID uint64) error
+
+	ListTags(ctx context.Context) ([]*entity.Tag, error)
+	CreateTag(ctx context.Context, name string) (*entity.Tag, error)
+	RenameTag(ctx context.Context, id uint64, name string) (*entity.Tag, error)
+	// DeleteTag deletes a tag and removes it from every product.
+	DeleteTag(ctx context.Context, id uint64) error
+	ProductTags(ctx context.Context, productID uint64) ([]*entity.Tag, error)
+	// TagProduct adds the tag named name to a product, creating the tag when it does not exist yet.
+	// Tag names are trimmed and lower-cased, so "Sale" and "sale" are one tag.
+	TagProduct(ctx context.Context, productID uint64, name string) (*entity.Tag, error)
+	UntagProduct(ctx context.Context, productID uint64, name string) error
+}
+
+type catalogUseCase struct {
+	repo     repository.CatalogRepository
+	products repository.ProductRepository
+}
+
+func NewCatalogUseCase(r repository.CatalogRepository, products repository.ProductRepository) CatalogUseCase {
+	return &catalogUseCase{repo: r, products: products}
+}
+
+func (uc *catalogUseCase) CreateCategory(ctx context.Context, c *entity.Category) (*entity.Category, error) {
+	if err := validateCategory(c, true); err != nil {
+		return nil, err
+	}
+	var id uint64
+	err := uc.repo.InTx(ctx, func(repo repository.CatalogRepository) error {
+		if c.ParentID != nil {
+			if err := checkParent(ctx, repo, 0, *c.ParentID); err != nil {
+				return err
+			}
+		}
+		var err error
+		id, err = repo.CreateCategory(ctx, c)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uc.GetCategory(ctx, id)
+}
+
+func (uc *catalogUseCase) GetCategory(ctx context.Context, id uint64) (*entity.Category, error) {
+	c, err := uc.repo.GetCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, ErrCategoryNotFound
+	}
+	return c, nil
+}
+
+func (uc *catalogUseCase) ListCategories(ctx context.Context) ([]*entity.Category, error) {
+	return uc.repo.ListCategories(ctx)
+}
+
+func (uc *catalogUseCase) UpdateCategory(ctx context.Context, c *entity.Category) (*entity.Category, error) {
+	if err := validateCategory(c, false); err != nil {
+		return nil, err
+	}
+	// The category and the ancestors of its new parent stay locked until it is moved, so that two
+	// concurrent moves cannot each put one category below the other.
+	err := uc.repo.InTx(ctx, func(repo repository.CatalogRepository) error {
+		stored, err := repo.LockCategory(ctx, c.ID)
+		if err != nil {
+			return err
+		}
+		if stored == nil {
+			return ErrCategoryNotFound
+		}
+		if c.ParentID != nil {
+			if err := checkParent(ctx, repo, c.ID, *c.ParentID); err != nil {
+				return err
+			}
+		}
+		return repo.UpdateCategory(ctx, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uc.GetCategory(ctx, c.ID)
+}
+
+// checkParent checks that parentID exists and, for an existing category id, is not id or one of its
+// descendants. It locks parentID and its ancestors within the transaction of repo.
+func checkParent(ctx context.Context, repo repository.CatalogRepository, id, parentID uint64) error {
+	// Walking up from the new parent reaches id exactly when the parent is in the subtree of id.
+	for next := &parentID; next != nil; {
+		if *next == id {
+			return ErrCategoryCycle
+		}
+		c, err := repo.LockCategory(ctx, *next)
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			// A foreign key keeps the parents of stored categories, so only parentID can be missing.
+			var fe fieldErrors
+			fe.add("parent_id", "must be an existing category")
+			return fe.err()
+		}
+		next = c.ParentID
+	}
+	return nil
+}
+
+func (uc *catalogUseCase) DeleteCategory(ctx context.Context, id uint64) error {
+	deleted, err := uc.repo.DeleteCategory(ctx, id)
+	if errors.Is(err, repository.ErrCategoryHasChildren) {
+		return ErrCategoryHasChildren
+	}
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (uc *catalogUseCase) ProductCategories(ctx context.Context, productID uint64) ([]*entity.Category, error) {
+	if err := uc.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ProductCategories(ctx, productID)
+}
+
+func (uc *catalogUseCase) AssignCategory(ctx context.Context, productID, categoryID uint64) error {
+	if err := uc.checkProduct(ctx, productID); err != nil {
+		return err
+	}
+	if _, err := uc.GetCategory(ctx, categoryID); err != nil {
+		return err
+	}
+	return uc.repo.AssignCategory(ctx, productID, categoryID)
+}
+
+func (uc *catalogUseCase) UnassignCategory(ctx context.Context, productID, categoryID uint64) error {
+	if err := uc.checkProduct(ctx, productID); err != nil {
+		return err
+	}
+	removed, err := uc.repo.UnassignCategory(ctx, productID, categoryID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrCategoryNotAssigned
+	}
+	return nil
+}
+
+func (uc *catalogUseCase) ListTags(ctx context.Context) ([]*entity.Tag, error) {
+	return uc.repo.ListTags(ctx)
+}
+
+func (uc *catalogUseCase) CreateTag(ctx context.Context, name string) (*entity.Tag, error) {
+	name = normalizeTag(name)
+	if err := validateTag(name); err != nil {
+		return nil, err
+	}
+	id, err := uc.repo.CreateTag(ctx, name)
+	if errors.Is(err, repository.ErrDuplicateTag) {
+		return nil, ErrDuplicateTag
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entity.Tag{ID: id, Name: name}, nil
+}
+
+func (uc *catalogUseCase) RenameTag(ctx context.Context, id uint64, name string) (*entity.Tag, error) {
+	name = normalizeTag(name)
+	if err := validateTag(name); err != nil {
+		return nil, err
+	}
+	t, err := uc.repo.GetTag(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrTagNotFound
+	}
+	if t.Name == name {
+		return t, nil
+	}
+	err = uc.repo.RenameTag(ctx, id, name)
+	if errors.Is(err, repository.ErrDuplicateTag) {
+		return nil, ErrDuplicateTag
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entity.Tag{ID: id, Name: name}, nil
+}
+
+func (uc *catalogUseCase) DeleteTag(ctx context.Context, id uint64) error {
+	deleted, err := uc.repo.DeleteTag(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+func (uc *catalogUseCase) ProductTags(ctx context.Context, productID uint64) ([]*entity.Tag, error) {
+	if err := uc.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ProductTags(ctx, productID)
+}
+
+func (uc *catalogUseCase) TagProduct(ctx context.Context, productID uint64, name string) (*entity.Tag, error) {
+	name = normalizeTag(name)
+	if err := validateTag(name); err != nil {
+		return nil, err
+	}
+	if err := uc.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	t, err := uc.repo.GetTagByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		id, err := uc.repo.CreateTag(ctx, name)
+		switch {
+		case errors.Is(err, repository.ErrDuplicateTag):
+			// Created concurrently by another request.
+			if t, err = uc.repo.GetTagByName(ctx, name); err != nil {
+				return nil, err
+			}
+			if t == nil {
+				return nil, ErrTagNotFound
+			}
+		case err != nil:
+			return nil, err
+		default:
+			t = &entity.Tag{ID: id, Name: name}
+		}
+	}
+	if err := uc.repo.TagProduct(ctx, productID, t.ID); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (uc *catalogUseCase) UntagProduct(ctx context.Context, productID uint64, name string) error {
+	if err := uc.checkProduct(ctx, productID); err != nil {
+		return err
+	}
+	t, err := uc.repo.GetTagByName(ctx, normalizeTag(name))
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return ErrTagNotFound
+	}
+	removed, err := uc.repo.UntagProduct(ctx, productID, t.ID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrTagNotAssigned
+	}
+	return nil
+}
+
+func (uc *catalogUseCase) checkProduct(ctx context.Context, id uint64) error {
+	p, err := uc.products.GetByID(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// validateCategory checks a payload for CreateCategory (create) or UpdateCategory.
+func validateCategory(c *entity.Category, create bool) error {
+	var fe fieldErrors
+	if create && c.ID != 0 {
+		fe.add("id", "is read-only")
+	}
+	switch {
+	case strings.TrimSpace(c.Name) == "":
+		fe.add("name", "is required")
+	case utf8.RuneCountInString(c.Name) > MaxCategoryNameLen:
+		fe.add("name", "must be at most "+strconv.Itoa(MaxCategoryNameLen)+" characters")
+	}
+	if c.ParentID != nil && *c.ParentID == 0 {
+		fe.add("parent_id", "must be a category id")
+	}
+	if c.CreatedAt != "" {
+		fe.add("created_at", "is read-only")
+	}
+	return fe.err()
+}
+
+// normalizeTag returns the stored form of a tag name.
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// validateTag checks a normalized tag name; tags are addressed by name in URLs, so it cannot contain a slash.
+func validateTag(name string) error {
+	var fe fieldErrors
+	switch {
+	case name == "":
+		fe.add("name", "is required")
+	case utf8.RuneCountInString(name) > MaxTagNameLen:
+		fe.add("name", "must be at most "+strconv.Itoa(MaxTagNameLen)+" characters")
+	case strings.Contains(name, "/"):
+		fe.add("name", "must not contain /")
+	}
+	return fe.err()
+}
@@ -0,0 +1,256 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+func catalog(t *testing.T) (usecase.CatalogUseCase, *MockCatalogRepository, *MockProductRepository) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	repo := NewMockCatalogRepository(mockCtl)
+	products := NewMockProductRepository(mockCtl)
+
+	return usecase.NewCatalogUseCase(repo, products), repo, products
+}
+
+func catalogTx(repo *MockCatalogRepository) *gomock.Call {
+	return repo.EXPECT().InTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(repository.CatalogRepository) error) error {
+			return fn(repo)
+		})
+}
+
+func categoryID(id uint64) *uint64 {
+	return &id
+}
+
+func TestUpdateCategory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	// 1 > 2 > 3, 4 is another root.
+	tree := map[uint64]*entity.Category{
+		1: {ID: 1, Name: "clothes"},
+		2: {ID: 2, ParentID: categoryID(1), Name: "shoes"},
+		3: {ID: 3, ParentID: categoryID(2), Name: "boots"},
+		4: {ID: 4, Name: "toys"},
+	}
+	getCategory := func(_ context.Context, id uint64) (*entity.Category, error) {
+		return tree[id], nil
+	}
+
+	tests := []struct {
+		name     string
+		category *entity.Category
+		target   error
+	}{
+		{name: "move to other root", category: &entity.Category{ID: 2, ParentID: categoryID(4), Name: "shoes"}},
+		{name: "make root", category: &entity.Category{ID: 3, Name: "boots"}},
+		{name: "below itself", category: &entity.Category{ID: 2, ParentID: categoryID(2), Name: "shoes"}, target: usecase.ErrCategoryCycle},
+		{name: "below descendant", category: &entity.Category{ID: 1, ParentID: categoryID(3), Name: "clothes"}, target: usecase.ErrCategoryCycle},
+		{name: "unknown parent", category: &entity.Category{ID: 2, ParentID: categoryID(9), Name: "shoes"}, target: usecase.ErrValidation},
+		{name: "unknown category", category: &entity.Category{ID: 9, Name: "hats"}, target: usecase.ErrCategoryNotFound},
+		{name: "no name", category: &entity.Category{ID: 2, Name: " "}, target: usecase.ErrValidation},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc, repo, _ := catalog(t)
+			catalogTx(repo).AnyTimes()
+			// The category and the parents walked through are read with a lock.
+			repo.EXPECT().LockCategory(ctx, gomock.Any()).DoAndReturn(getCategory).AnyTimes()
+			repo.EXPECT().GetCategory(ctx, gomock.Any()).DoAndReturn(getCategory).AnyTimes()
+			if tc.target == nil {
+				repo.EXPECT().UpdateCategory(ctx, tc.category).Return(nil)
+			}
+
+			_, err := uc.UpdateCategory(ctx, tc.category)
+			if tc.target != nil {
+				require.ErrorIs(t, err, tc.target)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCreateCategory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo, _ := catalog(t)
+
+	c := &entity.Category{ParentID: categoryID(2), Name: "boots"}
+	catalogTx(repo)
+	gomock.InOrder(
+		repo.EXPECT().LockCategory(ctx, uint64(2)).Return(&entity.Category{ID: 2, ParentID: categoryID(1), Name: "shoes"}, nil),
+		repo.EXPECT().LockCategory(ctx, uint64(1)).Return(&entity.Category{ID: 1, Name: "clothes"}, nil),
+		repo.EXPECT().CreateCategory(ctx, c).Return(uint64(3), nil),
+	)
+	repo.EXPECT().GetCategory(ctx, uint64(3)).Return(&entity.Category{ID: 3, ParentID: categoryID(2), Name: "boots"}, nil)
+
+	created, err := uc.CreateCategory(ctx, c)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), created.ID)
+}
+
+func TestDeleteCategory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo, _ := catalog(t)
+
+	repo.EXPECT().DeleteCategory(ctx, uint64(1)).Return(false, fmt.Errorf("%w: fk", repository.ErrCategoryHasChildren))
+	require.ErrorIs(t, uc.DeleteCategory(ctx, 1), usecase.ErrCategoryHasChildren)
+
+	repo.EXPECT().DeleteCategory(ctx, uint64(9)).Return(false, nil)
+	require.ErrorIs(t, uc.DeleteCategory(ctx, 9), usecase.ErrCategoryNotFound)
+}
+
+func TestAssignCategory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo, products := catalog(t)
+
+	products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil).Times(2)
+	repo.EXPECT().GetCategory(ctx, uint64(2)).Return(&entity.Category{ID: 2, Name: "shoes"}, nil)
+	repo.EXPECT().AssignCategory(ctx, uint64(7), uint64(2)).Return(nil)
+	require.NoError(t, uc.AssignCategory(ctx, 7, 2))
+
+	repo.EXPECT().GetCategory(ctx, uint64(9)).Return(nil, nil)
+	require.ErrorIs(t, uc.AssignCategory(ctx, 7, 9), usecase.ErrCategoryNotFound)
+
+	products.EXPECT().GetByID(ctx, uint64(8), false).Return(nil, nil)
+	require.ErrorIs(t, uc.AssignCategory(ctx, 8, 2), usecase.ErrProductNotFound)
+}
+
+func TestTagProduct(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("existing tag", func(t *testing.T) {
+		t.Parallel()
+
+		uc, repo, products := catalog(t)
+		products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+		repo.EXPECT().GetTagByName(ctx, "summer sale").Return(&entity.Tag{ID: 3, Name: "summer sale"}, nil)
+		repo.EXPECT().TagProduct(ctx, uint64(7), uint64(3)).Return(nil)
+
+		tag, err := uc.TagProduct(ctx, 7, "  Summer Sale ")
+		require.NoError(t, err)
+		require.Equal(t, &entity.Tag{ID: 3, Name: "summer sale"}, tag)
+	})
+
+	t.Run("new tag", func(t *testing.T) {
+		t.Parallel()
+
+		uc, repo, products := catalog(t)
+		products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+		repo.EXPECT().GetTagByName(ctx, "new").Return(nil, nil)
+		repo.EXPECT().CreateTag(ctx, "new").Return(uint64(4), nil)
+		repo.EXPECT().TagProduct(ctx, uint64(7), uint64(4)).Return(nil)
+
+		tag, err := uc.TagProduct(ctx, 7, "new")
+		require.NoError(t, err)
+		require.Equal(t, &entity.Tag{ID: 4, Name: "new"}, tag)
+	})
+
+	t.Run("created concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		uc, repo, products := catalog(t)
+		products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+		gomock.InOrder(
+			repo.EXPECT().GetTagByName(ctx, "new").Return(nil, nil),
+			repo.EXPECT().CreateTag(ctx, "new").Return(uint64(0), repository.ErrDuplicateTag),
+			repo.EXPECT().GetTagByName(ctx, "new").Return(&entity.Tag{ID: 5, Name: "new"}, nil),
+		)
+		repo.EXPECT().TagProduct(ctx, uint64(7), uint64(5)).Return(nil)
+
+		tag, err := uc.TagProduct(ctx, 7, "new")
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), tag.ID)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		t.Parallel()
+
+		uc, _, _ := catalog(t)
+		for _, name := range []string{" ", "a/b", strings.Repeat("a", usecase.MaxTagNameLen+1)} {
+			_, err := uc.TagProduct(ctx, 7, name)
+			require.ErrorIs(t, err, usecase.ErrValidation, name)
+		}
+	})
+}
+
+func TestUntagProduct(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo, products := catalog(t)
+
+	products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil).Times(3)
+	repo.EXPECT().GetTagByName(ctx, "sale").Return(&entity.Tag{ID: 3, Name: "sale"}, nil).Times(2)
+	repo.EXPECT().UntagProduct(ctx, uint64(7), uint64(3)).Return(true, nil)
+	require.NoError(t, uc.UntagProduct(ctx, 7, "Sale"))
+
+	repo.EXPECT().UntagProduct(ctx, uint64(7), uint64(3)).Return(false, nil)
+	require.ErrorIs(t, uc.UntagProduct(ctx, 7, "sale"), usecase.ErrTagNotAssigned)
+
+	repo.EXPECT().GetTagByName(ctx, "other").Return(nil, nil)
+	require.ErrorIs(t, uc.UntagProduct(ctx, 7, "other"), usecase.ErrTagNotFound)
+}
+
+func TestRenameTag(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo, _ := catalog(t)
+
+	repo.EXPECT().GetTag(ctx, uint64(3)).Return(&entity.Tag{ID: 3, Name: "sale"}, nil).Times(2)
+	repo.EXPECT().RenameTag(ctx, uint64(3), "clearance").Return(fmt.Errorf("%w: unique", repository.ErrDuplicateTag))
+	_, err := uc.RenameTag(ctx, 3, "Clearance")
+	require.ErrorIs(t, err, usecase.ErrDuplicateTag)
+
+	// Renaming to the current name is not a write.
+	tag, err := uc.RenameTag(ctx, 3, "SALE")
+	require.NoError(t, err)
+	require.Equal(t, "sale", tag.Name)
+
+	repo.EXPECT().GetTag(ctx, uint64(9)).Return(nil, nil)
+	_, err = uc.RenameTag(ctx, 9, "x")
+	require.ErrorIs(t, err, usecase.ErrTagNotFound)
+}
+
+func TestProductListNormalizesTag(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+	ctx := context.Background()
+
+	repo.EXPECT().
+		List(ctx, &entity.ProductFilter{SortBy: "id", Limit: 21, CategoryID: 2, Tag: "sale"}).
+		Return(nil, uint64(0), nil)
+
+	_, err := uc.List(ctx, &entity.ProductFilter{CategoryID: 2, Tag: " Sale "})
+	require.NoError(t, err)
+}
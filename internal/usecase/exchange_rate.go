@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+//go:generate mockgen -source=exchange_rate.go -destination=./mocks_exchange_rate_provider_test.go -package=usecase_test
+
+const (
+	// _rateScale is the number of decimals rates are stored with, matching the NUMERIC(20,10) column.
+	_rateScale = 10
+	// _dateLayout is accepted wherever a point in time may be given as a whole day.
+	_dateLayout = "2006-01-02"
+)
+
+// ExchangeRateProvider is a source of exchange rates, e.g. a fixture file or a web API.
+type ExchangeRateProvider interface {
+	// Rates returns the rates the source knows, oldest first per pair.
+	// A rate without ValidFrom applies from the moment it is synced.
+	Rates(ctx context.Context) ([]entity.ExchangeRate, error)
+}
+
+type ExchangeRateUseCase interface {
+	// Convert returns m in currency to at the rate that was current at at; an empty at means now.
+	Convert(ctx context.Context, m entity.Money, to, at string) (entity.Money, error)
+	// ConvertProducts sets the ConvertedPrice of every product to its price in currency to at at.
+	ConvertProducts(ctx context.Context, products []*entity.Product, to, at string) error
+	ListRates(ctx context.Context, base, quote string) ([]*entity.ExchangeRate, error)
+	// SyncRates stores the rates of the provider that changed and returns how many did.
+	SyncRates(ctx context.Context) (int, error)
+}
+
+type exchangeRateUseCase struct {
+	repo     repository.ExchangeRateRepository
+	provider ExchangeRateProvider
+}
+
+// NewExchangeRateUseCase -. The provider may be nil when rates are maintained in the store directly.
+func NewExchangeRateUseCase(r repository.ExchangeRateRepository, p ExchangeRateProvider) ExchangeRateUseCase {
+	return &exchangeRateUseCase{repo: r, provider: p}
+}
+
+func (uc *exchangeRateUseCase) Convert(ctx context.Context, m entity.Money, to, at string) (entity.Money, error) {
+	if err := validateConversion(to, &at); err != nil {
+		return entity.Money{}, err
+	}
+	if m.Currency == to {
+		return m, nil
+	}
+	rate, err := uc.rate(ctx, m.Currency, to, at)
+	if err != nil {
+		return entity.Money{}, err
+	}
+	return convertMoney(m, to, rate), nil
+}
+
+func (uc *exchangeRateUseCase) ConvertProducts(ctx context.Context, products []*entity.Product, to, at string) error {
+	if err := validateConversion(to, &at); err != nil {
+		return err
+	}
+	// Every product of a currency is converted at the same rate, so each pair is looked up once.
+	rates := map[string]*big.Rat{to: big.NewRat(1, 1)}
+	for _, p := range products {
+		rate, ok := rates[p.Price.Currency]
+		if !ok {
+			var err error
+			if rate, err = uc.rate(ctx, p.Price.Currency, to, at); err != nil {
+				return err
+			}
+			rates[p.Price.Currency] = rate
+		}
+		converted := convertMoney(p.Price, to, rate)
+		p.ConvertedPrice = &converted
+	}
+	return nil
+}
+
+func (uc *exchangeRateUseCase) ListRates(ctx context.Context, base, quote string) ([]*entity.ExchangeRate, error) {
+	var fe fieldErrors
+	validateCurrency(&fe, "base", base)
+	validateCurrency(&fe, "quote", quote)
+	if err := fe.err(); err != nil {
+		return nil, err
+	}
+	rates, err := uc.repo.ListRates(ctx, base, quote)
+	if err != nil {
+		return nil, err
+	}
+	if rates == nil {
+		rates = []*entity.ExchangeRate{}
+	}
+	return rates, nil
+}
+
+func (uc *exchangeRateUseCase) SyncRates(ctx context.Context) (int, error) {
+	if uc.provider == nil {
+		return 0, nil
+	}
+	rates, err := uc.provider.Rates(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ExchangeRateUseCase - SyncRates - uc.provider.Rates: %w", err)
+	}
+
+	// One invalid rate does not hold back the others.
+	now := time.Now().UTC().Format(entity.DateTimeLayout)
+	var n int
+	var errs []error
+	for i := range rates {
+		rate := rates[i]
+		if rate.ValidFrom == "" {
+			rate.ValidFrom = now
+		}
+		if err := normalizeRate(&rate); err != nil {
+			errs = append(errs, fmt.Errorf("rate %s/%s: %w", rate.Base, rate.Quote, err))
+			continue
+		}
+		saved, err := uc.repo.SaveRate(ctx, &rate)
+		if err != nil {
+			return n, fmt.Errorf("ExchangeRateUseCase - SyncRates - uc.repo.SaveRate: %w", err)
+		}
+		if saved {
+			n++
+		}
+	}
+	return n, errors.Join(errs...)
+}
+
+// rate returns the factor converting major units of from into major units of to at at.
+// A pair without a rate of its own is converted with the inverse of the opposite pair.
+func (uc *exchangeRateUseCase) rate(ctx context.Context, from, to, at string) (*big.Rat, error) {
+	r, err := uc.repo.RateAt(ctx, from, to, at)
+	if err != nil {
+		return nil, err
+	}
+	if r != nil {
+		return parseRate(r)
+	}
+
+	r, err = uc.repo.RateAt(ctx, to, from, at)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("%w: %s to %s at %s", ErrExchangeRateNotFound, from, to, at)
+	}
+	rate, err := parseRate(r)
+	if err != nil {
+		return nil, err
+	}
+	return rate.Inv(rate), nil
+}
+
+func parseRate(r *entity.ExchangeRate) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(r.Rate)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("ExchangeRateUseCase - parseRate - invalid stored rate %s/%s %q", r.Base, r.Quote, r.Rate)
+	}
+	return rate, nil
+}
+
+// validateConversion checks the target currency and normalizes at, which may be a date or empty for now.
+func validateConversion(to string, at *string) error {
+	var fe fieldErrors
+	validateCurrency(&fe, "currency", to)
+	if *at == "" {
+		*at = time.Now().UTC().Format(entity.DateTimeLayout)
+	} else if _, err := time.Parse(entity.DateTimeLayout, *at); err != nil {
+		day, err := time.Parse(_dateLayout, *at)
+		if err != nil {
+			fe.add("date_time", "must be formatted as "+entity.DateTimeLayout)
+		} else {
+			*at = day.Format(entity.DateTimeLayout)
+		}
+	}
+	return fe.err()
+}
+
+// normalizeRate validates a provided rate and writes it with the stored scale and no trailing zeros,
+// so that unchanged rates compare equal to the stored ones.
+func normalizeRate(r *entity.ExchangeRate) error {
+	var fe fieldErrors
+	validateCurrency(&fe, "base", r.Base)
+	validateCurrency(&fe, "quote", r.Quote)
+	if r.Base == r.Quote {
+		fe.add("quote", "must differ from base")
+	}
+	rate, ok := new(big.Rat).SetString(r.Rate)
+	if ok && rate.Sign() > 0 {
+		r.Rate = strings.TrimRight(strings.TrimRight(rate.FloatString(_rateScale), "0"), ".")
+	}
+	if !ok || rate.Sign() <= 0 || r.Rate == "0" {
+		fe.add("rate", "must be a positive decimal with at most "+strconv.Itoa(_rateScale)+" significant decimals")
+	}
+	if _, err := time.Parse(entity.DateTimeLayout, r.ValidFrom); err != nil {
+		fe.add("valid_from", "must be formatted as "+entity.DateTimeLayout)
+	}
+	return fe.err()
+}
+
+// convertMoney converts m with a rate between major units, rounding half away from zero
+// to the minor units of currency to.
+func convertMoney(m entity.Money, to string, rate *big.Rat) entity.Money {
+	fromExp, _ := entity.CurrencyExponent(m.Currency)
+	toExp, _ := entity.CurrencyExponent(to)
+
+	v := new(big.Rat).SetInt64(m.Amount)
+	v.Mul(v, rate)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExp-fromExp))), nil)
+	if toExp >= fromExp {
+		v.Mul(v, new(big.Rat).SetInt(scale))
+	} else {
+		v.Quo(v, new(big.Rat).SetInt(scale))
+	}
+
+	q, r := new(big.Int).QuoRem(v.Num(), v.Denom(), new(big.Int))
+	if r.Sign() != 0 && new(big.Int).Abs(new(big.Int).Lsh(r, 1)).Cmp(v.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(v.Sign())))
+	}
+	return entity.Money{Amount: q.Int64(), Currency: to}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
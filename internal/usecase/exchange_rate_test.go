@@ -0,0 +1,163 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+)
+
+const _at = "2026-03-01 12:00:00"
+
+func exchangeRate(t *testing.T) (usecase.ExchangeRateUseCase, *MockExchangeRateRepository, *MockExchangeRateProvider) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	repo := NewMockExchangeRateRepository(mockCtl)
+	provider := NewMockExchangeRateProvider(mockCtl)
+
+	return usecase.NewExchangeRateUseCase(repo, provider), repo, provider
+}
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		money  entity.Money
+		to     string
+		expect func(repo *MockExchangeRateRepository)
+		want   entity.Money
+	}{
+		{
+			name:  "same currency",
+			money: entity.Money{Amount: 1999, Currency: "EUR"},
+			to:    "EUR",
+			want:  entity.Money{Amount: 1999, Currency: "EUR"},
+		},
+		{
+			name:  "direct rate",
+			money: entity.Money{Amount: 1999, Currency: "USD"},
+			to:    "EUR",
+			expect: func(repo *MockExchangeRateRepository) {
+				repo.EXPECT().RateAt(gomock.Any(), "USD", "EUR", _at).Return(&entity.ExchangeRate{Base: "USD", Quote: "EUR", Rate: "0.91"}, nil)
+			},
+			// 18.1909 is rounded to the cent.
+			want: entity.Money{Amount: 1819, Currency: "EUR"},
+		},
+		{
+			name:  "inverse rate",
+			money: entity.Money{Amount: 1000, Currency: "EUR"},
+			to:    "USD",
+			expect: func(repo *MockExchangeRateRepository) {
+				repo.EXPECT().RateAt(gomock.Any(), "EUR", "USD", _at).Return(nil, nil)
+				repo.EXPECT().RateAt(gomock.Any(), "USD", "EUR", _at).Return(&entity.ExchangeRate{Base: "USD", Quote: "EUR", Rate: "0.8"}, nil)
+			},
+			want: entity.Money{Amount: 1250, Currency: "USD"},
+		},
+		{
+			name:  "to a currency without minor units",
+			money: entity.Money{Amount: 1050, Currency: "USD"},
+			to:    "JPY",
+			expect: func(repo *MockExchangeRateRepository) {
+				repo.EXPECT().RateAt(gomock.Any(), "USD", "JPY", _at).Return(&entity.ExchangeRate{Base: "USD", Quote: "JPY", Rate: "148.5"}, nil)
+			},
+			// 1559.25 JPY
+			want: entity.Money{Amount: 1559, Currency: "JPY"},
+		},
+		{
+			name:  "half rounds away from zero",
+			money: entity.Money{Amount: 1, Currency: "USD"},
+			to:    "EUR",
+			expect: func(repo *MockExchangeRateRepository) {
+				repo.EXPECT().RateAt(gomock.Any(), "USD", "EUR", _at).Return(&entity.ExchangeRate{Base: "USD", Quote: "EUR", Rate: "2.5"}, nil)
+			},
+			want: entity.Money{Amount: 3, Currency: "EUR"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc, repo, _ := exchangeRate(t)
+			if tc.expect != nil {
+				tc.expect(repo)
+			}
+
+			got, err := uc.Convert(context.Background(), tc.money, tc.to, _at)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	t.Parallel()
+
+	uc, repo, _ := exchangeRate(t)
+
+	usd := entity.Money{Amount: 100, Currency: "USD"}
+
+	_, err := uc.Convert(context.Background(), usd, "XXX", _at)
+	require.ErrorIs(t, err, usecase.ErrValidation)
+
+	_, err = uc.Convert(context.Background(), usd, "EUR", "yesterday")
+	require.ErrorIs(t, err, usecase.ErrValidation)
+
+	repo.EXPECT().RateAt(gomock.Any(), "USD", "EUR", _at).Return(nil, nil)
+	repo.EXPECT().RateAt(gomock.Any(), "EUR", "USD", _at).Return(nil, nil)
+
+	_, err = uc.Convert(context.Background(), usd, "EUR", _at)
+	require.ErrorIs(t, err, usecase.ErrExchangeRateNotFound)
+	require.ErrorIs(t, err, usecase.ErrNotFound)
+}
+
+func TestConvertProducts(t *testing.T) {
+	t.Parallel()
+
+	uc, repo, _ := exchangeRate(t)
+
+	products := []*entity.Product{
+		{ID: "1", Price: entity.Money{Amount: 100, Currency: "USD"}},
+		{ID: "2", Price: entity.Money{Amount: 200, Currency: "USD"}},
+		{ID: "3", Price: entity.Money{Amount: 300, Currency: "EUR"}},
+	}
+
+	// The USD rate is looked up once for both USD products.
+	repo.EXPECT().RateAt(gomock.Any(), "USD", "EUR", gomock.Any()).Return(&entity.ExchangeRate{Rate: "0.9"}, nil)
+
+	err := uc.ConvertProducts(context.Background(), products, "EUR", "")
+	require.NoError(t, err)
+	require.Equal(t, &entity.Money{Amount: 90, Currency: "EUR"}, products[0].ConvertedPrice)
+	require.Equal(t, &entity.Money{Amount: 180, Currency: "EUR"}, products[1].ConvertedPrice)
+	require.Equal(t, &entity.Money{Amount: 300, Currency: "EUR"}, products[2].ConvertedPrice)
+}
+
+func TestSyncRates(t *testing.T) {
+	t.Parallel()
+
+	uc, repo, provider := exchangeRate(t)
+
+	provider.EXPECT().Rates(gomock.Any()).Return([]entity.ExchangeRate{
+		{Base: "USD", Quote: "EUR", Rate: "0.9100", ValidFrom: "2026-01-01 00:00:00"},
+		{Base: "USD", Quote: "GBP", Rate: "0.74", ValidFrom: "2026-01-01 00:00:00"},
+		{Base: "USD", Quote: "USD", Rate: "1", ValidFrom: "2026-01-01 00:00:00"},
+	}, nil)
+
+	// Rates are stored without padding; an unchanged rate is not counted.
+	repo.EXPECT().SaveRate(gomock.Any(), &entity.ExchangeRate{Base: "USD", Quote: "EUR", Rate: "0.91", ValidFrom: "2026-01-01 00:00:00"}).Return(true, nil)
+	repo.EXPECT().SaveRate(gomock.Any(), &entity.ExchangeRate{Base: "USD", Quote: "GBP", Rate: "0.74", ValidFrom: "2026-01-01 00:00:00"}).Return(false, nil)
+
+	n, err := uc.SyncRates(context.Background())
+	require.ErrorIs(t, err, usecase.ErrValidation)
+	require.Equal(t, 1, n)
+}
@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+// Limits of stock writes; reference matches its VARCHAR column.
+const (
+	MaxStockReferenceLen = 255
+	MaxStockItems        = 100
+)
+
+// InventoryUseCase keeps the stock ledger of live products. Every write appends movements to the
+// ledger with the entity.Change carried by its ctx; the available stock of a product is what is on
+// hand less what is reserved, and no write makes it negative.
+type InventoryUseCase interface {
+	Stock(ctx context.Context, productID uint64) (*entity.StockLevel, error)
+	// Movements returns the stock ledger of a product, oldest first.
+	Movements(ctx context.Context, productID uint64) ([]*entity.StockMovement, error)
+	// Receive adds quantity to the stock on hand of a product.
+	Receive(ctx context.Context, productID uint64, quantity int64) (*entity.StockLevel, error)
+	// Adjust corrects the stock on hand of a product by delta; stock that is reserved cannot be removed.
+	Adjust(ctx context.Context, productID uint64, delta int64) (*entity.StockLevel, error)
+	// Reserve sets items aside for reference in one transaction: either every item is reserved or,
+	// when a product has too little available stock, none is and ErrInsufficientStock is returned.
+	// The levels are returned in the order of items. A reference reserves a product once: repeating
+	// the reservation of a product changes nothing, and repeating it with another quantity gives
+	// ErrStockReferenceUsed. Release and Fulfil are repeated the same way.
+	Reserve(ctx context.Context, reference string, items []entity.StockItem) ([]*entity.StockLevel, error)
+	// Release returns items reserved for reference to the available stock, all or none like Reserve.
+	Release(ctx context.Context, reference string, items []entity.StockItem) ([]*entity.StockLevel, error)
+	// Fulfil removes items reserved for reference from the stock on hand as they ship, all or none like Reserve.
+	Fulfil(ctx context.Context, reference string, items []entity.StockItem) ([]*entity.StockLevel, error)
+	// LowStock returns up to limit live products with at most threshold available, lowest first.
+	LowStock(ctx context.Context, threshold int64, limit uint64) ([]*entity.StockLevel, error)
+}
+
+type inventoryUseCase struct {
+	repo     repository.InventoryRepository
+	products repository.ProductRepository
+}
+
+func NewInventoryUseCase(r repository.InventoryRepository, products repository.ProductRepository) InventoryUseCase {
+	return &inventoryUseCase{repo: r, products: products}
+}
+
+func (uc *inventoryUseCase) Stock(ctx context.Context, productID uint64) (*entity.StockLevel, error) {
+	if err := uc.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return uc.repo.GetLevel(ctx, productID)
+}
+
+func (uc *inventoryUseCase) Movements(ctx context.Context, productID uint64) ([]*entity.StockMovement, error) {
+	if err := uc.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListMovements(ctx, productID)
+}
+
+func (uc *inventoryUseCase) Receive(ctx context.Context, productID uint64, quantity int64) (*entity.StockLevel, error) {
+	var fe fieldErrors
+	if quantity <= 0 {
+		fe.add("quantity", "must be positive")
+	}
+	return uc.recordOne(ctx, fe, entity.StockReceipt, productID, quantity)
+}
+
+func (uc *inventoryUseCase) Adjust(ctx context.Context, productID uint64, delta int64) (*entity.StockLevel, error) {
+	var fe fieldErrors
+	if delta == 0 {
+		fe.add("quantity", "must not be zero")
+	}
+	return uc.recordOne(ctx, fe, entity.StockAdjustment, productID, delta)
+}
+
+func (uc *inventoryUseCase) Reserve(ctx context.Context, reference string, items []entity.StockItem) ([]*entity.StockLevel, error) {
+	if err := validateChange(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateStockItems(reference, items); err != nil {
+		return nil, err
+	}
+	return uc.record(ctx, entity.StockReservation, reference, items)
+}
+
+func (uc *inventoryUseCase) Release(ctx context.Context, reference string, items []entity.StockItem) ([]*entity.StockLevel, error) {
+	if err := validateChange(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateStockItems(reference, items); err != nil {
+		return nil, err
+	}
+	return uc.record(ctx, entity.StockRelease, reference, items)
+}
+
+func (uc *inventoryUseCase) Fulfil(ctx context.Context, reference string, items []entity.StockItem) ([]*entity.StockLevel, error) {
+	if err := validateChange(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateStockItems(reference, items); err != nil {
+		return nil, err
+	}
+	return uc.record(ctx, entity.StockFulfilment, reference, items)
+}
+
+func (uc *inventoryUseCase) LowStock(ctx context.Context, threshold int64, limit uint64) ([]*entity.StockLevel, error) {
+	if threshold < 0 {
+		var fe fieldErrors
+		fe.add("threshold", "must not be negative")
+		return nil, fe.err()
+	}
+	if limit == 0 {
+		limit = _defaultListLimit
+	}
+	if limit > _maxListLimit {
+		limit = _maxListLimit
+	}
+	return uc.repo.LowStock(ctx, threshold, limit)
+}
+
+// recordOne records a movement of a single product once fe, the validation of its quantity, passed.
+func (uc *inventoryUseCase) recordOne(ctx context.Context, fe fieldErrors, kind string, productID uint64, quantity int64) (*entity.StockLevel, error) {
+	if err := validateChange(ctx); err != nil {
+		return nil, err
+	}
+	if err := fe.err(); err != nil {
+		return nil, err
+	}
+	levels, err := uc.record(ctx, kind, "", []entity.StockItem{{ProductID: productID, Quantity: quantity}})
+	if err != nil {
+		return nil, err
+	}
+	return levels[0], nil
+}
+
+// record appends a movement of kind for every item in one transaction. The rules of kind are checked
+// against the locked stock levels, so concurrent writes cannot oversell; the levels are locked in
+// product order, so concurrent writes of several products cannot deadlock. An item whose movement the
+// reference already recorded is skipped.
+func (uc *inventoryUseCase) record(ctx context.Context, kind, reference string, items []entity.StockItem) ([]*entity.StockLevel, error) {
+	for _, item := range items {
+		if err := uc.checkProduct(ctx, item.ProductID); err != nil {
+			return nil, fmt.Errorf("product %d: %w", item.ProductID, err)
+		}
+	}
+	sorted := append([]entity.StockItem(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ProductID < sorted[j].ProductID })
+
+	levels := make(map[uint64]*entity.StockLevel, len(items))
+	err := uc.repo.InTx(ctx, func(repo repository.InventoryRepository) error {
+		for _, item := range sorted {
+			level, err := repo.LockLevel(ctx, item.ProductID)
+			if err != nil {
+				return err
+			}
+			if reference != "" {
+				recorded, err := repo.GetMovement(ctx, item.ProductID, reference, kind)
+				if err != nil {
+					return err
+				}
+				if recorded != nil {
+					if recorded.Quantity != item.Quantity {
+						return fmt.Errorf("product %d: %w", item.ProductID, ErrStockReferenceUsed)
+					}
+					levels[item.ProductID] = level
+					continue
+				}
+			}
+			if err := applyMovement(ctx, repo, level, kind, reference, item.Quantity); err != nil {
+				return fmt.Errorf("product %d: %w", item.ProductID, err)
+			}
+			m := &entity.StockMovement{ProductID: item.ProductID, Kind: kind, Quantity: item.Quantity, Reference: reference}
+			if _, err := repo.Record(ctx, m, level); err != nil {
+				if errors.Is(err, repository.ErrDuplicateStockMovement) {
+					return fmt.Errorf("product %d: %w", item.ProductID, ErrStockReferenceUsed)
+				}
+				return err
+			}
+			if levels[item.ProductID], err = repo.GetLevel(ctx, item.ProductID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*entity.StockLevel, len(items))
+	for i, item := range items {
+		result[i] = levels[item.ProductID]
+	}
+	return result, nil
+}
+
+func (uc *inventoryUseCase) checkProduct(ctx context.Context, id uint64) error {
+	p, err := uc.products.GetByID(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// applyMovement changes the locked level by a movement of kind, or tells why the movement is not possible.
+func applyMovement(ctx context.Context, repo repository.InventoryRepository, level *entity.StockLevel, kind, reference string, quantity int64) error {
+	switch kind {
+	case entity.StockReceipt:
+		level.OnHand += quantity
+	case entity.StockAdjustment:
+		if level.OnHand+quantity < level.Reserved {
+			return ErrInsufficientStock
+		}
+		level.OnHand += quantity
+	case entity.StockReservation:
+		if level.OnHand-level.Reserved < quantity {
+			return ErrInsufficientStock
+		}
+		level.Reserved += quantity
+	case entity.StockRelease:
+		reserved, err := repo.Reserved(ctx, level.ProductID, reference)
+		if err != nil {
+			return err
+		}
+		if reserved < quantity {
+			return ErrReleaseExceedsReservation
+		}
+		level.Reserved -= quantity
+	case entity.StockFulfilment:
+		reserved, err := repo.Reserved(ctx, level.ProductID, reference)
+		if err != nil {
+			return err
+		}
+		if reserved < quantity {
+			return ErrFulfilmentExceedsReservation
+		}
+		level.Reserved -= quantity
+		level.OnHand -= quantity
+	}
+	level.Available = level.OnHand - level.Reserved
+	return nil
+}
+
+// validateStockItems checks the payload of Reserve, Release and Fulfil.
+func validateStockItems(reference string, items []entity.StockItem) error {
+	var fe fieldErrors
+	switch {
+	case strings.TrimSpace(reference) == "":
+		fe.add("reference", "is required")
+	case utf8.RuneCountInString(reference) > MaxStockReferenceLen:
+		fe.add("reference", "must be at most "+strconv.Itoa(MaxStockReferenceLen)+" characters")
+	}
+	switch {
+	case len(items) == 0:
+		fe.add("items", "is required")
+	case len(items) > MaxStockItems:
+		fe.add("items", "must have at most "+strconv.Itoa(MaxStockItems)+" entries")
+	}
+	seen := make(map[uint64]bool, len(items))
+	for i, item := range items {
+		field := "items[" + strconv.Itoa(i) + "]"
+		switch {
+		case item.ProductID == 0:
+			fe.add(field+".product_id", "is required")
+		case seen[item.ProductID]:
+			fe.add(field+".product_id", "is listed more than once")
+		}
+		seen[item.ProductID] = true
+		if item.Quantity <= 0 {
+			fe.add(field+".quantity", "must be positive")
+		}
+	}
+	return fe.err()
+}
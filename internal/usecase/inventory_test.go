@@ -0,0 +1,291 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+func inventory(t *testing.T) (usecase.InventoryUseCase, *MockInventoryRepository, *MockProductRepository) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	repo := NewMockInventoryRepository(mockCtl)
+	products := NewMockProductRepository(mockCtl)
+
+	return usecase.NewInventoryUseCase(repo, products), repo, products
+}
+
+// inventoryTx makes InTx of repo run fn with repo itself.
+func inventoryTx(repo *MockInventoryRepository) *gomock.Call {
+	return repo.EXPECT().InTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(repository.InventoryRepository) error) error {
+			return fn(repo)
+		})
+}
+
+// stockLevel is a level of a product with its available stock filled in.
+func stockLevel(productID uint64, onHand, reserved int64) *entity.StockLevel {
+	return &entity.StockLevel{ProductID: productID, OnHand: onHand, Reserved: reserved, Available: onHand - reserved}
+}
+
+func TestReserveStock(t *testing.T) {
+	t.Parallel()
+
+	ctx := entity.WithChange(context.Background(), entity.Change{Actor: "shop"})
+	items := []entity.StockItem{{ProductID: 7, Quantity: 2}, {ProductID: 3, Quantity: 1}}
+	live := func(products *MockProductRepository) {
+		products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+		products.EXPECT().GetByID(ctx, uint64(3), false).Return(&entity.Product{ID: "3"}, nil)
+	}
+
+	t.Run("every item available", func(t *testing.T) {
+		t.Parallel()
+
+		uc, repo, products := inventory(t)
+		live(products)
+		inventoryTx(repo)
+		gomock.InOrder(
+			repo.EXPECT().LockLevel(ctx, uint64(3)).Return(stockLevel(3, 1, 0), nil),
+			repo.EXPECT().GetMovement(ctx, uint64(3), "order-1", entity.StockReservation).Return(nil, nil),
+			repo.EXPECT().Record(ctx, &entity.StockMovement{ProductID: 3, Kind: entity.StockReservation, Quantity: 1, Reference: "order-1"}, stockLevel(3, 1, 1)).Return(uint64(10), nil),
+			repo.EXPECT().GetLevel(ctx, uint64(3)).Return(stockLevel(3, 1, 1), nil),
+			repo.EXPECT().LockLevel(ctx, uint64(7)).Return(stockLevel(7, 5, 3), nil),
+			repo.EXPECT().GetMovement(ctx, uint64(7), "order-1", entity.StockReservation).Return(nil, nil),
+			repo.EXPECT().Record(ctx, &entity.StockMovement{ProductID: 7, Kind: entity.StockReservation, Quantity: 2, Reference: "order-1"}, stockLevel(7, 5, 5)).Return(uint64(11), nil),
+			repo.EXPECT().GetLevel(ctx, uint64(7)).Return(stockLevel(7, 5, 5), nil),
+		)
+
+		levels, err := uc.Reserve(ctx, "order-1", items)
+		require.NoError(t, err)
+		require.Equal(t, []*entity.StockLevel{stockLevel(7, 5, 5), stockLevel(3, 1, 1)}, levels)
+	})
+
+	t.Run("one item short", func(t *testing.T) {
+		t.Parallel()
+
+		uc, repo, products := inventory(t)
+		live(products)
+		inventoryTx(repo)
+		repo.EXPECT().LockLevel(ctx, uint64(3)).Return(stockLevel(3, 1, 0), nil)
+		repo.EXPECT().GetMovement(ctx, gomock.Any(), "order-1", entity.StockReservation).Return(nil, nil).Times(2)
+		repo.EXPECT().Record(ctx, gomock.Any(), stockLevel(3, 1, 1)).Return(uint64(10), nil)
+		repo.EXPECT().GetLevel(ctx, uint64(3)).Return(stockLevel(3, 1, 1), nil)
+		repo.EXPECT().LockLevel(ctx, uint64(7)).Return(stockLevel(7, 5, 4), nil)
+
+		_, err := uc.Reserve(ctx, "order-1", items)
+		require.ErrorIs(t, err, usecase.ErrInsufficientStock)
+		require.ErrorIs(t, err, usecase.ErrConflict)
+		require.Contains(t, err.Error(), "product 7")
+	})
+
+	t.Run("repeated", func(t *testing.T) {
+		t.Parallel()
+
+		uc, repo, products := inventory(t)
+		live(products)
+		inventoryTx(repo)
+		// Product 3 was reserved by an earlier, retried request; product 7 is new.
+		gomock.InOrder(
+			repo.EXPECT().LockLevel(ctx, uint64(3)).Return(stockLevel(3, 1, 1), nil),
+			repo.EXPECT().GetMovement(ctx, uint64(3), "order-1", entity.StockReservation).
+				Return(&entity.StockMovement{ID: 10, ProductID: 3, Kind: entity.StockReservation, Quantity: 1, Reference: "order-1"}, nil),
+			repo.EXPECT().LockLevel(ctx, uint64(7)).Return(stockLevel(7, 5, 3), nil),
+			repo.EXPECT().GetMovement(ctx, uint64(7), "order-1", entity.StockReservation).Return(nil, nil),
+			repo.EXPECT().Record(ctx, gomock.Any(), stockLevel(7, 5, 5)).Return(uint64(11), nil),
+			repo.EXPECT().GetLevel(ctx, uint64(7)).Return(stockLevel(7, 5, 5), nil),
+		)
+
+		levels, err := uc.Reserve(ctx, "order-1", items)
+		require.NoError(t, err)
+		require.Equal(t, []*entity.StockLevel{stockLevel(7, 5, 5), stockLevel(3, 1, 1)}, levels)
+	})
+
+	t.Run("repeated with another quantity", func(t *testing.T) {
+		t.Parallel()
+
+		uc, repo, products := inventory(t)
+		live(products)
+		inventoryTx(repo)
+		repo.EXPECT().LockLevel(ctx, uint64(3)).Return(stockLevel(3, 2, 2), nil)
+		repo.EXPECT().GetMovement(ctx, uint64(3), "order-1", entity.StockReservation).
+			Return(&entity.StockMovement{ID: 10, ProductID: 3, Kind: entity.StockReservation, Quantity: 2, Reference: "order-1"}, nil)
+
+		_, err := uc.Reserve(ctx, "order-1", items)
+		require.ErrorIs(t, err, usecase.ErrStockReferenceUsed)
+		require.Contains(t, err.Error(), "product 3")
+	})
+
+	t.Run("reserved concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		uc, repo, products := inventory(t)
+		live(products)
+		inventoryTx(repo)
+		repo.EXPECT().LockLevel(ctx, uint64(3)).Return(stockLevel(3, 1, 0), nil)
+		repo.EXPECT().GetMovement(ctx, uint64(3), "order-1", entity.StockReservation).Return(nil, nil)
+		repo.EXPECT().Record(ctx, gomock.Any(), stockLevel(3, 1, 1)).Return(uint64(0), repository.ErrDuplicateStockMovement)
+
+		_, err := uc.Reserve(ctx, "order-1", items)
+		require.ErrorIs(t, err, usecase.ErrStockReferenceUsed)
+	})
+
+	t.Run("unknown product", func(t *testing.T) {
+		t.Parallel()
+
+		uc, _, products := inventory(t)
+		products.EXPECT().GetByID(ctx, uint64(7), false).Return(nil, nil)
+
+		_, err := uc.Reserve(ctx, "order-1", items)
+		require.ErrorIs(t, err, usecase.ErrProductNotFound)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		uc, _, _ := inventory(t)
+		_, err := uc.Reserve(ctx, "", []entity.StockItem{{ProductID: 7, Quantity: 1}, {ProductID: 7, Quantity: 0}})
+
+		var ve *usecase.ValidationError
+		require.ErrorAs(t, err, &ve)
+		require.Equal(t, []usecase.FieldError{
+			{Field: "reference", Message: "is required"},
+			{Field: "items[1].product_id", Message: "is listed more than once"},
+			{Field: "items[1].quantity", Message: "must be positive"},
+		}, ve.Fields)
+	})
+}
+
+func TestReleaseStock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		reserved int64
+		target   error
+	}{
+		{name: "reserved for the reference", reserved: 3},
+		{name: "more than reserved for the reference", reserved: 1, target: usecase.ErrReleaseExceedsReservation},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc, repo, products := inventory(t)
+			products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+			inventoryTx(repo)
+			repo.EXPECT().LockLevel(ctx, uint64(7)).Return(stockLevel(7, 5, 4), nil)
+			repo.EXPECT().GetMovement(ctx, uint64(7), "order-1", entity.StockRelease).Return(nil, nil)
+			repo.EXPECT().Reserved(ctx, uint64(7), "order-1").Return(tc.reserved, nil)
+			if tc.target == nil {
+				repo.EXPECT().Record(ctx, gomock.Any(), stockLevel(7, 5, 2)).Return(uint64(12), nil)
+				repo.EXPECT().GetLevel(ctx, uint64(7)).Return(stockLevel(7, 5, 2), nil)
+			}
+
+			levels, err := uc.Release(ctx, "order-1", []entity.StockItem{{ProductID: 7, Quantity: 2}})
+			if tc.target != nil {
+				require.ErrorIs(t, err, tc.target)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, int64(3), levels[0].Available)
+		})
+	}
+}
+
+func TestFulfilStock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		reserved int64
+		target   error
+	}{
+		{name: "reserved for the reference", reserved: 3},
+		{name: "more than reserved for the reference", reserved: 1, target: usecase.ErrFulfilmentExceedsReservation},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc, repo, products := inventory(t)
+			products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+			inventoryTx(repo)
+			repo.EXPECT().LockLevel(ctx, uint64(7)).Return(stockLevel(7, 5, 4), nil)
+			repo.EXPECT().GetMovement(ctx, uint64(7), "order-1", entity.StockFulfilment).Return(nil, nil)
+			repo.EXPECT().Reserved(ctx, uint64(7), "order-1").Return(tc.reserved, nil)
+			if tc.target == nil {
+				// Shipped stock leaves both the reservation and the stock on hand.
+				repo.EXPECT().Record(ctx, &entity.StockMovement{ProductID: 7, Kind: entity.StockFulfilment, Quantity: 2, Reference: "order-1"}, stockLevel(7, 3, 2)).Return(uint64(14), nil)
+				repo.EXPECT().GetLevel(ctx, uint64(7)).Return(stockLevel(7, 3, 2), nil)
+			}
+
+			levels, err := uc.Fulfil(ctx, "order-1", []entity.StockItem{{ProductID: 7, Quantity: 2}})
+			if tc.target != nil {
+				require.ErrorIs(t, err, tc.target)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []*entity.StockLevel{stockLevel(7, 3, 2)}, levels)
+		})
+	}
+}
+
+func TestAdjustStock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo, products := inventory(t)
+
+	_, err := uc.Adjust(ctx, 7, 0)
+	require.ErrorIs(t, err, usecase.ErrValidation)
+
+	products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil).Times(2)
+	inventoryTx(repo).Times(2)
+	repo.EXPECT().LockLevel(ctx, uint64(7)).Return(stockLevel(7, 5, 4), nil)
+	repo.EXPECT().LockLevel(ctx, uint64(7)).Return(stockLevel(7, 5, 4), nil)
+	repo.EXPECT().Record(ctx, &entity.StockMovement{ProductID: 7, Kind: entity.StockAdjustment, Quantity: -1}, stockLevel(7, 4, 4)).Return(uint64(13), nil)
+	repo.EXPECT().GetLevel(ctx, uint64(7)).Return(stockLevel(7, 4, 4), nil)
+
+	level, err := uc.Adjust(ctx, 7, -1)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), level.Available)
+
+	// Reserved stock cannot be written off
+	_, err = uc.Adjust(ctx, 7, -2)
+	require.ErrorIs(t, err, usecase.ErrInsufficientStock)
+}
+
+func TestLowStock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo, _ := inventory(t)
+
+	_, err := uc.LowStock(ctx, -1, 0)
+	require.ErrorIs(t, err, usecase.ErrValidation)
+
+	repo.EXPECT().LowStock(ctx, int64(5), uint64(20)).Return([]*entity.StockLevel{stockLevel(3, 0, 0)}, nil)
+
+	levels, err := uc.LowStock(ctx, 5, 0)
+	require.NoError(t, err)
+	require.Len(t, levels, 1)
+}
@@ -0,0 +1,317 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/usecase/repository/catalog.go
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/dariuszdroba/go-from-template/internal/entity"
+	repository "github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCatalogRepository is a mock of CatalogRepository interface.
+type MockCatalogRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCatalogRepositoryMockRecorder
+}
+
+// MockCatalogRepositoryMockRecorder is the mock recorder for MockCatalogRepository.
+type MockCatalogRepositoryMockRecorder struct {
+	mock *MockCatalogRepository
+}
+
+// NewMockCatalogRepository creates a new mock instance.
+func NewMockCatalogRepository(ctrl *gomock.Controller) *MockCatalogRepository {
+	mock := &MockCatalogRepository{ctrl: ctrl}
+	mock.recorder = &MockCatalogRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCatalogRepository) EXPECT() *MockCatalogRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AssignCategory mocks base method.
+func (m *MockCatalogRepository) AssignCategory(ctx context.Context, productID, categoryID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignCategory", ctx, productID, categoryID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignCategory indicates an expected call of AssignCategory.
+func (mr *MockCatalogRepositoryMockRecorder) AssignCategory(ctx, productID, categoryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignCategory", reflect.TypeOf((*MockCatalogRepository)(nil).AssignCategory), ctx, productID, categoryID)
+}
+
+// CreateCategory mocks base method.
+func (m *MockCatalogRepository) CreateCategory(ctx context.Context, c *entity.Category) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategory", ctx, c)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategory indicates an expected call of CreateCategory.
+func (mr *MockCatalogRepositoryMockRecorder) CreateCategory(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockCatalogRepository)(nil).CreateCategory), ctx, c)
+}
+
+// CreateTag mocks base method.
+func (m *MockCatalogRepository) CreateTag(ctx context.Context, name string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", ctx, name)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockCatalogRepositoryMockRecorder) CreateTag(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockCatalogRepository)(nil).CreateTag), ctx, name)
+}
+
+// DeleteCategory mocks base method.
+func (m *MockCatalogRepository) DeleteCategory(ctx context.Context, id uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockCatalogRepositoryMockRecorder) DeleteCategory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockCatalogRepository)(nil).DeleteCategory), ctx, id)
+}
+
+// DeleteTag mocks base method.
+func (m *MockCatalogRepository) DeleteTag(ctx context.Context, id uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MockCatalogRepositoryMockRecorder) DeleteTag(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockCatalogRepository)(nil).DeleteTag), ctx, id)
+}
+
+// GetCategory mocks base method.
+func (m *MockCatalogRepository) GetCategory(ctx context.Context, id uint64) (*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategory", ctx, id)
+	ret0, _ := ret[0].(*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategory indicates an expected call of GetCategory.
+func (mr *MockCatalogRepositoryMockRecorder) GetCategory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockCatalogRepository)(nil).GetCategory), ctx, id)
+}
+
+// GetTag mocks base method.
+func (m *MockCatalogRepository) GetTag(ctx context.Context, id uint64) (*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTag", ctx, id)
+	ret0, _ := ret[0].(*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTag indicates an expected call of GetTag.
+func (mr *MockCatalogRepositoryMockRecorder) GetTag(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTag", reflect.TypeOf((*MockCatalogRepository)(nil).GetTag), ctx, id)
+}
+
+// GetTagByName mocks base method.
+func (m *MockCatalogRepository) GetTagByName(ctx context.Context, name string) (*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTagByName", ctx, name)
+	ret0, _ := ret[0].(*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTagByName indicates an expected call of GetTagByName.
+func (mr *MockCatalogRepositoryMockRecorder) GetTagByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagByName", reflect.TypeOf((*MockCatalogRepository)(nil).GetTagByName), ctx, name)
+}
+
+// InTx mocks base method.
+func (m *MockCatalogRepository) InTx(ctx context.Context, fn func(repository.CatalogRepository) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InTx indicates an expected call of InTx.
+func (mr *MockCatalogRepositoryMockRecorder) InTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InTx", reflect.TypeOf((*MockCatalogRepository)(nil).InTx), ctx, fn)
+}
+
+// ListCategories mocks base method.
+func (m *MockCatalogRepository) ListCategories(ctx context.Context) ([]*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategories", ctx)
+	ret0, _ := ret[0].([]*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategories indicates an expected call of ListCategories.
+func (mr *MockCatalogRepositoryMockRecorder) ListCategories(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategories", reflect.TypeOf((*MockCatalogRepository)(nil).ListCategories), ctx)
+}
+
+// ListTags mocks base method.
+func (m *MockCatalogRepository) ListTags(ctx context.Context) ([]*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags", ctx)
+	ret0, _ := ret[0].([]*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockCatalogRepositoryMockRecorder) ListTags(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockCatalogRepository)(nil).ListTags), ctx)
+}
+
+// LockCategory mocks base method.
+func (m *MockCatalogRepository) LockCategory(ctx context.Context, id uint64) (*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockCategory", ctx, id)
+	ret0, _ := ret[0].(*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockCategory indicates an expected call of LockCategory.
+func (mr *MockCatalogRepositoryMockRecorder) LockCategory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockCategory", reflect.TypeOf((*MockCatalogRepository)(nil).LockCategory), ctx, id)
+}
+
+// ProductCategories mocks base method.
+func (m *MockCatalogRepository) ProductCategories(ctx context.Context, productID uint64) ([]*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProductCategories", ctx, productID)
+	ret0, _ := ret[0].([]*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProductCategories indicates an expected call of ProductCategories.
+func (mr *MockCatalogRepositoryMockRecorder) ProductCategories(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProductCategories", reflect.TypeOf((*MockCatalogRepository)(nil).ProductCategories), ctx, productID)
+}
+
+// ProductTags mocks base method.
+func (m *MockCatalogRepository) ProductTags(ctx context.Context, productID uint64) ([]*entity.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProductTags", ctx, productID)
+	ret0, _ := ret[0].([]*entity.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProductTags indicates an expected call of ProductTags.
+func (mr *MockCatalogRepositoryMockRecorder) ProductTags(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProductTags", reflect.TypeOf((*MockCatalogRepository)(nil).ProductTags), ctx, productID)
+}
+
+// RenameTag mocks base method.
+func (m *MockCatalogRepository) RenameTag(ctx context.Context, id uint64, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameTag", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameTag indicates an expected call of RenameTag.
+func (mr *MockCatalogRepositoryMockRecorder) RenameTag(ctx, id, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameTag", reflect.TypeOf((*MockCatalogRepository)(nil).RenameTag), ctx, id, name)
+}
+
+// TagProduct mocks base method.
+func (m *MockCatalogRepository) TagProduct(ctx context.Context, productID, tagID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagProduct", ctx, productID, tagID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TagProduct indicates an expected call of TagProduct.
+func (mr *MockCatalogRepositoryMockRecorder) TagProduct(ctx, productID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagProduct", reflect.TypeOf((*MockCatalogRepository)(nil).TagProduct), ctx, productID, tagID)
+}
+
+// UnassignCategory mocks base method.
+func (m *MockCatalogRepository) UnassignCategory(ctx context.Context, productID, categoryID uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnassignCategory", ctx, productID, categoryID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnassignCategory indicates an expected call of UnassignCategory.
+func (mr *MockCatalogRepositoryMockRecorder) UnassignCategory(ctx, productID, categoryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnassignCategory", reflect.TypeOf((*MockCatalogRepository)(nil).UnassignCategory), ctx, productID, categoryID)
+}
+
+// UntagProduct mocks base method.
+func (m *MockCatalogRepository) UntagProduct(ctx context.Context, productID, tagID uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UntagProduct", ctx, productID, tagID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UntagProduct indicates an expected call of UntagProduct.
+func (mr *MockCatalogRepositoryMockRecorder) UntagProduct(ctx, productID, tagID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UntagProduct", reflect.TypeOf((*MockCatalogRepository)(nil).UntagProduct), ctx, productID, tagID)
+}
+
+// UpdateCategory mocks base method.
+func (m *MockCatalogRepository) UpdateCategory(ctx context.Context, c *entity.Category) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategory", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCategory indicates an expected call of UpdateCategory.
+func (mr *MockCatalogRepositoryMockRecorder) UpdateCategory(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*MockCatalogRepository)(nil).UpdateCategory), ctx, c)
+}
@@ -0,0 +1,133 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/usecase/exchange_rate.go
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/dariuszdroba/go-from-template/internal/entity"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockExchangeRateProvider is a mock of ExchangeRateProvider interface.
+type MockExchangeRateProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockExchangeRateProviderMockRecorder
+}
+
+// MockExchangeRateProviderMockRecorder is the mock recorder for MockExchangeRateProvider.
+type MockExchangeRateProviderMockRecorder struct {
+	mock *MockExchangeRateProvider
+}
+
+// NewMockExchangeRateProvider creates a new mock instance.
+func NewMockExchangeRateProvider(ctrl *gomock.Controller) *MockExchangeRateProvider {
+	mock := &MockExchangeRateProvider{ctrl: ctrl}
+	mock.recorder = &MockExchangeRateProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExchangeRateProvider) EXPECT() *MockExchangeRateProviderMockRecorder {
+	return m.recorder
+}
+
+// Rates mocks base method.
+func (m *MockExchangeRateProvider) Rates(ctx context.Context) ([]entity.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rates", ctx)
+	ret0, _ := ret[0].([]entity.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rates indicates an expected call of Rates.
+func (mr *MockExchangeRateProviderMockRecorder) Rates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rates", reflect.TypeOf((*MockExchangeRateProvider)(nil).Rates), ctx)
+}
+
+// MockExchangeRateUseCase is a mock of ExchangeRateUseCase interface.
+type MockExchangeRateUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockExchangeRateUseCaseMockRecorder
+}
+
+// MockExchangeRateUseCaseMockRecorder is the mock recorder for MockExchangeRateUseCase.
+type MockExchangeRateUseCaseMockRecorder struct {
+	mock *MockExchangeRateUseCase
+}
+
+// NewMockExchangeRateUseCase creates a new mock instance.
+func NewMockExchangeRateUseCase(ctrl *gomock.Controller) *MockExchangeRateUseCase {
+	mock := &MockExchangeRateUseCase{ctrl: ctrl}
+	mock.recorder = &MockExchangeRateUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExchangeRateUseCase) EXPECT() *MockExchangeRateUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Convert mocks base method.
+func (m_2 *MockExchangeRateUseCase) Convert(ctx context.Context, m entity.Money, to, at string) (entity.Money, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "Convert", ctx, m, to, at)
+	ret0, _ := ret[0].(entity.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Convert indicates an expected call of Convert.
+func (mr *MockExchangeRateUseCaseMockRecorder) Convert(ctx, m, to, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Convert", reflect.TypeOf((*MockExchangeRateUseCase)(nil).Convert), ctx, m, to, at)
+}
+
+// ConvertProducts mocks base method.
+func (m *MockExchangeRateUseCase) ConvertProducts(ctx context.Context, products []*entity.Product, to, at string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConvertProducts", ctx, products, to, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConvertProducts indicates an expected call of ConvertProducts.
+func (mr *MockExchangeRateUseCaseMockRecorder) ConvertProducts(ctx, products, to, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConvertProducts", reflect.TypeOf((*MockExchangeRateUseCase)(nil).ConvertProducts), ctx, products, to, at)
+}
+
+// ListRates mocks base method.
+func (m *MockExchangeRateUseCase) ListRates(ctx context.Context, base, quote string) ([]*entity.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRates", ctx, base, quote)
+	ret0, _ := ret[0].([]*entity.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRates indicates an expected call of ListRates.
+func (mr *MockExchangeRateUseCaseMockRecorder) ListRates(ctx, base, quote interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRates", reflect.TypeOf((*MockExchangeRateUseCase)(nil).ListRates), ctx, base, quote)
+}
+
+// SyncRates mocks base method.
+func (m *MockExchangeRateUseCase) SyncRates(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncRates", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncRates indicates an expected call of SyncRates.
+func (mr *MockExchangeRateUseCaseMockRecorder) SyncRates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncRates", reflect.TypeOf((*MockExchangeRateUseCase)(nil).SyncRates), ctx)
+}
@@ -0,0 +1,156 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/usecase/repository/inventory.go
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/dariuszdroba/go-from-template/internal/entity"
+	repository "github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockInventoryRepository is a mock of InventoryRepository interface.
+type MockInventoryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockInventoryRepositoryMockRecorder
+}
+
+// MockInventoryRepositoryMockRecorder is the mock recorder for MockInventoryRepository.
+type MockInventoryRepositoryMockRecorder struct {
+	mock *MockInventoryRepository
+}
+
+// NewMockInventoryRepository creates a new mock instance.
+func NewMockInventoryRepository(ctrl *gomock.Controller) *MockInventoryRepository {
+	mock := &MockInventoryRepository{ctrl: ctrl}
+	mock.recorder = &MockInventoryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInventoryRepository) EXPECT() *MockInventoryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetLevel mocks base method.
+func (m *MockInventoryRepository) GetLevel(ctx context.Context, productID uint64) (*entity.StockLevel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLevel", ctx, productID)
+	ret0, _ := ret[0].(*entity.StockLevel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLevel indicates an expected call of GetLevel.
+func (mr *MockInventoryRepositoryMockRecorder) GetLevel(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLevel", reflect.TypeOf((*MockInventoryRepository)(nil).GetLevel), ctx, productID)
+}
+
+// GetMovement mocks base method.
+func (m *MockInventoryRepository) GetMovement(ctx context.Context, productID uint64, reference, kind string) (*entity.StockMovement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMovement", ctx, productID, reference, kind)
+	ret0, _ := ret[0].(*entity.StockMovement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMovement indicates an expected call of GetMovement.
+func (mr *MockInventoryRepositoryMockRecorder) GetMovement(ctx, productID, reference, kind interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMovement", reflect.TypeOf((*MockInventoryRepository)(nil).GetMovement), ctx, productID, reference, kind)
+}
+
+// InTx mocks base method.
+func (m *MockInventoryRepository) InTx(ctx context.Context, fn func(repository.InventoryRepository) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InTx indicates an expected call of InTx.
+func (mr *MockInventoryRepositoryMockRecorder) InTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InTx", reflect.TypeOf((*MockInventoryRepository)(nil).InTx), ctx, fn)
+}
+
+// ListMovements mocks base method.
+func (m *MockInventoryRepository) ListMovements(ctx context.Context, productID uint64) ([]*entity.StockMovement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMovements", ctx, productID)
+	ret0, _ := ret[0].([]*entity.StockMovement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMovements indicates an expected call of ListMovements.
+func (mr *MockInventoryRepositoryMockRecorder) ListMovements(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMovements", reflect.TypeOf((*MockInventoryRepository)(nil).ListMovements), ctx, productID)
+}
+
+// LockLevel mocks base method.
+func (m *MockInventoryRepository) LockLevel(ctx context.Context, productID uint64) (*entity.StockLevel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLevel", ctx, productID)
+	ret0, _ := ret[0].(*entity.StockLevel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockLevel indicates an expected call of LockLevel.
+func (mr *MockInventoryRepositoryMockRecorder) LockLevel(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLevel", reflect.TypeOf((*MockInventoryRepository)(nil).LockLevel), ctx, productID)
+}
+
+// LowStock mocks base method.
+func (m *MockInventoryRepository) LowStock(ctx context.Context, threshold int64, limit uint64) ([]*entity.StockLevel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LowStock", ctx, threshold, limit)
+	ret0, _ := ret[0].([]*entity.StockLevel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LowStock indicates an expected call of LowStock.
+func (mr *MockInventoryRepositoryMockRecorder) LowStock(ctx, threshold, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LowStock", reflect.TypeOf((*MockInventoryRepository)(nil).LowStock), ctx, threshold, limit)
+}
+
+// Record mocks base method.
+func (m_2 *MockInventoryRepository) Record(ctx context.Context, m *entity.StockMovement, level *entity.StockLevel) (uint64, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "Record", ctx, m, level)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockInventoryRepositoryMockRecorder) Record(ctx, m, level interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockInventoryRepository)(nil).Record), ctx, m, level)
+}
+
+// Reserved mocks base method.
+func (m *MockInventoryRepository) Reserved(ctx context.Context, productID uint64, reference string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserved", ctx, productID, reference)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reserved indicates an expected call of Reserved.
+func (mr *MockInventoryRepositoryMockRecorder) Reserved(ctx, productID, reference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserved", reflect.TypeOf((*MockInventoryRepository)(nil).Reserved), ctx, productID, reference)
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/usecase/product_event.go
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockEventPublisher is a mock of EventPublisher interface.
+type MockEventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventPublisherMockRecorder
+}
+
+// MockEventPublisherMockRecorder is the mock recorder for MockEventPublisher.
+type MockEventPublisherMockRecorder struct {
+	mock *MockEventPublisher
+}
+
+// NewMockEventPublisher creates a new mock instance.
+func NewMockEventPublisher(ctrl *gomock.Controller) *MockEventPublisher {
+	mock := &MockEventPublisher{ctrl: ctrl}
+	mock.recorder = &MockEventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventPublisher) EXPECT() *MockEventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockEventPublisher) Publish(ctx context.Context, routingKey, messageID string, body []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, routingKey, messageID, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockEventPublisherMockRecorder) Publish(ctx, routingKey, messageID, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockEventPublisher)(nil).Publish), ctx, routingKey, messageID, body)
+}
+
+// MockProductEventUseCase is a mock of ProductEventUseCase interface.
+type MockProductEventUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductEventUseCaseMockRecorder
+}
+
+// MockProductEventUseCaseMockRecorder is the mock recorder for MockProductEventUseCase.
+type MockProductEventUseCaseMockRecorder struct {
+	mock *MockProductEventUseCase
+}
+
+// NewMockProductEventUseCase creates a new mock instance.
+func NewMockProductEventUseCase(ctrl *gomock.Controller) *MockProductEventUseCase {
+	mock := &MockProductEventUseCase{ctrl: ctrl}
+	mock.recorder = &MockProductEventUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductEventUseCase) EXPECT() *MockProductEventUseCaseMockRecorder {
+	return m.recorder
+}
+
+// PublishPending mocks base method.
+func (m *MockProductEventUseCase) PublishPending(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishPending", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishPending indicates an expected call of PublishPending.
+func (mr *MockProductEventUseCaseMockRecorder) PublishPending(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishPending", reflect.TypeOf((*MockProductEventUseCase)(nil).PublishPending), ctx)
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/usecase/repository/product_event.go
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/dariuszdroba/go-from-template/internal/entity"
+	repository "github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockProductEventRepository is a mock of ProductEventRepository interface.
+type MockProductEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductEventRepositoryMockRecorder
+}
+
+// MockProductEventRepositoryMockRecorder is the mock recorder for MockProductEventRepository.
+type MockProductEventRepositoryMockRecorder struct {
+	mock *MockProductEventRepository
+}
+
+// NewMockProductEventRepository creates a new mock instance.
+func NewMockProductEventRepository(ctrl *gomock.Controller) *MockProductEventRepository {
+	mock := &MockProductEventRepository{ctrl: ctrl}
+	mock.recorder = &MockProductEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductEventRepository) EXPECT() *MockProductEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockProductEventRepository) Delete(ctx context.Context, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockProductEventRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProductEventRepository)(nil).Delete), ctx, id)
+}
+
+// InTx mocks base method.
+func (m *MockProductEventRepository) InTx(ctx context.Context, fn func(repository.ProductEventRepository) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InTx indicates an expected call of InTx.
+func (mr *MockProductEventRepositoryMockRecorder) InTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InTx", reflect.TypeOf((*MockProductEventRepository)(nil).InTx), ctx, fn)
+}
+
+// Pending mocks base method.
+func (m *MockProductEventRepository) Pending(ctx context.Context, limit uint64) ([]*entity.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pending", ctx, limit)
+	ret0, _ := ret[0].([]*entity.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pending indicates an expected call of Pending.
+func (mr *MockProductEventRepositoryMockRecorder) Pending(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*MockProductEventRepository)(nil).Pending), ctx, limit)
+}
@@ -0,0 +1,531 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/usecase/repository/product.go
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	sql "database/sql"
+	reflect "reflect"
+	time "time"
+
+	entity "github.com/dariuszdroba/go-from-template/internal/entity"
+	repository "github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockProductRepository is a mock of ProductRepository interface.
+type MockProductRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductRepositoryMockRecorder
+}
+
+// MockProductRepositoryMockRecorder is the mock recorder for MockProductRepository.
+type MockProductRepositoryMockRecorder struct {
+	mock *MockProductRepository
+}
+
+// NewMockProductRepository creates a new mock instance.
+func NewMockProductRepository(ctrl *gomock.Controller) *MockProductRepository {
+	mock := &MockProductRepository{ctrl: ctrl}
+	mock.recorder = &MockProductRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductRepository) EXPECT() *MockProductRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockProductRepository) Create(ctx context.Context, p *entity.Product) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, p)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockProductRepositoryMockRecorder) Create(ctx, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProductRepository)(nil).Create), ctx, p)
+}
+
+// CreateScheduledChange mocks base method.
+func (m *MockProductRepository) CreateScheduledChange(ctx context.Context, c *entity.ScheduledChange) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledChange", ctx, c)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledChange indicates an expected call of CreateScheduledChange.
+func (mr *MockProductRepositoryMockRecorder) CreateScheduledChange(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledChange", reflect.TypeOf((*MockProductRepository)(nil).CreateScheduledChange), ctx, c)
+}
+
+// Delete mocks base method.
+func (m *MockProductRepository) Delete(ctx context.Context, id, version uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockProductRepositoryMockRecorder) Delete(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProductRepository)(nil).Delete), ctx, id, version)
+}
+
+// DueScheduledChanges mocks base method.
+func (m *MockProductRepository) DueScheduledChanges(ctx context.Context, limit uint64) ([]*entity.ScheduledChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DueScheduledChanges", ctx, limit)
+	ret0, _ := ret[0].([]*entity.ScheduledChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DueScheduledChanges indicates an expected call of DueScheduledChanges.
+func (mr *MockProductRepositoryMockRecorder) DueScheduledChanges(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DueScheduledChanges", reflect.TypeOf((*MockProductRepository)(nil).DueScheduledChanges), ctx, limit)
+}
+
+// ExportHistory mocks base method.
+func (m *MockProductRepository) ExportHistory(ctx context.Context, f *entity.ExportFilter, fn func(*entity.ProductHistory) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportHistory", ctx, f, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportHistory indicates an expected call of ExportHistory.
+func (mr *MockProductRepositoryMockRecorder) ExportHistory(ctx, f, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportHistory", reflect.TypeOf((*MockProductRepository)(nil).ExportHistory), ctx, f, fn)
+}
+
+// ExportProducts mocks base method.
+func (m *MockProductRepository) ExportProducts(ctx context.Context, f *entity.ExportFilter, fn func(*entity.Product) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportProducts", ctx, f, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportProducts indicates an expected call of ExportProducts.
+func (mr *MockProductRepositoryMockRecorder) ExportProducts(ctx, f, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportProducts", reflect.TypeOf((*MockProductRepository)(nil).ExportProducts), ctx, f, fn)
+}
+
+// GetByDate mocks base method.
+func (m *MockProductRepository) GetByDate(ctx context.Context, id uint64, rd *entity.ReferenceDate) (*entity.ProductHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByDate", ctx, id, rd)
+	ret0, _ := ret[0].(*entity.ProductHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByDate indicates an expected call of GetByDate.
+func (mr *MockProductRepositoryMockRecorder) GetByDate(ctx, id, rd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByDate", reflect.TypeOf((*MockProductRepository)(nil).GetByDate), ctx, id, rd)
+}
+
+// GetByID mocks base method.
+func (m *MockProductRepository) GetByID(ctx context.Context, id uint64, includeDeleted bool) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id, includeDeleted)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockProductRepositoryMockRecorder) GetByID(ctx, id, includeDeleted interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockProductRepository)(nil).GetByID), ctx, id, includeDeleted)
+}
+
+// GetBySKU mocks base method.
+func (m *MockProductRepository) GetBySKU(ctx context.Context, sku string) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBySKU", ctx, sku)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBySKU indicates an expected call of GetBySKU.
+func (mr *MockProductRepositoryMockRecorder) GetBySKU(ctx, sku interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBySKU", reflect.TypeOf((*MockProductRepository)(nil).GetBySKU), ctx, sku)
+}
+
+// GetHighestPrice mocks base method.
+func (m *MockProductRepository) GetHighestPrice(ctx context.Context, id uint64) (*entity.ProductMaxValue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHighestPrice", ctx, id)
+	ret0, _ := ret[0].(*entity.ProductMaxValue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHighestPrice indicates an expected call of GetHighestPrice.
+func (mr *MockProductRepositoryMockRecorder) GetHighestPrice(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHighestPrice", reflect.TypeOf((*MockProductRepository)(nil).GetHighestPrice), ctx, id)
+}
+
+// GetPriceIntervals mocks base method.
+func (m *MockProductRepository) GetPriceIntervals(ctx context.Context, ids []uint64, from, to time.Time) ([]*entity.PriceInterval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceIntervals", ctx, ids, from, to)
+	ret0, _ := ret[0].([]*entity.PriceInterval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPriceIntervals indicates an expected call of GetPriceIntervals.
+func (mr *MockProductRepositoryMockRecorder) GetPriceIntervals(ctx, ids, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceIntervals", reflect.TypeOf((*MockProductRepository)(nil).GetPriceIntervals), ctx, ids, from, to)
+}
+
+// GetProductHistory mocks base method.
+func (m *MockProductRepository) GetProductHistory(ctx context.Context, id uint64) (*entity.Product, []*entity.ProductHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductHistory", ctx, id)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].([]*entity.ProductHistory)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetProductHistory indicates an expected call of GetProductHistory.
+func (mr *MockProductRepositoryMockRecorder) GetProductHistory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductHistory", reflect.TypeOf((*MockProductRepository)(nil).GetProductHistory), ctx, id)
+}
+
+// GetScheduledChange mocks base method.
+func (m *MockProductRepository) GetScheduledChange(ctx context.Context, id uint64) (*entity.ScheduledChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledChange", ctx, id)
+	ret0, _ := ret[0].(*entity.ScheduledChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledChange indicates an expected call of GetScheduledChange.
+func (mr *MockProductRepositoryMockRecorder) GetScheduledChange(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledChange", reflect.TypeOf((*MockProductRepository)(nil).GetScheduledChange), ctx, id)
+}
+
+// GetTimeDiff mocks base method.
+func (m *MockProductRepository) GetTimeDiff(ctx context.Context, id uint64) ([]*entity.TimeDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeDiff", ctx, id)
+	ret0, _ := ret[0].([]*entity.TimeDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeDiff indicates an expected call of GetTimeDiff.
+func (mr *MockProductRepositoryMockRecorder) GetTimeDiff(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeDiff", reflect.TypeOf((*MockProductRepository)(nil).GetTimeDiff), ctx, id)
+}
+
+// InTx mocks base method.
+func (m *MockProductRepository) InTx(ctx context.Context, fn func(repository.ProductRepository) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InTx indicates an expected call of InTx.
+func (mr *MockProductRepositoryMockRecorder) InTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InTx", reflect.TypeOf((*MockProductRepository)(nil).InTx), ctx, fn)
+}
+
+// List mocks base method.
+func (m *MockProductRepository) List(ctx context.Context, f *entity.ProductFilter) ([]*entity.Product, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, f)
+	ret0, _ := ret[0].([]*entity.Product)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockProductRepositoryMockRecorder) List(ctx, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockProductRepository)(nil).List), ctx, f)
+}
+
+// ListScheduledChanges mocks base method.
+func (m *MockProductRepository) ListScheduledChanges(ctx context.Context, productID uint64, status string) ([]*entity.ScheduledChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledChanges", ctx, productID, status)
+	ret0, _ := ret[0].([]*entity.ScheduledChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledChanges indicates an expected call of ListScheduledChanges.
+func (mr *MockProductRepositoryMockRecorder) ListScheduledChanges(ctx, productID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledChanges", reflect.TypeOf((*MockProductRepository)(nil).ListScheduledChanges), ctx, productID, status)
+}
+
+// Restore mocks base method.
+func (m *MockProductRepository) Restore(ctx context.Context, id, version uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockProductRepositoryMockRecorder) Restore(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockProductRepository)(nil).Restore), ctx, id, version)
+}
+
+// Search mocks base method.
+func (m *MockProductRepository) Search(ctx context.Context, s *entity.ProductSearch) ([]*entity.ProductSearchHit, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, s)
+	ret0, _ := ret[0].([]*entity.ProductSearchHit)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockProductRepositoryMockRecorder) Search(ctx, s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockProductRepository)(nil).Search), ctx, s)
+}
+
+// SearchWords mocks base method.
+func (m *MockProductRepository) SearchWords(ctx context.Context, term string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchWords", ctx, term)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchWords indicates an expected call of SearchWords.
+func (mr *MockProductRepositoryMockRecorder) SearchWords(ctx, term interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchWords", reflect.TypeOf((*MockProductRepository)(nil).SearchWords), ctx, term)
+}
+
+// TransitionScheduledChange mocks base method.
+func (m *MockProductRepository) TransitionScheduledChange(ctx context.Context, id uint64, from, to string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitionScheduledChange", ctx, id, from, to)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransitionScheduledChange indicates an expected call of TransitionScheduledChange.
+func (mr *MockProductRepositoryMockRecorder) TransitionScheduledChange(ctx, id, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionScheduledChange", reflect.TypeOf((*MockProductRepository)(nil).TransitionScheduledChange), ctx, id, from, to)
+}
+
+// Update mocks base method.
+func (m *MockProductRepository) Update(ctx context.Context, p *entity.Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, p)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockProductRepositoryMockRecorder) Update(ctx, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProductRepository)(nil).Update), ctx, p)
+}
+
+// MockmysqlConn is a mock of mysqlConn interface.
+type MockmysqlConn struct {
+	ctrl     *gomock.Controller
+	recorder *MockmysqlConnMockRecorder
+}
+
+// MockmysqlConnMockRecorder is the mock recorder for MockmysqlConn.
+type MockmysqlConnMockRecorder struct {
+	mock *MockmysqlConn
+}
+
+// NewMockmysqlConn creates a new mock instance.
+func NewMockmysqlConn(ctrl *gomock.Controller) *MockmysqlConn {
+	mock := &MockmysqlConn{ctrl: ctrl}
+	mock.recorder = &MockmysqlConnMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockmysqlConn) EXPECT() *MockmysqlConnMockRecorder {
+	return m.recorder
+}
+
+// ExecContext mocks base method.
+func (m *MockmysqlConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecContext", varargs...)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecContext indicates an expected call of ExecContext.
+func (mr *MockmysqlConnMockRecorder) ExecContext(ctx, query interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecContext", reflect.TypeOf((*MockmysqlConn)(nil).ExecContext), varargs...)
+}
+
+// QueryContext mocks base method.
+func (m *MockmysqlConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryContext", varargs...)
+	ret0, _ := ret[0].(*sql.Rows)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryContext indicates an expected call of QueryContext.
+func (mr *MockmysqlConnMockRecorder) QueryContext(ctx, query interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryContext", reflect.TypeOf((*MockmysqlConn)(nil).QueryContext), varargs...)
+}
+
+// QueryRowContext mocks base method.
+func (m *MockmysqlConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryRowContext", varargs...)
+	ret0, _ := ret[0].(*sql.Row)
+	return ret0
+}
+
+// QueryRowContext indicates an expected call of QueryRowContext.
+func (mr *MockmysqlConnMockRecorder) QueryRowContext(ctx, query interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryRowContext", reflect.TypeOf((*MockmysqlConn)(nil).QueryRowContext), varargs...)
+}
+
+// MockrowScanner is a mock of rowScanner interface.
+type MockrowScanner struct {
+	ctrl     *gomock.Controller
+	recorder *MockrowScannerMockRecorder
+}
+
+// MockrowScannerMockRecorder is the mock recorder for MockrowScanner.
+type MockrowScannerMockRecorder struct {
+	mock *MockrowScanner
+}
+
+// NewMockrowScanner creates a new mock instance.
+func NewMockrowScanner(ctrl *gomock.Controller) *MockrowScanner {
+	mock := &MockrowScanner{ctrl: ctrl}
+	mock.recorder = &MockrowScannerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrowScanner) EXPECT() *MockrowScannerMockRecorder {
+	return m.recorder
+}
+
+// Scan mocks base method.
+func (m *MockrowScanner) Scan(dest ...interface{}) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range dest {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Scan", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockrowScannerMockRecorder) Scan(dest ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockrowScanner)(nil).Scan), dest...)
+}
+
+// MockrowQueryer is a mock of rowQueryer interface.
+type MockrowQueryer struct {
+	ctrl     *gomock.Controller
+	recorder *MockrowQueryerMockRecorder
+}
+
+// MockrowQueryerMockRecorder is the mock recorder for MockrowQueryer.
+type MockrowQueryerMockRecorder struct {
+	mock *MockrowQueryer
+}
+
+// NewMockrowQueryer creates a new mock instance.
+func NewMockrowQueryer(ctrl *gomock.Controller) *MockrowQueryer {
+	mock := &MockrowQueryer{ctrl: ctrl}
+	mock.recorder = &MockrowQueryerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockrowQueryer) EXPECT() *MockrowQueryerMockRecorder {
+	return m.recorder
+}
+
+// QueryRowContext mocks base method.
+func (m *MockrowQueryer) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "QueryRowContext", varargs...)
+	ret0, _ := ret[0].(*sql.Row)
+	return ret0
+}
+
+// QueryRowContext indicates an expected call of QueryRowContext.
+func (mr *MockrowQueryerMockRecorder) QueryRowContext(ctx, query interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryRowContext", reflect.TypeOf((*MockrowQueryer)(nil).QueryRowContext), varargs...)
+}
@@ -0,0 +1,155 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/usecase/repository/product_translation.go
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	entity "github.com/dariuszdroba/go-from-template/internal/entity"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockProductTranslationRepository is a mock of ProductTranslationRepository interface.
+type MockProductTranslationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductTranslationRepositoryMockRecorder
+}
+
+// MockProductTranslationRepositoryMockRecorder is the mock recorder for MockProductTranslationRepository.
+type MockProductTranslationRepositoryMockRecorder struct {
+	mock *MockProductTranslationRepository
+}
+
+// NewMockProductTranslationRepository creates a new mock instance.
+func NewMockProductTranslationRepository(ctrl *gomock.Controller) *MockProductTranslationRepository {
+	mock := &MockProductTranslationRepository{ctrl: ctrl}
+	mock.recorder = &MockProductTranslationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductTranslationRepository) EXPECT() *MockProductTranslationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClearFailures mocks base method.
+func (m *MockProductTranslationRepository) ClearFailures(ctx context.Context, productID uint64, locale string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearFailures", ctx, productID, locale)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearFailures indicates an expected call of ClearFailures.
+func (mr *MockProductTranslationRepositoryMockRecorder) ClearFailures(ctx, productID, locale interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearFailures", reflect.TypeOf((*MockProductTranslationRepository)(nil).ClearFailures), ctx, productID, locale)
+}
+
+// Delete mocks base method.
+func (m *MockProductTranslationRepository) Delete(ctx context.Context, productID uint64, locale string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, productID, locale)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockProductTranslationRepositoryMockRecorder) Delete(ctx, productID, locale interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProductTranslationRepository)(nil).Delete), ctx, productID, locale)
+}
+
+// Get mocks base method.
+func (m *MockProductTranslationRepository) Get(ctx context.Context, productID uint64, locale string) (*entity.ProductTranslation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, productID, locale)
+	ret0, _ := ret[0].(*entity.ProductTranslation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockProductTranslationRepositoryMockRecorder) Get(ctx, productID, locale interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockProductTranslationRepository)(nil).Get), ctx, productID, locale)
+}
+
+// ListByProduct mocks base method.
+func (m *MockProductTranslationRepository) ListByProduct(ctx context.Context, productID uint64) ([]*entity.ProductTranslation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByProduct", ctx, productID)
+	ret0, _ := ret[0].([]*entity.ProductTranslation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByProduct indicates an expected call of ListByProduct.
+func (mr *MockProductTranslationRepositoryMockRecorder) ListByProduct(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByProduct", reflect.TypeOf((*MockProductTranslationRepository)(nil).ListByProduct), ctx, productID)
+}
+
+// ListForProducts mocks base method.
+func (m *MockProductTranslationRepository) ListForProducts(ctx context.Context, productIDs []uint64, locales []string) ([]*entity.ProductTranslation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListForProducts", ctx, productIDs, locales)
+	ret0, _ := ret[0].([]*entity.ProductTranslation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListForProducts indicates an expected call of ListForProducts.
+func (mr *MockProductTranslationRepositoryMockRecorder) ListForProducts(ctx, productIDs, locales interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListForProducts", reflect.TypeOf((*MockProductTranslationRepository)(nil).ListForProducts), ctx, productIDs, locales)
+}
+
+// Missing mocks base method.
+func (m *MockProductTranslationRepository) Missing(ctx context.Context, locales []string, limit uint64) ([]*entity.ProductLocale, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Missing", ctx, locales, limit)
+	ret0, _ := ret[0].([]*entity.ProductLocale)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Missing indicates an expected call of Missing.
+func (mr *MockProductTranslationRepositoryMockRecorder) Missing(ctx, locales, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Missing", reflect.TypeOf((*MockProductTranslationRepository)(nil).Missing), ctx, locales, limit)
+}
+
+// RecordFailure mocks base method.
+func (m *MockProductTranslationRepository) RecordFailure(ctx context.Context, productID uint64, locale string, retryAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailure", ctx, productID, locale, retryAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordFailure indicates an expected call of RecordFailure.
+func (mr *MockProductTranslationRepositoryMockRecorder) RecordFailure(ctx, productID, locale, retryAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailure", reflect.TypeOf((*MockProductTranslationRepository)(nil).RecordFailure), ctx, productID, locale, retryAt)
+}
+
+// Save mocks base method.
+func (m *MockProductTranslationRepository) Save(ctx context.Context, t *entity.ProductTranslation) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, t)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockProductTranslationRepositoryMockRecorder) Save(ctx, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockProductTranslationRepository)(nil).Save), ctx, t)
+}
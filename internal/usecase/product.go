@@ -2,20 +2,59 @@ package usecase
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"github.com/dariuszdroba/go-from-template/internal/entity"
 	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+	"strconv"
+	"time"
 )
 
+const (
+	_defaultListLimit = 20
+	_maxListLimit     = 100
+)
+
+// ProductUseCase -. Every write records the entity.Change carried by its ctx with the version it creates.
 type ProductUseCase interface {
 	Create(ctx context.Context, p *entity.Product) (uint64, error)
-	GetByID(ctx context.Context, id uint64) (*entity.Product, error)
+	GetByID(ctx context.Context, id uint64, includeDeleted bool) (*entity.Product, error)
+	// Update stores p as the next version of its product; without a sku the stored one is kept.
 	Update(ctx context.Context, p *entity.Product) error
-	Delete(ctx context.Context, id uint64) error
-	List(ctx context.Context) ([]*entity.Product, error)
+	Delete(ctx context.Context, id, version uint64) error
+	Restore(ctx context.Context, id, version uint64) error
+	List(ctx context.Context, f *entity.ProductFilter) (*entity.ProductPage, error)
+	// Search finds the products whose name or description match the words of query, most relevant
+	// first. f filters and pages them like List; its ordering is ignored.
+	Search(ctx context.Context, query string, f *entity.ProductFilter) (*entity.ProductSearchPage, error)
 	GetProductHistory(ctx context.Context, id uint64) (*entity.Product, []*entity.ProductHistory, error)
 	GetHighestPrice(ctx context.Context, id uint64) (*entity.ProductMaxValue, error)
 	GetTimeDiff(ctx context.Context, id uint64) ([]*entity.TimeDiff, error)
 	GetByDate(ctx context.Context, id uint64, rd *entity.ReferenceDate) (*entity.ProductHistory, error)
+	// GetPriceStats summarises the prices of a product during [from, to). A zero from starts at its
+	// first version and a zero to means now.
+	GetPriceStats(ctx context.Context, id uint64, from, to time.Time) (*entity.PriceStats, error)
+	GetPriceSeries(ctx context.Context, q *entity.PriceSeriesQuery) ([]*entity.PriceSeries, error)
+	Diff(ctx context.Context, id uint64, from, to entity.VersionRef) (*entity.ProductDiff, error)
+	Changelog(ctx context.Context, id uint64) ([]*entity.ChangelogEntry, error)
+	// Revert stores the sku, name, description and price of the version to names as the next version
+	// of a product. The actor of the entity.Change in ctx is required; without a reason the version
+	// reverted to is recorded as the reason. A non-zero version is checked like in Update.
+	Revert(ctx context.Context, id uint64, to entity.VersionRef, version uint64) (*entity.Product, error)
+	// ExportProducts and ExportHistory stream the rows f selects to fn, ordered by product, without
+	// holding them in memory; an error of fn stops the export and is returned.
+	ExportProducts(ctx context.Context, f *entity.ExportFilter, fn func(p *entity.Product) error) error
+	ExportHistory(ctx context.Context, f *entity.ExportFilter, fn func(h *entity.ProductHistory) error) error
+
+	ScheduleChange(ctx context.Context, c *entity.ScheduledChange) (*entity.ScheduledChange, error)
+	ListScheduledChanges(ctx context.Context, productID uint64, status string) ([]*entity.ScheduledChange, error)
+	CancelScheduledChange(ctx context.Context, productID, changeID uint64) error
+	// ApplyDueChanges applies every pending change whose time has come and returns how many were applied.
+	// The changes that failed stay pending and their errors are joined.
+	ApplyDueChanges(ctx context.Context) (int, error)
+
+	// Import upserts products by sku from r; see ImportReport for how failures are handled.
+	Import(ctx context.Context, r ImportReader, opts ImportOptions) (*ImportReport, error)
 }
 
 type productUseCase struct {
@@ -26,31 +65,216 @@ func NewProductUseCase(r repository.ProductRepository) ProductUseCase {
 	return &productUseCase{repo: r}
 }
 
+// withRepo returns a copy of uc that works through repo, e.g. the repository of a transaction.
+func (uc *productUseCase) withRepo(repo repository.ProductRepository) *productUseCase {
+	tx := *uc
+	tx.repo = repo
+	return &tx
+}
+
 func (uc *productUseCase) Create(ctx context.Context, p *entity.Product) (uint64, error) {
-	return uc.repo.Create(ctx, p)
+	if err := validateChange(ctx); err != nil {
+		return 0, err
+	}
+	if err := validateProduct(p, true); err != nil {
+		return 0, err
+	}
+	id, err := uc.repo.Create(ctx, p)
+	return id, productRepoError(err)
 }
 
-func (uc *productUseCase) GetByID(ctx context.Context, id uint64) (*entity.Product, error) {
-	return uc.repo.GetByID(ctx, id)
+func (uc *productUseCase) GetByID(ctx context.Context, id uint64, includeDeleted bool) (*entity.Product, error) {
+	p, err := uc.repo.GetByID(ctx, id, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrProductNotFound
+	}
+	return p, nil
 }
 func (uc *productUseCase) Update(ctx context.Context, p *entity.Product) error {
-	return uc.repo.Update(ctx, p)
+	if err := validateChange(ctx); err != nil {
+		return err
+	}
+	if err := validateProduct(p, false); err != nil {
+		return err
+	}
+	if p.SKU == "" {
+		if err := uc.keepStoredSKU(ctx, p); err != nil {
+			return err
+		}
+	}
+	return productRepoError(uc.repo.Update(ctx, p))
 }
-func (uc *productUseCase) Delete(ctx context.Context, id uint64) error {
-	return uc.repo.Delete(ctx, id)
+
+// keepStoredSKU gives p, written without a sku, the one stored: once set, a sku is replaced but never cleared.
+func (uc *productUseCase) keepStoredSKU(ctx context.Context, p *entity.Product) error {
+	id, err := strconv.ParseUint(p.ID, 10, 64)
+	if err != nil {
+		return ErrProductNotFound
+	}
+	stored, err := uc.repo.GetByID(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	if stored != nil {
+		p.SKU = stored.SKU
+	}
+	return nil
+}
+func (uc *productUseCase) Delete(ctx context.Context, id, version uint64) error {
+	if err := validateChange(ctx); err != nil {
+		return err
+	}
+	return productRepoError(uc.repo.Delete(ctx, id, version))
 }
-func (uc *productUseCase) List(ctx context.Context) ([]*entity.Product, error) {
-	return uc.repo.List(ctx)
+func (uc *productUseCase) Restore(ctx context.Context, id, version uint64) error {
+	if err := validateChange(ctx); err != nil {
+		return err
+	}
+	p, err := uc.repo.GetByID(ctx, id, true)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return ErrProductNotFound
+	}
+	if p.DeletedAt == "" {
+		return ErrProductNotDeleted
+	}
+	return productRepoError(uc.repo.Restore(ctx, id, version))
+}
+func (uc *productUseCase) List(ctx context.Context, f *entity.ProductFilter) (*entity.ProductPage, error) {
+	var fe fieldErrors
+	validatePriceBounds(&fe, f)
+	if err := fe.err(); err != nil {
+		return nil, err
+	}
+
+	q := *f
+	q.Tag = normalizeTag(q.Tag)
+	if q.SortBy == "" {
+		q.SortBy = "id"
+	}
+	if q.Limit == 0 {
+		q.Limit = _defaultListLimit
+	}
+	if q.Limit > _maxListLimit {
+		q.Limit = _maxListLimit
+	}
+	if q.Cursor != "" {
+		after, err := decodeProductCursor(q.Cursor)
+		if err != nil || after.SortBy != q.SortBy {
+			return nil, ErrInvalidCursor
+		}
+		q.After = after
+		q.Offset = 0
+	}
+
+	// One extra row tells whether another page exists.
+	limit := q.Limit
+	q.Limit++
+	products, total, err := uc.repo.List(ctx, &q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.ProductPage{Products: products, Total: total}
+	if uint64(len(products)) > limit {
+		page.Products = products[:limit]
+		page.NextCursor = encodeProductCursor(q.SortBy, page.Products[limit-1])
+	}
+	if page.Products == nil {
+		page.Products = []*entity.Product{}
+	}
+	return page, nil
 }
 func (uc *productUseCase) GetProductHistory(ctx context.Context, id uint64) (*entity.Product, []*entity.ProductHistory, error) {
-	return uc.repo.GetProductHistory(ctx, id)
+	p, history, err := uc.repo.GetProductHistory(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p == nil {
+		return nil, nil, ErrProductNotFound
+	}
+	return p, history, nil
 }
 func (uc *productUseCase) GetHighestPrice(ctx context.Context, id uint64) (*entity.ProductMaxValue, error) {
-	return uc.repo.GetHighestPrice(ctx, id)
+	pMax, err := uc.repo.GetHighestPrice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pMax == nil {
+		return nil, ErrProductNotFound
+	}
+	return pMax, nil
 }
+
+// GetTimeDiff -. Every product has at least one version, so an empty result means it does not exist.
 func (uc *productUseCase) GetTimeDiff(ctx context.Context, id uint64) ([]*entity.TimeDiff, error) {
-	return uc.repo.GetTimeDiff(ctx, id)
+	tDiffs, err := uc.repo.GetTimeDiff(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(tDiffs) == 0 {
+		return nil, ErrProductNotFound
+	}
+	return tDiffs, nil
 }
 func (uc *productUseCase) GetByDate(ctx context.Context, id uint64, rd *entity.ReferenceDate) (*entity.ProductHistory, error) {
-	return uc.repo.GetByDate(ctx, id, rd)
+	ph, err := uc.repo.GetByDate(ctx, id, rd)
+	if err != nil {
+		return nil, err
+	}
+	if ph == nil {
+		return nil, ErrProductVersionNotFound
+	}
+	return ph, nil
+}
+
+func encodeProductCursor(sortBy string, last *entity.Product) string {
+	c := entity.ProductCursor{SortBy: sortBy}
+	c.ID, _ = strconv.ParseUint(last.ID, 10, 64)
+	switch sortBy {
+	case "name":
+		c.Value = last.Name
+	case "description":
+		c.Value = last.Description
+	case "price":
+		c.Value = strconv.FormatInt(last.Price.Amount, 10)
+	case "created_at":
+		c.Value = last.CreatedAt
+	case "updated_at":
+		c.Value = last.UpdatedAt
+	}
+	return encodeCursor(&c)
+}
+
+func encodeCursor(c *entity.ProductCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*entity.ProductCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	c := &entity.ProductCursor{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func decodeProductCursor(s string) (*entity.ProductCursor, error) {
+	c, err := decodeCursor(s)
+	if err != nil {
+		return nil, err
+	}
+	if !repository.IsProductSortColumn(c.SortBy) {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
 }
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+// Diff compares two versions of a product. A zero from names its first version and a zero to its
+// current one, so that the zero refs diff the product as created against the product as it is.
+func (uc *productUseCase) Diff(ctx context.Context, id uint64, from, to entity.VersionRef) (*entity.ProductDiff, error) {
+	var fe fieldErrors
+	validateVersionRef(&fe, "from", from)
+	validateVersionRef(&fe, "to", to)
+	if err := fe.err(); err != nil {
+		return nil, err
+	}
+
+	_, history, err := uc.GetProductHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, ErrProductVersionNotFound
+	}
+
+	a, b := findVersion(history, from), findVersion(history, to)
+	if from == (entity.VersionRef{}) {
+		a = history[0]
+	}
+	if to == (entity.VersionRef{}) {
+		b = history[len(history)-1]
+	}
+	if a == nil || b == nil {
+		return nil, ErrProductVersionNotFound
+	}
+	return &entity.ProductDiff{ProductID: id, From: a, To: b, Changes: diffVersions(a, b)}, nil
+}
+
+// Changelog returns every version of a product, oldest first, with what changed since the one before;
+// the first version has no changes.
+func (uc *productUseCase) Changelog(ctx context.Context, id uint64) ([]*entity.ChangelogEntry, error) {
+	_, history, err := uc.GetProductHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*entity.ChangelogEntry, 0, len(history))
+	for n, h := range history {
+		e := &entity.ChangelogEntry{ProductHistory: h, Changes: []entity.FieldChange{}}
+		if n > 0 {
+			e.Changes = diffVersions(history[n-1], h)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func validateVersionRef(fe *fieldErrors, field string, ref entity.VersionRef) {
+	if ref.At == "" {
+		return
+	}
+	if ref.HistoryID != 0 {
+		fe.add(field, "must name a version by either history id or time")
+	} else if _, err := time.Parse(entity.DateTimeLayout, ref.At); err != nil {
+		fe.add(field, "must be a history id or formatted as "+entity.DateTimeLayout)
+	}
+}
+
+// findVersion returns the version of history, which is ordered by valid_from, that ref names, or nil.
+func findVersion(history []*entity.ProductHistory, ref entity.VersionRef) *entity.ProductHistory {
+	for n := len(history) - 1; n >= 0; n-- {
+		h := history[n]
+		if ref.HistoryID != 0 && uint64(h.ID) == ref.HistoryID {
+			return h
+		}
+		// Timestamps in DateTimeLayout compare like the times they stand for.
+		if ref.At != "" && h.ValidFrom <= ref.At && (h.ValidTo == "" || h.ValidTo > ref.At) {
+			return h
+		}
+	}
+	return nil
+}
+
+// diffVersions lists the fields that differ from a to b; deleted_at tells whether the product was
+// deleted or restored.
+func diffVersions(a, b *entity.ProductHistory) []entity.FieldChange {
+	changes := []entity.FieldChange{}
+	diffString := func(field, before, after string) {
+		if before != after {
+			changes = append(changes, entity.FieldChange{Field: field, Old: before, New: after})
+		}
+	}
+	diffString("sku", a.SKU, b.SKU)
+	diffString("name", a.Name, b.Name)
+	diffString("description", a.Description, b.Description)
+	if a.Price != b.Price {
+		changes = append(changes, entity.FieldChange{Field: "price", Old: a.Price, New: b.Price})
+	}
+	diffString("deleted_at", a.DeletedAt, b.DeletedAt)
+	return changes
+}
@@ -0,0 +1,116 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+)
+
+func diffHistory() []*entity.ProductHistory {
+	return []*entity.ProductHistory{
+		{ID: 10, ProductID: 1, Name: "n", Price: *eur(100), ValidFrom: "2020-01-01 00:00:00", ValidTo: "2020-01-02 00:00:00", Version: 1},
+		{ID: 11, ProductID: 1, Name: "n", Description: "d", Price: *eur(150), ValidFrom: "2020-01-02 00:00:00", ValidTo: "2020-01-03 00:00:00", Version: 2},
+		{ID: 12, ProductID: 1, Name: "m", Description: "d", Price: *eur(150), ValidFrom: "2020-01-03 00:00:00", DeletedAt: "2020-01-03 00:00:00", Version: 3},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		from, to entity.VersionRef
+		fromID   int
+		toID     int
+		changes  []entity.FieldChange
+	}{
+		{
+			name:   "first to current",
+			fromID: 10,
+			toID:   12,
+			changes: []entity.FieldChange{
+				{Field: "name", Old: "n", New: "m"},
+				{Field: "description", Old: "", New: "d"},
+				{Field: "price", Old: *eur(100), New: *eur(150)},
+				{Field: "deleted_at", Old: "", New: "2020-01-03 00:00:00"},
+			},
+		},
+		{
+			name:   "history ids",
+			from:   entity.VersionRef{HistoryID: 11},
+			to:     entity.VersionRef{HistoryID: 10},
+			fromID: 11,
+			toID:   10,
+			changes: []entity.FieldChange{
+				{Field: "description", Old: "d", New: ""},
+				{Field: "price", Old: *eur(150), New: *eur(100)},
+			},
+		},
+		{
+			name:    "times",
+			from:    entity.VersionRef{At: "2020-01-02 00:00:00"},
+			to:      entity.VersionRef{At: "2020-01-02 23:59:59"},
+			fromID:  11,
+			toID:    11,
+			changes: []entity.FieldChange{},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc, repo := product(t)
+			repo.EXPECT().GetProductHistory(context.Background(), uint64(1)).Return(&entity.Product{ID: "1"}, diffHistory(), nil)
+
+			diff, err := uc.Diff(context.Background(), 1, tc.from, tc.to)
+			require.NoError(t, err)
+			require.Equal(t, tc.fromID, diff.From.ID)
+			require.Equal(t, tc.toID, diff.To.ID)
+			require.Equal(t, tc.changes, diff.Changes)
+		})
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	_, err := uc.Diff(context.Background(), 1, entity.VersionRef{At: "yesterday"}, entity.VersionRef{})
+	require.ErrorIs(t, err, usecase.ErrValidation)
+
+	repo.EXPECT().GetProductHistory(context.Background(), uint64(1)).Return(&entity.Product{ID: "1"}, diffHistory(), nil)
+	_, err = uc.Diff(context.Background(), 1, entity.VersionRef{At: "2019-12-31 00:00:00"}, entity.VersionRef{})
+	require.ErrorIs(t, err, usecase.ErrProductVersionNotFound)
+
+	repo.EXPECT().GetProductHistory(context.Background(), uint64(2)).Return(nil, nil, nil)
+	_, err = uc.Diff(context.Background(), 2, entity.VersionRef{}, entity.VersionRef{})
+	require.ErrorIs(t, err, usecase.ErrProductNotFound)
+}
+
+func TestChangelog(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+	repo.EXPECT().GetProductHistory(context.Background(), uint64(1)).Return(&entity.Product{ID: "1"}, diffHistory(), nil)
+
+	changelog, err := uc.Changelog(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, changelog, 3)
+	require.Empty(t, changelog[0].Changes)
+	require.Equal(t, []entity.FieldChange{
+		{Field: "description", Old: "", New: "d"},
+		{Field: "price", Old: *eur(100), New: *eur(150)},
+	}, changelog[1].Changes)
+	require.Equal(t, []entity.FieldChange{
+		{Field: "name", Old: "n", New: "m"},
+		{Field: "deleted_at", Old: "", New: "2020-01-03 00:00:00"},
+	}, changelog[2].Changes)
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+// Kinds of product errors. Every error below matches exactly one of them with errors.Is,
+// so transports can map them without knowing each case.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrValidation = errors.New("validation failed")
+	ErrConflict   = errors.New("conflict")
+)
+
+// Error is a product error of a given Kind; Err is the underlying cause, if any.
+type Error struct {
+	Kind error
+	Msg  string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Msg + ": " + e.Err.Error()
+	}
+	return e.Msg
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+var (
+	// ErrProductNotFound is returned for unknown and, unless asked for, soft-deleted products.
+	ErrProductNotFound = &Error{Kind: ErrNotFound, Msg: "product not found"}
+	// ErrProductVersionNotFound is returned by GetByDate when the product did not exist at that time.
+	ErrProductVersionNotFound = &Error{Kind: ErrNotFound, Msg: "product has no version at that date"}
+	// ErrInvalidCursor is returned by List when the cursor is malformed or was issued for another sort column.
+	ErrInvalidCursor = &Error{Kind: ErrValidation, Msg: "invalid cursor"}
+	// ErrVersionConflict is matched by the *repository.ConflictError returned by Update and Delete.
+	ErrVersionConflict = repository.ErrVersionConflict
+	// ErrDuplicateSKU is returned by Create and Update when another product has the SKU, deleted or not.
+	ErrDuplicateSKU = &Error{Kind: ErrConflict, Msg: "sku is already used by another product"}
+	// ErrProductNotDeleted is returned when restoring a product that is not deleted.
+	ErrProductNotDeleted = &Error{Kind: ErrConflict, Msg: "product is not deleted"}
+	// ErrNoPricesInWindow is returned by GetPriceStats when the product existed but had no live version in the window.
+	ErrNoPricesInWindow = &Error{Kind: ErrNotFound, Msg: "product has no price in that window"}
+	// ErrMixedCurrencyPrices is returned by GetPriceStats when the currency changed within the window,
+	// and by GetPriceSeries when it changed within a bucket averaged over time.
+	ErrMixedCurrencyPrices = &Error{Kind: ErrValidation, Msg: "prices in the window are in more than one currency"}
+	// ErrRevertToDeletedVersion is returned by Revert for the version that records the deletion of a product.
+	ErrRevertToDeletedVersion = &Error{Kind: ErrValidation, Msg: "cannot revert to a deleted version"}
+
+	// ErrInvalidScheduledChange is returned for changes that change nothing or are not in the future.
+	ErrInvalidScheduledChange = &Error{Kind: ErrValidation, Msg: "scheduled change must set a field and take effect in the future"}
+	// ErrScheduledChangeNotFound -.
+	ErrScheduledChangeNotFound = &Error{Kind: ErrNotFound, Msg: "scheduled change not found"}
+	// ErrScheduledChangeNotPending is returned when cancelling a change that was already applied or cancelled.
+	ErrScheduledChangeNotPending = &Error{Kind: ErrConflict, Msg: "scheduled change is not pending"}
+
+	// ErrUnsupportedImportFormat is returned by NewImportReader for formats other than csv and ndjson.
+	ErrUnsupportedImportFormat = &Error{Kind: ErrValidation, Msg: "unsupported import format"}
+	// ErrImportSKURequired is reported for import rows without a sku, as rows are matched by it.
+	ErrImportSKURequired = &ValidationError{Fields: []FieldError{{Field: "sku", Message: "is required"}}}
+	// ErrSKUOfDeletedProduct is reported for import rows whose sku belongs to a soft-deleted product.
+	ErrSKUOfDeletedProduct = &Error{Kind: ErrConflict, Msg: "sku belongs to a deleted product"}
+
+	// ErrExchangeRateNotFound is returned when a price cannot be converted because neither direction
+	// of the currency pair had a rate at that time.
+	ErrExchangeRateNotFound = &Error{Kind: ErrNotFound, Msg: "exchange rate not found"}
+
+	// ErrCategoryNotFound -.
+	ErrCategoryNotFound = &Error{Kind: ErrNotFound, Msg: "category not found"}
+	// ErrCategoryNotAssigned is returned when unassigning a category the product is not in.
+	ErrCategoryNotAssigned = &Error{Kind: ErrNotFound, Msg: "product is not in that category"}
+	// ErrCategoryHasChildren is returned when deleting a category that still has subcategories.
+	ErrCategoryHasChildren = &Error{Kind: ErrConflict, Msg: "category has subcategories"}
+	// ErrCategoryCycle is returned when moving a category below itself or one of its descendants.
+	ErrCategoryCycle = &Error{Kind: ErrValidation, Msg: "category cannot be moved below itself"}
+	// ErrTagNotFound -.
+	ErrTagNotFound = &Error{Kind: ErrNotFound, Msg: "tag not found"}
+	// ErrTagNotAssigned is returned when removing a tag the product does not have.
+	ErrTagNotAssigned = &Error{Kind: ErrNotFound, Msg: "product does not have that tag"}
+	// ErrDuplicateTag is returned when creating or renaming a tag to the name of another tag.
+	ErrDuplicateTag = &Error{Kind: ErrConflict, Msg: "tag already exists"}
+
+	// ErrVariantNotFound is returned for unknown and, unless asked for, soft-deleted variants, and for
+	// variants of another product than the one named.
+	ErrVariantNotFound = &Error{Kind: ErrNotFound, Msg: "variant not found"}
+	// ErrVariantVersionNotFound is returned by VariantUseCase.GetAt when the variant did not exist at that time.
+	ErrVariantVersionNotFound = &Error{Kind: ErrNotFound, Msg: "variant has no version at that date"}
+	// ErrDuplicateVariantSKU is returned when another variant has the SKU, deleted or not.
+	ErrDuplicateVariantSKU = &Error{Kind: ErrConflict, Msg: "sku is already used by another variant"}
+	// ErrDuplicateVariantAttributes is returned when another live variant of the product has the same attributes.
+	ErrDuplicateVariantAttributes = &Error{Kind: ErrConflict, Msg: "another variant of the product has the same attributes"}
+
+	// ErrInsufficientStock is returned when a reservation or adjustment needs more stock than is available.
+	ErrInsufficientStock = &Error{Kind: ErrConflict, Msg: "not enough stock available"}
+	// ErrReleaseExceedsReservation is returned when a release is larger than what is reserved for its reference.
+	ErrReleaseExceedsReservation = &Error{Kind: ErrConflict, Msg: "release exceeds the quantity reserved for the reference"}
+	// ErrFulfilmentExceedsReservation is returned when a fulfilment is larger than what is reserved for its reference.
+	ErrFulfilmentExceedsReservation = &Error{Kind: ErrConflict, Msg: "fulfilment exceeds the quantity reserved for the reference"}
+	// ErrStockReferenceUsed is returned when a reference already reserved, released or fulfilled a product with another quantity.
+	ErrStockReferenceUsed = &Error{Kind: ErrConflict, Msg: "the reference already recorded this movement with another quantity"}
+
+	// ErrTranslationNotFound is returned when deleting a translation the product does not have.
+	ErrTranslationNotFound = &Error{Kind: ErrNotFound, Msg: "product has no translation into that locale"}
+)
+
+// productRepoError translates the errors of a repository write into product errors.
+func productRepoError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrProductNotFound):
+		return ErrProductNotFound
+	case errors.Is(err, repository.ErrVersionConflict):
+		return &Error{Kind: ErrConflict, Msg: "product was modified", Err: err}
+	case errors.Is(err, repository.ErrDuplicateSKU):
+		return ErrDuplicateSKU
+	default:
+		return err
+	}
+}
+
+// variantRepoError translates the errors of a variant repository write into variant errors.
+func variantRepoError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrVariantNotFound):
+		return ErrVariantNotFound
+	case errors.Is(err, repository.ErrVersionConflict):
+		return &Error{Kind: ErrConflict, Msg: "variant was modified", Err: err}
+	case errors.Is(err, repository.ErrDuplicateVariantSKU):
+		return ErrDuplicateVariantSKU
+	case errors.Is(err, repository.ErrDuplicateVariantAttributes):
+		return ErrDuplicateVariantAttributes
+	default:
+		return err
+	}
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+//go:generate mockgen -source=product_event.go -destination=./mocks_product_event_publisher_test.go -package=usecase_test
+
+// _eventPublishBatch is how many events are read from the outbox at a time.
+const _eventPublishBatch = 100
+
+// EventPublisher sends events to a message broker, e.g. a RabbitMQ topic exchange.
+type EventPublisher interface {
+	// Publish returns once the broker accepted body; messageID is the same for every retry of an event.
+	Publish(ctx context.Context, routingKey, messageID string, body []byte) error
+}
+
+// ProductEventUseCase relays the events the product repositories write to their outbox with every
+// change of a product. Events are delivered at least once and, per product, in the order of the changes.
+type ProductEventUseCase interface {
+	// PublishPending publishes the events in the outbox, oldest first, and removes each once it is published.
+	// It stops at the first event that cannot be published, which is retried by the next call, and returns
+	// how many it published. Each batch is claimed in a transaction, so relays running it concurrently
+	// take turns instead of publishing the same events.
+	PublishPending(ctx context.Context) (int, error)
+}
+
+type productEventUseCase struct {
+	repo      repository.ProductEventRepository
+	publisher EventPublisher
+}
+
+// NewProductEventUseCase -. The publisher may be nil, in which case events are kept in the outbox.
+func NewProductEventUseCase(r repository.ProductEventRepository, p EventPublisher) ProductEventUseCase {
+	return &productEventUseCase{repo: r, publisher: p}
+}
+
+func (uc *productEventUseCase) PublishPending(ctx context.Context) (int, error) {
+	if uc.publisher == nil {
+		return 0, nil
+	}
+
+	var n int
+	for {
+		published, claimed, err := uc.publishBatch(ctx)
+		n += published
+		if err != nil || claimed < _eventPublishBatch {
+			return n, err
+		}
+	}
+}
+
+// publishBatch claims a batch of events and publishes them, returning how many it published and claimed.
+// The deletes of the events published before one that failed are committed all the same.
+func (uc *productEventUseCase) publishBatch(ctx context.Context) (published, claimed int, err error) {
+	var publishErr error
+	err = uc.repo.InTx(ctx, func(repo repository.ProductEventRepository) error {
+		events, err := repo.Pending(ctx, _eventPublishBatch)
+		if err != nil {
+			return fmt.Errorf("ProductEventUseCase - PublishPending - repo.Pending: %w", err)
+		}
+		claimed = len(events)
+		for _, e := range events {
+			if err := uc.publisher.Publish(ctx, e.Type, strconv.FormatUint(e.ID, 10), e.Payload); err != nil {
+				publishErr = fmt.Errorf("ProductEventUseCase - PublishPending - uc.publisher.Publish: %w", err)
+				return nil
+			}
+			// An event published again after a failed delete or commit is a duplicate its id tells apart.
+			if err := repo.Delete(ctx, e.ID); err != nil {
+				return fmt.Errorf("ProductEventUseCase - PublishPending - repo.Delete: %w", err)
+			}
+			published++
+		}
+		return nil
+	})
+	if err != nil {
+		return published, claimed, err
+	}
+	return published, claimed, publishErr
+}
@@ -0,0 +1,119 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+var errBrokerDown = errors.New("broker down")
+
+func productEvents(t *testing.T) (usecase.ProductEventUseCase, *MockProductEventRepository, *MockEventPublisher) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	repo := NewMockProductEventRepository(mockCtl)
+	publisher := NewMockEventPublisher(mockCtl)
+
+	return usecase.NewProductEventUseCase(repo, publisher), repo, publisher
+}
+
+// outboxTx makes InTx of repo run fn with repo itself.
+func outboxTx(repo *MockProductEventRepository) *gomock.Call {
+	return repo.EXPECT().InTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(repository.ProductEventRepository) error) error {
+			return fn(repo)
+		})
+}
+
+func outboxEvents(from, to uint64) []*entity.OutboxEvent {
+	events := []*entity.OutboxEvent{}
+	for id := from; id <= to; id++ {
+		events = append(events, &entity.OutboxEvent{ID: id, Type: entity.ProductUpdated, ProductID: 1, Payload: []byte(`{}`)})
+	}
+
+	return events
+}
+
+func TestPublishPendingProductEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo, publisher := productEvents(t)
+
+	// A full batch is followed by another, each claimed in a transaction of its own.
+	outboxTx(repo).Times(2)
+	gomock.InOrder(
+		repo.EXPECT().Pending(ctx, uint64(100)).Return(outboxEvents(1, 100), nil),
+		repo.EXPECT().Pending(ctx, uint64(100)).Return(outboxEvents(101, 102), nil),
+	)
+
+	var published []string
+	publisher.EXPECT().Publish(ctx, entity.ProductUpdated, gomock.Any(), []byte(`{}`)).
+		DoAndReturn(func(_ context.Context, _, id string, _ []byte) error {
+			published = append(published, id)
+			return nil
+		}).Times(102)
+	repo.EXPECT().Delete(ctx, gomock.Any()).Return(nil).Times(102)
+
+	n, err := uc.PublishPending(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 102, n)
+	require.Equal(t, "1", published[0])
+	require.Equal(t, "102", published[101])
+}
+
+func TestPublishPendingProductEventsBrokerDown(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo, publisher := productEvents(t)
+
+	// The transaction still commits the delete of the event published before the failure.
+	outboxTx(repo)
+	repo.EXPECT().Pending(ctx, gomock.Any()).Return(outboxEvents(1, 3), nil)
+	publisher.EXPECT().Publish(ctx, entity.ProductUpdated, "1", gomock.Any()).Return(nil)
+	repo.EXPECT().Delete(ctx, uint64(1)).Return(nil)
+	// The events after a failed one wait for it, keeping their order.
+	publisher.EXPECT().Publish(ctx, entity.ProductUpdated, "2", gomock.Any()).Return(errBrokerDown)
+
+	n, err := uc.PublishPending(ctx)
+	require.ErrorIs(t, err, errBrokerDown)
+	require.Equal(t, 1, n)
+}
+
+func TestPublishPendingProductEventsTxFailed(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo, publisher := productEvents(t)
+
+	// A failed delete rolls the batch back; its published event is published again by the next call.
+	outboxTx(repo)
+	repo.EXPECT().Pending(ctx, gomock.Any()).Return(outboxEvents(1, 2), nil)
+	publisher.EXPECT().Publish(ctx, entity.ProductUpdated, "1", gomock.Any()).Return(nil)
+	repo.EXPECT().Delete(ctx, uint64(1)).Return(errInternalServErr)
+
+	n, err := uc.PublishPending(ctx)
+	require.ErrorIs(t, err, errInternalServErr)
+	require.Zero(t, n)
+}
+
+func TestPublishPendingProductEventsWithoutPublisher(t *testing.T) {
+	t.Parallel()
+
+	uc := usecase.NewProductEventUseCase(nil, nil)
+
+	n, err := uc.PublishPending(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
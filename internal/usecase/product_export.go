@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+func (uc *productUseCase) ExportProducts(ctx context.Context, f *entity.ExportFilter, fn func(p *entity.Product) error) error {
+	return uc.repo.ExportProducts(ctx, utcExportFilter(f), fn)
+}
+
+// ExportHistory -. With f.AsOf, versions closed after that time are exported as still open,
+// as they were known then.
+func (uc *productUseCase) ExportHistory(ctx context.Context, f *entity.ExportFilter, fn func(h *entity.ProductHistory) error) error {
+	q := utcExportFilter(f)
+	asOf := ""
+	if !q.AsOf.IsZero() {
+		asOf = q.AsOf.Format(entity.DateTimeLayout)
+	}
+	return uc.repo.ExportHistory(ctx, q, func(h *entity.ProductHistory) error {
+		if asOf != "" && h.ValidTo > asOf {
+			h.ValidTo = ""
+		}
+		return fn(h)
+	})
+}
+
+// utcExportFilter returns f with its times in UTC, the zone timestamps are stored in.
+func utcExportFilter(f *entity.ExportFilter) *entity.ExportFilter {
+	q := *f
+	q.UpdatedSince = q.UpdatedSince.UTC()
+	q.AsOf = q.AsOf.UTC()
+	return &q
+}
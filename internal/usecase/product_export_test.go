@@ -0,0 +1,69 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+func TestExportHistoryAsOf(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	asOf := time.Date(2026, 3, 1, 13, 0, 0, 0, time.FixedZone("CET", 3600))
+	history := []*entity.ProductHistory{
+		{ID: 1, ValidFrom: "2026-01-01 00:00:00", ValidTo: "2026-02-01 00:00:00"},
+		{ID: 2, ValidFrom: "2026-02-01 00:00:00", ValidTo: "2026-04-01 00:00:00"},
+	}
+
+	// Times reach the repository in UTC.
+	repo.EXPECT().
+		ExportHistory(gomock.Any(), &entity.ExportFilter{AsOf: asOf.UTC()}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *entity.ExportFilter, fn func(*entity.ProductHistory) error) error {
+			for _, h := range history {
+				if err := fn(h); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+
+	var got []entity.ProductHistory
+
+	err := uc.ExportHistory(context.Background(), &entity.ExportFilter{AsOf: asOf}, func(h *entity.ProductHistory) error {
+		got = append(got, *h)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The second version was still current at 12:00 UTC on March 1st.
+	require.Equal(t, []entity.ProductHistory{
+		{ID: 1, ValidFrom: "2026-01-01 00:00:00", ValidTo: "2026-02-01 00:00:00"},
+		{ID: 2, ValidFrom: "2026-02-01 00:00:00"},
+	}, got)
+}
+
+func TestExportStopsOnError(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	repo.EXPECT().
+		ExportProducts(gomock.Any(), &entity.ExportFilter{}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *entity.ExportFilter, fn func(*entity.Product) error) error {
+			return fn(&entity.Product{ID: "1"})
+		})
+
+	err := uc.ExportProducts(context.Background(), &entity.ExportFilter{}, func(*entity.Product) error {
+		return errInternalServErr
+	})
+	require.ErrorIs(t, err, errInternalServErr)
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+const (
+	_defaultImportBatchSize = 500
+	_maxImportBatchSize     = 5000
+)
+
+// errImportDryRun rolls back the transaction of a dry-run batch.
+var errImportDryRun = errors.New("import dry run")
+
+// ImportOptions -.
+type ImportOptions struct {
+	// DryRun writes every batch and rolls it back, so the report shows what an import would do.
+	// As nothing is kept, a batch does not see the products of the batches before it.
+	DryRun bool
+	// BatchSize is the number of rows written per transaction; 0 means 500.
+	BatchSize int
+}
+
+// ImportReport sums up an import. Rows that failed are listed in Errors and written nowhere;
+// the others are written whatever happens to their neighbours.
+type ImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	Rows      int              `json:"rows" example:"1000"`
+	Created   int              `json:"created" example:"900"`
+	Updated   int              `json:"updated" example:"80"`
+	Unchanged int              `json:"unchanged" example:"18"`
+	Failed    int              `json:"failed" example:"2"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// ImportRowError tells why one row of an import failed.
+type ImportRowError struct {
+	Line   int          `json:"line" example:"42"`
+	SKU    string       `json:"sku,omitempty" example:"DARIUS-001"`
+	Error  string       `json:"error" example:"validation failed"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// Import creates the rows of r whose sku is new and updates the others, batch by batch.
+// Rows failing validation or a version check are reported and skipped; any other error stops
+// the import, keeping the batches written so far, and is returned with the report of those batches.
+func (uc *productUseCase) Import(ctx context.Context, r ImportReader, opts ImportOptions) (*ImportReport, error) {
+	if err := validateChange(ctx); err != nil {
+		return nil, err
+	}
+	size := opts.BatchSize
+	if size <= 0 {
+		size = _defaultImportBatchSize
+	}
+	if size > _maxImportBatchSize {
+		size = _maxImportBatchSize
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Errors: []ImportRowError{}}
+	batch := make([]*ImportRow, 0, size)
+	for {
+		row, err := r.Read()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return report, err
+		}
+		if row != nil {
+			batch = append(batch, row)
+		}
+		if len(batch) == size || (errors.Is(err, io.EOF) && len(batch) > 0) {
+			if err := uc.importBatch(ctx, batch, opts.DryRun, report); err != nil {
+				return report, err
+			}
+			batch = batch[:0]
+		}
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+	}
+}
+
+// importBatch writes rows in one transaction and adds their outcome to report once it is committed,
+// or rolled back for a dry run.
+func (uc *productUseCase) importBatch(ctx context.Context, rows []*ImportRow, dryRun bool, report *ImportReport) error {
+	var batch ImportReport
+	err := uc.repo.InTx(ctx, func(repo repository.ProductRepository) error {
+		tx := uc.withRepo(repo)
+		batch = ImportReport{}
+		for _, row := range rows {
+			outcome, err := tx.importRow(ctx, row)
+			switch {
+			case err == nil:
+			case errors.Is(err, ErrValidation), errors.Is(err, ErrConflict), errors.Is(err, ErrNotFound):
+				batch.Errors = append(batch.Errors, importRowError(row, err))
+				continue
+			default:
+				return err
+			}
+			switch outcome {
+			case _importCreated:
+				batch.Created++
+			case _importUpdated:
+				batch.Updated++
+			default:
+				batch.Unchanged++
+			}
+		}
+		if dryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		return err
+	}
+
+	report.Rows += len(rows)
+	report.Created += batch.Created
+	report.Updated += batch.Updated
+	report.Unchanged += batch.Unchanged
+	report.Failed += len(batch.Errors)
+	report.Errors = append(report.Errors, batch.Errors...)
+	return nil
+}
+
+type importOutcome int
+
+const (
+	_importUnchanged importOutcome = iota
+	_importCreated
+	_importUpdated
+)
+
+// importRow upserts the product of row by sku. Updating a product to the values it has is skipped,
+// so importing a file again does not add versions.
+func (uc *productUseCase) importRow(ctx context.Context, row *ImportRow) (importOutcome, error) {
+	if row.Err != nil {
+		return 0, row.Err
+	}
+	p := row.Product
+	if p.SKU == "" {
+		return 0, ErrImportSKURequired
+	}
+
+	existing, err := uc.repo.GetBySKU(ctx, p.SKU)
+	if err != nil {
+		return 0, err
+	}
+	if existing == nil {
+		if _, err := uc.Create(ctx, &p); err != nil {
+			return 0, err
+		}
+		return _importCreated, nil
+	}
+	if existing.DeletedAt != "" {
+		return 0, ErrSKUOfDeletedProduct
+	}
+
+	p.ID = existing.ID
+	if sameImportedValues(existing, &p) {
+		return _importUnchanged, nil
+	}
+	if err := uc.Update(ctx, &p); err != nil {
+		return 0, err
+	}
+	return _importUpdated, nil
+}
+
+func sameImportedValues(stored, p *entity.Product) bool {
+	return stored.Name == p.Name &&
+		stored.Description == p.Description &&
+		stored.Price == p.Price &&
+		(p.EffectiveFrom == "" || stored.EffectiveFrom == p.EffectiveFrom) &&
+		(p.Version == 0 || stored.Version == p.Version)
+}
+
+func importRowError(row *ImportRow, err error) ImportRowError {
+	e := ImportRowError{Line: row.Line, SKU: row.Product.SKU, Error: err.Error()}
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		e.Error = ErrValidation.Error()
+		e.Fields = ve.Fields
+	}
+	return e
+}
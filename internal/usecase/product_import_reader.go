@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+// Import formats.
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// ImportRow is one product of an import. Err is set instead of Product when the row could not be decoded.
+type ImportRow struct {
+	Line    int
+	Product entity.Product
+	Err     error
+}
+
+// ImportReader streams the rows of an import. Read returns io.EOF after the last row;
+// any other error means the input cannot be read any further.
+type ImportReader interface {
+	Read() (*ImportRow, error)
+}
+
+// NewImportReader returns the reader of format over r.
+// CSV input starts with a header naming its columns: sku, name, description, price, currency,
+// effective_from and version, of which sku, name, price and currency are required.
+// NDJSON input has one product per line, shaped like the JSON of a product.
+func NewImportReader(format string, r io.Reader) (ImportReader, error) {
+	switch format {
+	case ImportFormatCSV:
+		return newCSVImportReader(r)
+	case ImportFormatNDJSON:
+		return &ndjsonImportReader{r: bufio.NewReader(r)}, nil
+	default:
+		return nil, ErrUnsupportedImportFormat
+	}
+}
+
+type csvImportReader struct {
+	r       *csv.Reader
+	columns []string
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, &Error{Kind: ErrValidation, Msg: "csv header is missing"}
+	}
+	if err != nil {
+		return nil, &Error{Kind: ErrValidation, Msg: "invalid csv header", Err: err}
+	}
+
+	seen := make(map[string]bool, len(header))
+	columns := make([]string, 0, len(header))
+	for _, h := range header {
+		c := strings.ToLower(strings.TrimSpace(h))
+		switch {
+		case !isImportColumn(c):
+			return nil, &Error{Kind: ErrValidation, Msg: fmt.Sprintf("unknown csv column %q", h)}
+		case seen[c]:
+			return nil, &Error{Kind: ErrValidation, Msg: fmt.Sprintf("duplicate csv column %q", h)}
+		}
+		seen[c] = true
+		columns = append(columns, c)
+	}
+	for _, c := range []string{"sku", "name", "price", "currency"} {
+		if !seen[c] {
+			return nil, &Error{Kind: ErrValidation, Msg: fmt.Sprintf("csv column %q is required", c)}
+		}
+	}
+	return &csvImportReader{r: cr, columns: columns}, nil
+}
+
+func isImportColumn(c string) bool {
+	switch c {
+	case "sku", "name", "description", "price", "currency", "effective_from", "version":
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *csvImportReader) Read() (*ImportRow, error) {
+	record, err := c.r.Read()
+	var pe *csv.ParseError
+	switch {
+	case errors.As(err, &pe):
+		// The reader resumes at the next record, so a malformed one fails only its own row.
+		return &ImportRow{Line: pe.StartLine, Err: &Error{Kind: ErrValidation, Msg: "invalid csv", Err: pe.Err}}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	line, _ := c.r.FieldPos(0)
+	row := &ImportRow{Line: line}
+	var fe fieldErrors
+	for i, v := range record {
+		p := &row.Product
+		switch c.columns[i] {
+		case "sku":
+			p.SKU = v
+		case "name":
+			p.Name = v
+		case "description":
+			p.Description = v
+		case "price":
+			if p.Price.Amount, err = strconv.ParseInt(v, 10, 64); err != nil {
+				fe.add("price", "must be an amount in minor units")
+			}
+		case "currency":
+			p.Price.Currency = v
+		case "effective_from":
+			p.EffectiveFrom = v
+		case "version":
+			if v != "" {
+				if p.Version, err = strconv.ParseUint(v, 10, 64); err != nil {
+					fe.add("version", "must be a product version")
+				}
+			}
+		}
+	}
+	row.Err = fe.err()
+	return row, nil
+}
+
+type ndjsonImportReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// ndjsonProduct is the part of a product an import may set.
+type ndjsonProduct struct {
+	SKU           string       `json:"sku"`
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Price         entity.Money `json:"price"`
+	EffectiveFrom string       `json:"effective_from"`
+	Version       uint64       `json:"version"`
+}
+
+func (n *ndjsonImportReader) Read() (*ImportRow, error) {
+	for {
+		b, err := n.r.ReadBytes('\n')
+		if len(b) == 0 && err != nil {
+			return nil, err
+		}
+		n.line++
+		b = bytes.TrimSpace(b)
+		if len(b) == 0 {
+			continue
+		}
+
+		row := &ImportRow{Line: n.line}
+		var p ndjsonProduct
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		var typeErr *json.UnmarshalTypeError
+		switch err := dec.Decode(&p); {
+		case errors.As(err, &typeErr):
+			row.Err = &ValidationError{Fields: []FieldError{{Field: typeErr.Field, Message: "must be of type " + typeErr.Type.String()}}}
+		case err != nil:
+			row.Err = &Error{Kind: ErrValidation, Msg: "invalid json", Err: err}
+		case dec.More():
+			row.Err = &Error{Kind: ErrValidation, Msg: "invalid json: one product per line"}
+		default:
+			row.Product = entity.Product{
+				SKU:           p.SKU,
+				Name:          p.Name,
+				Description:   p.Description,
+				Price:         p.Price,
+				EffectiveFrom: p.EffectiveFrom,
+				Version:       p.Version,
+			}
+		}
+		return row, nil
+	}
+}
@@ -0,0 +1,141 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+// inTx makes InTx of repo run fn with repo itself, as many times as it is called.
+func inTx(repo *MockProductRepository) *gomock.Call {
+	return repo.EXPECT().InTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(repository.ProductRepository) error) error {
+			return fn(repo)
+		})
+}
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	input := `sku,name,price,currency
+NEW-1,New,100,EUR
+OLD-1,Renamed,200,EUR
+SAME-1,Same,300,EUR
+BAD-1,Bad,ten,EUR
+,No sku,100,EUR
+GONE-1,Gone,100,EUR
+`
+	r, err := usecase.NewImportReader(usecase.ImportFormatCSV, strings.NewReader(input))
+	require.NoError(t, err)
+
+	// Six rows in batches of four.
+	inTx(repo).Times(2)
+
+	repo.EXPECT().GetBySKU(gomock.Any(), "NEW-1").Return(nil, nil)
+	repo.EXPECT().Create(gomock.Any(), &entity.Product{SKU: "NEW-1", Name: "New", Price: entity.Money{Amount: 100, Currency: "EUR"}}).Return(uint64(1), nil)
+
+	repo.EXPECT().GetBySKU(gomock.Any(), "OLD-1").Return(&entity.Product{ID: "2", SKU: "OLD-1", Name: "Old", Price: entity.Money{Amount: 200, Currency: "EUR"}, Version: 3}, nil)
+	repo.EXPECT().Update(gomock.Any(), &entity.Product{ID: "2", SKU: "OLD-1", Name: "Renamed", Price: entity.Money{Amount: 200, Currency: "EUR"}}).Return(nil)
+
+	repo.EXPECT().GetBySKU(gomock.Any(), "SAME-1").Return(&entity.Product{ID: "3", SKU: "SAME-1", Name: "Same", Price: entity.Money{Amount: 300, Currency: "EUR"}}, nil)
+
+	repo.EXPECT().GetBySKU(gomock.Any(), "GONE-1").Return(&entity.Product{ID: "4", SKU: "GONE-1", DeletedAt: "2026-01-01 00:00:00"}, nil)
+
+	report, err := uc.Import(context.Background(), r, usecase.ImportOptions{BatchSize: 4})
+	require.NoError(t, err)
+	require.Equal(t, 6, report.Rows)
+	require.Equal(t, 1, report.Created)
+	require.Equal(t, 1, report.Updated)
+	require.Equal(t, 1, report.Unchanged)
+	require.Equal(t, 3, report.Failed)
+	require.Equal(t, []usecase.ImportRowError{
+		{Line: 5, SKU: "BAD-1", Error: "validation failed", Fields: []usecase.FieldError{{Field: "price", Message: "must be an amount in minor units"}}},
+		{Line: 6, Error: "validation failed", Fields: []usecase.FieldError{{Field: "sku", Message: "is required"}}},
+		{Line: 7, SKU: "GONE-1", Error: usecase.ErrSKUOfDeletedProduct.Error()},
+	}, report.Errors)
+}
+
+func TestImportDryRun(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	r, err := usecase.NewImportReader(usecase.ImportFormatNDJSON, strings.NewReader(`{"sku":"NEW-1","name":"New","price":{"amount":100,"currency":"EUR"}}`))
+	require.NoError(t, err)
+
+	// The batch is written, then rolled back by the error fn returns.
+	repo.EXPECT().InTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(repository.ProductRepository) error) error {
+			err := fn(repo)
+			require.Error(t, err)
+
+			return err
+		})
+	repo.EXPECT().GetBySKU(gomock.Any(), "NEW-1").Return(nil, nil)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(uint64(1), nil)
+
+	report, err := uc.Import(context.Background(), r, usecase.ImportOptions{DryRun: true})
+	require.NoError(t, err)
+	require.True(t, report.DryRun)
+	require.Equal(t, 1, report.Created)
+}
+
+func TestImportStopsOnStoreErrors(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	r, err := usecase.NewImportReader(usecase.ImportFormatNDJSON, strings.NewReader(`{"sku":"NEW-1","name":"New","price":{"amount":100,"currency":"EUR"}}`))
+	require.NoError(t, err)
+
+	inTx(repo)
+	repo.EXPECT().GetBySKU(gomock.Any(), "NEW-1").Return(nil, errInternalServErr)
+
+	report, err := uc.Import(context.Background(), r, usecase.ImportOptions{})
+	require.ErrorIs(t, err, errInternalServErr)
+	require.Equal(t, 0, report.Rows)
+}
+
+func TestImportReader(t *testing.T) {
+	t.Parallel()
+
+	_, err := usecase.NewImportReader("xml", strings.NewReader(""))
+	require.ErrorIs(t, err, usecase.ErrUnsupportedImportFormat)
+
+	for _, header := range []string{"", "sku,name,price", "sku,name,price,currency,colour", "sku,name,price,currency,sku"} {
+		_, err = usecase.NewImportReader(usecase.ImportFormatCSV, strings.NewReader(header))
+		require.ErrorIs(t, err, usecase.ErrValidation, header)
+	}
+
+	r, err := usecase.NewImportReader(usecase.ImportFormatNDJSON, strings.NewReader(`{"sku":"A","price":{"amount":"1"}}
+
+{"sku":"B","colour":"red"}
+{"sku":"C","name":"C","version":2}
+`))
+	require.NoError(t, err)
+
+	row, err := r.Read()
+	require.NoError(t, err)
+	require.Equal(t, 1, row.Line)
+	require.ErrorIs(t, row.Err, usecase.ErrValidation)
+
+	row, err = r.Read()
+	require.NoError(t, err)
+	require.Equal(t, 3, row.Line)
+	require.ErrorIs(t, row.Err, usecase.ErrValidation)
+
+	row, err = r.Read()
+	require.NoError(t, err)
+	require.Equal(t, 4, row.Line)
+	require.NoError(t, row.Err)
+	require.Equal(t, entity.Product{SKU: "C", Name: "C", Version: 2}, row.Product)
+}
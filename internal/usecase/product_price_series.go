@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+// Limits of GetPriceSeries.
+const (
+	MaxPriceSeriesProducts = 50
+	MaxPriceSeriesPoints   = 5000
+)
+
+// GetPriceSeries resamples the price history of every product of q into buckets. The window starts
+// at the beginning of the bucket From falls in and a zero To means now; the last bucket is cut at To.
+// Products are returned in the order of q.ProductIDs; buckets without a price have a null price.
+func (uc *productUseCase) GetPriceSeries(ctx context.Context, q *entity.PriceSeriesQuery) ([]*entity.PriceSeries, error) {
+	bucket, agg := q.Bucket, q.Aggregation
+	if bucket == "" {
+		bucket = entity.PriceBucketDay
+	}
+	if agg == "" {
+		agg = entity.PriceAggLast
+	}
+	to := q.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	to = to.UTC().Truncate(time.Second)
+
+	starts, err := validatePriceSeries(q, bucket, agg, to)
+	if err != nil {
+		return nil, err
+	}
+
+	intervals, err := uc.repo.GetPriceIntervals(ctx, q.ProductIDs, starts[0], to)
+	if err != nil {
+		return nil, err
+	}
+	byProduct := make(map[uint64][]*entity.PriceInterval, len(q.ProductIDs))
+	for _, i := range intervals {
+		byProduct[i.ProductID] = append(byProduct[i.ProductID], i)
+	}
+
+	series := make([]*entity.PriceSeries, 0, len(q.ProductIDs))
+	for _, id := range q.ProductIDs {
+		if len(byProduct[id]) == 0 {
+			// Every product has a version, so only unknown products have no history at all.
+			p, err := uc.repo.GetByID(ctx, id, true)
+			if err != nil {
+				return nil, err
+			}
+			if p == nil {
+				return nil, ErrProductNotFound
+			}
+		}
+		points, err := resamplePrices(byProduct[id], starts, to, agg)
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, &entity.PriceSeries{ProductID: id, Bucket: bucket, Aggregation: agg, Points: points})
+	}
+	return series, nil
+}
+
+// validatePriceSeries checks q and returns the start of every bucket of the window.
+func validatePriceSeries(q *entity.PriceSeriesQuery, bucket, agg string, to time.Time) ([]time.Time, error) {
+	var fe fieldErrors
+	switch {
+	case len(q.ProductIDs) == 0:
+		fe.add("id", "is required")
+	case len(q.ProductIDs) > MaxPriceSeriesProducts:
+		fe.add("id", "must list at most "+strconv.Itoa(MaxPriceSeriesProducts)+" products")
+	}
+	seen := make(map[uint64]bool, len(q.ProductIDs))
+	for _, id := range q.ProductIDs {
+		if seen[id] {
+			fe.add("id", "must not repeat a product")
+			break
+		}
+		seen[id] = true
+	}
+	switch bucket {
+	case entity.PriceBucketHour, entity.PriceBucketDay, entity.PriceBucketWeek, entity.PriceBucketMonth:
+	default:
+		fe.add("bucket", "must be one of hour, day, week, month")
+	}
+	switch agg {
+	case entity.PriceAggLast, entity.PriceAggTimeWeighted:
+	default:
+		fe.add("aggregation", "must be one of last, time_weighted")
+	}
+	if q.From.IsZero() {
+		fe.add("from", "is required")
+	} else if !q.From.Before(to) {
+		fe.add("to", "must be after from")
+	}
+	if err := fe.err(); err != nil {
+		return nil, err
+	}
+
+	var starts []time.Time
+	for t := bucketStart(q.From.UTC(), bucket); t.Before(to); t = nextBucket(t, bucket) {
+		if len(starts) == MaxPriceSeriesPoints {
+			fe.add("from", "must leave at most "+strconv.Itoa(MaxPriceSeriesPoints)+" buckets until to")
+			return nil, fe.err()
+		}
+		starts = append(starts, t)
+	}
+	return starts, nil
+}
+
+// bucketStart returns the start of the bucket t falls in.
+func bucketStart(t time.Time, bucket string) time.Time {
+	switch bucket {
+	case entity.PriceBucketHour:
+		return t.Truncate(time.Hour)
+	case entity.PriceBucketWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case entity.PriceBucketMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func nextBucket(t time.Time, bucket string) time.Time {
+	switch bucket {
+	case entity.PriceBucketHour:
+		return t.Add(time.Hour)
+	case entity.PriceBucketWeek:
+		return t.AddDate(0, 0, 7)
+	case entity.PriceBucketMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// resamplePrices aggregates the intervals of one product, oldest first, into the buckets that start
+// at starts; the last bucket ends at to.
+func resamplePrices(intervals []*entity.PriceInterval, starts []time.Time, to time.Time, agg string) ([]entity.PricePoint, error) {
+	points := make([]entity.PricePoint, len(starts))
+	k := 0
+	for n, start := range starts {
+		end := to
+		if n+1 < len(starts) {
+			end = starts[n+1]
+		}
+		points[n].Time = start.Format(entity.DateTimeLayout)
+
+		// Intervals do not overlap, so those that ended before this bucket are not needed again.
+		for k < len(intervals) && !intervals[k].ValidTo.IsZero() && !intervals[k].ValidTo.After(start) {
+			k++
+		}
+
+		var (
+			last              *entity.Money
+			weighted, seconds float64
+		)
+		for _, i := range intervals[k:] {
+			if !i.ValidFrom.Before(end) {
+				break
+			}
+			from, until := i.ValidFrom, end
+			if from.Before(start) {
+				from = start
+			}
+			if !i.ValidTo.IsZero() && i.ValidTo.Before(until) {
+				until = i.ValidTo
+			}
+			if last != nil && last.Currency != i.Price.Currency && agg == entity.PriceAggTimeWeighted {
+				return nil, ErrMixedCurrencyPrices
+			}
+			price := i.Price
+			last = &price
+			weighted += float64(i.Price.Amount) * until.Sub(from).Seconds()
+			seconds += until.Sub(from).Seconds()
+		}
+
+		if last != nil && agg == entity.PriceAggTimeWeighted && seconds > 0 {
+			last.Amount = int64(math.Round(weighted / seconds))
+		}
+		points[n].Price = last
+	}
+	return points, nil
+}
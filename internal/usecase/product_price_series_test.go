@@ -0,0 +1,178 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+)
+
+func eur(amount int64) *entity.Money {
+	return &entity.Money{Amount: amount, Currency: "EUR"}
+}
+
+func TestGetPriceSeries(t *testing.T) {
+	t.Parallel()
+
+	day := func(d, h int) time.Time { return time.Date(2020, 1, d, h, 0, 0, 0, time.UTC) }
+
+	// Product 1 costs 100 until noon of the 2nd, then 200 until it is deleted on the 3rd and
+	// restored at 300 on the 4th; product 2 has no price in the window.
+	intervals := []*entity.PriceInterval{
+		{ProductID: 1, Price: *eur(100), ValidFrom: day(1, 0), ValidTo: day(2, 12)},
+		{ProductID: 1, Price: *eur(200), ValidFrom: day(2, 12), ValidTo: day(3, 0)},
+		{ProductID: 1, Price: *eur(300), ValidFrom: day(4, 0)},
+	}
+
+	tests := []struct {
+		agg    string
+		points []entity.PricePoint
+	}{
+		{
+			agg: entity.PriceAggLast,
+			points: []entity.PricePoint{
+				{Time: "2020-01-01 00:00:00", Price: eur(100)},
+				{Time: "2020-01-02 00:00:00", Price: eur(200)},
+				{Time: "2020-01-03 00:00:00"},
+				{Time: "2020-01-04 00:00:00", Price: eur(300)},
+			},
+		},
+		{
+			agg: entity.PriceAggTimeWeighted,
+			points: []entity.PricePoint{
+				{Time: "2020-01-01 00:00:00", Price: eur(100)},
+				{Time: "2020-01-02 00:00:00", Price: eur(150)},
+				{Time: "2020-01-03 00:00:00"},
+				{Time: "2020-01-04 00:00:00", Price: eur(300)},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.agg, func(t *testing.T) {
+			t.Parallel()
+
+			uc, repo := product(t)
+
+			// The window starts at the beginning of the day of from and the last bucket ends at to.
+			repo.EXPECT().GetPriceIntervals(gomock.Any(), []uint64{2, 1}, day(1, 0), day(4, 6)).Return(intervals, nil)
+			repo.EXPECT().GetByID(gomock.Any(), uint64(2), true).Return(&entity.Product{ID: "2"}, nil)
+
+			series, err := uc.GetPriceSeries(context.Background(), &entity.PriceSeriesQuery{
+				ProductIDs:  []uint64{2, 1},
+				From:        day(1, 8),
+				To:          day(4, 6),
+				Aggregation: tc.agg,
+			})
+			require.NoError(t, err)
+			require.Len(t, series, 2)
+			require.Equal(t, uint64(2), series[0].ProductID)
+			require.Equal(t, entity.PriceBucketDay, series[0].Bucket)
+			for _, p := range series[0].Points {
+				require.Nil(t, p.Price)
+			}
+			require.Equal(t, uint64(1), series[1].ProductID)
+			require.Equal(t, tc.points, series[1].Points)
+		})
+	}
+}
+
+func TestGetPriceSeriesBuckets(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	// 2020-01-15 is a Wednesday, so weeks start on the 13th.
+	from := time.Date(2020, 1, 15, 10, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 1, 28, 0, 0, 0, 0, time.UTC)
+	intervals := []*entity.PriceInterval{{ProductID: 1, Price: *eur(100), ValidFrom: from}}
+
+	repo.EXPECT().GetPriceIntervals(gomock.Any(), []uint64{1}, time.Date(2020, 1, 13, 0, 0, 0, 0, time.UTC), to).Return(intervals, nil)
+
+	series, err := uc.GetPriceSeries(context.Background(), &entity.PriceSeriesQuery{
+		ProductIDs: []uint64{1},
+		From:       from,
+		To:         to,
+		Bucket:     entity.PriceBucketWeek,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []entity.PricePoint{
+		{Time: "2020-01-13 00:00:00", Price: eur(100)},
+		{Time: "2020-01-20 00:00:00", Price: eur(100)},
+		{Time: "2020-01-27 00:00:00", Price: eur(100)},
+	}, series[0].Points)
+}
+
+func TestGetPriceSeriesErrors(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		q      *entity.PriceSeriesQuery
+		mock   func(repo *MockProductRepository)
+		target error
+	}{
+		{
+			name:   "repeated product",
+			q:      &entity.PriceSeriesQuery{ProductIDs: []uint64{1, 1}, From: from, To: to},
+			mock:   func(repo *MockProductRepository) {},
+			target: usecase.ErrValidation,
+		},
+		{
+			name:   "unknown bucket",
+			q:      &entity.PriceSeriesQuery{ProductIDs: []uint64{1}, From: from, To: to, Bucket: "year"},
+			mock:   func(repo *MockProductRepository) {},
+			target: usecase.ErrValidation,
+		},
+		{
+			name:   "too many buckets",
+			q:      &entity.PriceSeriesQuery{ProductIDs: []uint64{1}, From: from.AddDate(-1, 0, 0), To: to, Bucket: entity.PriceBucketHour},
+			mock:   func(repo *MockProductRepository) {},
+			target: usecase.ErrValidation,
+		},
+		{
+			name: "unknown product",
+			q:    &entity.PriceSeriesQuery{ProductIDs: []uint64{1}, From: from, To: to},
+			mock: func(repo *MockProductRepository) {
+				repo.EXPECT().GetPriceIntervals(gomock.Any(), []uint64{1}, from, to).Return(nil, nil)
+				repo.EXPECT().GetByID(gomock.Any(), uint64(1), true).Return(nil, nil)
+			},
+			target: usecase.ErrProductNotFound,
+		},
+		{
+			name: "currency changed within a bucket",
+			q:    &entity.PriceSeriesQuery{ProductIDs: []uint64{1}, From: from, To: to, Aggregation: entity.PriceAggTimeWeighted},
+			mock: func(repo *MockProductRepository) {
+				repo.EXPECT().GetPriceIntervals(gomock.Any(), []uint64{1}, from, to).Return([]*entity.PriceInterval{
+					{ProductID: 1, Price: *eur(100), ValidFrom: from, ValidTo: from.Add(time.Hour)},
+					{ProductID: 1, Price: entity.Money{Amount: 100, Currency: "USD"}, ValidFrom: from.Add(time.Hour)},
+				}, nil)
+			},
+			target: usecase.ErrMixedCurrencyPrices,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc, repo := product(t)
+			tc.mock(repo)
+
+			_, err := uc.GetPriceSeries(context.Background(), tc.q)
+			require.ErrorIs(t, err, tc.target)
+		})
+	}
+}
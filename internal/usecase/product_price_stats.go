@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+func (uc *productUseCase) GetPriceStats(ctx context.Context, id uint64, from, to time.Time) (*entity.PriceStats, error) {
+	from = from.UTC()
+	if to.IsZero() {
+		to = time.Now()
+	}
+	to = to.UTC().Truncate(time.Second)
+	if !from.IsZero() && !from.Before(to) {
+		return nil, &ValidationError{Fields: []FieldError{{Field: "to", Message: "must be after from"}}}
+	}
+
+	intervals, err := uc.repo.GetPriceIntervals(ctx, []uint64{id}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	s, err := priceStats(id, from, to, intervals)
+	if err != nil || s != nil {
+		return s, err
+	}
+
+	// Every product has a version, so it either did not exist or had no price in the window.
+	p, err := uc.repo.GetByID(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrProductNotFound
+	}
+	return nil, ErrNoPricesInWindow
+}
+
+// pricePeriod is a price and how long it was held within the window.
+type pricePeriod struct {
+	price   entity.Money
+	seconds int64
+}
+
+// priceStats folds the intervals of one product, oldest first, into entity.PriceStats, or nil when none
+// of them overlaps [from, to). A zero from starts at the first interval and a zero ValidTo means it is
+// still current. Consecutive intervals with the same price are one period, also across the time the
+// product was deleted.
+func priceStats(id uint64, from, to time.Time, intervals []*entity.PriceInterval) (*entity.PriceStats, error) {
+	var (
+		start   time.Time
+		periods []pricePeriod
+	)
+	for _, i := range intervals {
+		if len(periods) > 0 && periods[0].price.Currency != i.Price.Currency {
+			return nil, ErrMixedCurrencyPrices
+		}
+		begin, end := i.ValidFrom, to
+		if begin.Before(from) {
+			begin = from
+		}
+		if !i.ValidTo.IsZero() && i.ValidTo.Before(end) {
+			end = i.ValidTo
+		}
+		if !begin.Before(end) {
+			continue
+		}
+		seconds := int64(end.Sub(begin).Seconds())
+		if n := len(periods); n > 0 && periods[n-1].price == i.Price {
+			periods[n-1].seconds += seconds
+			continue
+		}
+		if len(periods) == 0 {
+			start = begin
+		}
+		periods = append(periods, pricePeriod{price: i.Price, seconds: seconds})
+	}
+	if len(periods) == 0 {
+		return nil, nil
+	}
+
+	first, last := periods[0], periods[len(periods)-1]
+	currency := first.price.Currency
+	s := &entity.PriceStats{
+		ProductID: id,
+		From:      start.Format(entity.DateTimeLayout),
+		To:        to.Format(entity.DateTimeLayout),
+		Min:       first.price,
+		Max:       first.price,
+		First:     first.price,
+		Last:      last.price,
+		Changes:   len(periods) - 1,
+	}
+	var sum, weighted, seconds float64
+	held := make(map[int64]int64)
+	for _, p := range periods {
+		amount, d := p.price.Amount, p.seconds
+		if amount < s.Min.Amount {
+			s.Min.Amount = amount
+		}
+		if amount > s.Max.Amount {
+			s.Max.Amount = amount
+		}
+		sum += float64(amount)
+		weighted += float64(amount) * float64(d)
+		seconds += float64(d)
+		held[amount] += d
+	}
+
+	s.Average = entity.Money{Amount: int64(math.Round(sum / float64(len(periods)))), Currency: currency}
+	s.TimeWeightedAverage = s.Average
+	if seconds > 0 {
+		s.TimeWeightedAverage.Amount = int64(math.Round(weighted / seconds))
+	}
+	for amount, d := range held {
+		if d > s.LongestHeld.Seconds || (d == s.LongestHeld.Seconds && amount > s.LongestHeld.Price.Amount) {
+			s.LongestHeld = entity.HeldPrice{Price: entity.Money{Amount: amount, Currency: currency}, Seconds: d}
+		}
+	}
+	s.LongestHeld.Duration = formatHeldDuration(s.LongestHeld.Seconds)
+	if first.price.Amount != 0 {
+		pct := math.Round(float64(last.price.Amount-first.price.Amount)/float64(first.price.Amount)*10000) / 100
+		s.PercentChange = &pct
+	}
+	return s, nil
+}
+
+// formatHeldDuration renders seconds like MySQL TIMEDIFF does, e.g. "168:00:00".
+func formatHeldDuration(seconds int64) string {
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
@@ -0,0 +1,201 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+)
+
+func jan(day int) time.Time {
+	return time.Date(2020, 1, day, 0, 0, 0, 0, time.UTC)
+}
+
+func percent(p float64) *float64 {
+	return &p
+}
+
+func TestGetPriceStats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		from      time.Time
+		to        time.Time
+		intervals []*entity.PriceInterval
+		want      *entity.PriceStats
+	}{
+		{
+			name: "window edges clip the first and the current version",
+			from: jan(6),
+			to:   jan(26),
+			intervals: []*entity.PriceInterval{
+				{ProductID: 1, Price: *eur(100), ValidFrom: jan(1), ValidTo: jan(11)},
+				{ProductID: 1, Price: *eur(200), ValidFrom: jan(11), ValidTo: jan(21)},
+				{ProductID: 1, Price: *eur(150), ValidFrom: jan(21)},
+			},
+			want: &entity.PriceStats{
+				ProductID: 1, From: "2020-01-06 00:00:00", To: "2020-01-26 00:00:00",
+				Min: *eur(100), Max: *eur(200), First: *eur(100), Last: *eur(150), Changes: 2,
+				// (100 + 200 + 150) / 3 and (100*5 + 200*10 + 150*5) / 20 days.
+				Average: *eur(150), TimeWeightedAverage: *eur(163),
+				LongestHeld:   entity.HeldPrice{Price: *eur(200), Duration: "240:00:00", Seconds: 864000},
+				PercentChange: percent(50),
+			},
+		},
+		{
+			name: "a version ending at from is outside the window",
+			from: jan(6),
+			to:   jan(7),
+			intervals: []*entity.PriceInterval{
+				{ProductID: 1, Price: *eur(300), ValidFrom: jan(1), ValidTo: jan(6)},
+				{ProductID: 1, Price: *eur(100), ValidFrom: jan(6)},
+			},
+			want: &entity.PriceStats{
+				ProductID: 1, From: "2020-01-06 00:00:00", To: "2020-01-07 00:00:00",
+				Min: *eur(100), Max: *eur(100), First: *eur(100), Last: *eur(100),
+				Average: *eur(100), TimeWeightedAverage: *eur(100),
+				LongestHeld:   entity.HeldPrice{Price: *eur(100), Duration: "24:00:00", Seconds: 86400},
+				PercentChange: percent(0),
+			},
+		},
+		{
+			name: "open-ended current version without from",
+			to:   jan(8),
+			intervals: []*entity.PriceInterval{
+				{ProductID: 1, Price: entity.Money{Amount: 0, Currency: "EUR"}, ValidFrom: jan(1)},
+			},
+			want: &entity.PriceStats{
+				ProductID: 1, From: "2020-01-01 00:00:00", To: "2020-01-08 00:00:00",
+				Min: *eur(0), Max: *eur(0), First: *eur(0), Last: *eur(0),
+				Average: *eur(0), TimeWeightedAverage: *eur(0),
+				LongestHeld: entity.HeldPrice{Price: *eur(0), Duration: "168:00:00", Seconds: 604800},
+			},
+		},
+		{
+			name: "same price across a deletion is one period",
+			from: jan(1),
+			to:   jan(5),
+			intervals: []*entity.PriceInterval{
+				{ProductID: 1, Price: *eur(100), ValidFrom: jan(1), ValidTo: jan(2)},
+				{ProductID: 1, Price: *eur(100), ValidFrom: jan(3)},
+			},
+			want: &entity.PriceStats{
+				ProductID: 1, From: "2020-01-01 00:00:00", To: "2020-01-05 00:00:00",
+				Min: *eur(100), Max: *eur(100), First: *eur(100), Last: *eur(100),
+				Average: *eur(100), TimeWeightedAverage: *eur(100),
+				LongestHeld:   entity.HeldPrice{Price: *eur(100), Duration: "72:00:00", Seconds: 259200},
+				PercentChange: percent(0),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc, repo := product(t)
+			repo.EXPECT().GetPriceIntervals(gomock.Any(), []uint64{1}, tc.from, tc.to).Return(tc.intervals, nil)
+
+			s, err := uc.GetPriceStats(context.Background(), 1, tc.from, tc.to)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, s)
+		})
+	}
+}
+
+func TestGetPriceStatsWindowInUTC(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	from := time.Date(2020, 1, 1, 1, 0, 0, 0, time.FixedZone("CET", 3600))
+	to := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	repo.EXPECT().GetPriceIntervals(gomock.Any(), []uint64{1}, jan(1), to).
+		Return([]*entity.PriceInterval{{ProductID: 1, Price: *eur(100), ValidFrom: jan(1)}}, nil)
+
+	s, err := uc.GetPriceStats(context.Background(), 1, from, to)
+	require.NoError(t, err)
+	require.Equal(t, "2020-01-01 00:00:00", s.From)
+}
+
+func TestGetPriceStatsErrors(t *testing.T) {
+	t.Parallel()
+
+	from := jan(1)
+
+	tests := []struct {
+		name   string
+		from   time.Time
+		to     time.Time
+		mock   func(repo *MockProductRepository)
+		target error
+	}{
+		{
+			name:   "empty window",
+			from:   from,
+			to:     from,
+			mock:   func(repo *MockProductRepository) {},
+			target: usecase.ErrValidation,
+		},
+		{
+			name: "unknown product",
+			from: from,
+			mock: func(repo *MockProductRepository) {
+				repo.EXPECT().GetPriceIntervals(gomock.Any(), []uint64{1}, from, gomock.Any()).Return(nil, nil)
+				repo.EXPECT().GetByID(gomock.Any(), uint64(1), true).Return(nil, nil)
+			},
+			target: usecase.ErrProductNotFound,
+		},
+		{
+			name: "no prices in window",
+			from: from,
+			mock: func(repo *MockProductRepository) {
+				repo.EXPECT().GetPriceIntervals(gomock.Any(), []uint64{1}, from, gomock.Any()).Return(nil, nil)
+				repo.EXPECT().GetByID(gomock.Any(), uint64(1), true).Return(&entity.Product{ID: "1"}, nil)
+			},
+			target: usecase.ErrNoPricesInWindow,
+		},
+		{
+			name: "mixed currencies",
+			from: from,
+			mock: func(repo *MockProductRepository) {
+				repo.EXPECT().GetPriceIntervals(gomock.Any(), []uint64{1}, from, gomock.Any()).Return([]*entity.PriceInterval{
+					{ProductID: 1, Price: *eur(100), ValidFrom: jan(1), ValidTo: jan(2)},
+					{ProductID: 1, Price: entity.Money{Amount: 400, Currency: "PLN"}, ValidFrom: jan(2)},
+				}, nil)
+			},
+			target: usecase.ErrValidation,
+		},
+		{
+			name: "repository error",
+			from: from,
+			mock: func(repo *MockProductRepository) {
+				repo.EXPECT().GetPriceIntervals(gomock.Any(), []uint64{1}, from, gomock.Any()).Return(nil, errInternalServErr)
+			},
+			target: errInternalServErr,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc, repo := product(t)
+			tc.mock(repo)
+
+			_, err := uc.GetPriceStats(context.Background(), 1, tc.from, tc.to)
+			require.ErrorIs(t, err, tc.target)
+		})
+	}
+}
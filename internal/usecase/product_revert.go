@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+func (uc *productUseCase) Revert(ctx context.Context, id uint64, to entity.VersionRef, version uint64) (*entity.Product, error) {
+	if err := validateChange(ctx); err != nil {
+		return nil, err
+	}
+	change := entity.ChangeFromContext(ctx)
+	var fe fieldErrors
+	if change.Actor == "" {
+		fe.add("actor", "is required")
+	}
+	if to == (entity.VersionRef{}) {
+		fe.add("to", "is required")
+	}
+	validateVersionRef(&fe, "to", to)
+	if err := fe.err(); err != nil {
+		return nil, err
+	}
+
+	p, history, err := uc.GetProductHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	h := findVersion(history, to)
+	if h == nil {
+		return nil, ErrProductVersionNotFound
+	}
+	if h.DeletedAt != "" {
+		return nil, ErrRevertToDeletedVersion
+	}
+
+	if change.Reason == "" {
+		change.Reason = "revert to version " + strconv.FormatUint(h.Version, 10)
+		ctx = entity.WithChange(ctx, change)
+	}
+	reverted := &entity.Product{ID: p.ID, SKU: h.SKU, Name: h.Name, Description: h.Description, Price: h.Price, Version: version}
+	if err := validateProduct(reverted, false); err != nil {
+		return nil, err
+	}
+	if err := productRepoError(uc.repo.Update(ctx, reverted)); err != nil {
+		return nil, err
+	}
+	return uc.GetByID(ctx, id, false)
+}
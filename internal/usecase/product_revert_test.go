@@ -0,0 +1,112 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+func TestRevert(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+	ctx := entity.WithChange(context.Background(), entity.Change{Actor: "jane"})
+	current := &entity.Product{ID: "1", Name: "m", Description: "d", Price: *eur(150), Version: 3}
+
+	repo.EXPECT().GetProductHistory(ctx, uint64(1)).Return(current, diffHistory()[:2], nil)
+	repo.EXPECT().Update(gomock.Any(), &entity.Product{ID: "1", Name: "n", Price: *eur(100), Version: 3}).DoAndReturn(
+		func(ctx context.Context, _ *entity.Product) error {
+			require.Equal(t, entity.Change{Actor: "jane", Reason: "revert to version 1"}, entity.ChangeFromContext(ctx))
+			return nil
+		})
+	repo.EXPECT().GetByID(gomock.Any(), uint64(1), false).Return(&entity.Product{ID: "1", Name: "n", Price: *eur(100), Version: 4}, nil)
+
+	p, err := uc.Revert(ctx, 1, entity.VersionRef{At: "2020-01-01 12:00:00"}, 3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), p.Version)
+}
+
+func TestRevertErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := entity.WithChange(context.Background(), entity.Change{Actor: "jane"})
+	current := &entity.Product{ID: "1", Name: "m", Description: "d", Price: *eur(150), Version: 3}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		to     entity.VersionRef
+		mock   func(repo *MockProductRepository)
+		target error
+	}{
+		{
+			name:   "no actor",
+			ctx:    context.Background(),
+			to:     entity.VersionRef{HistoryID: 10},
+			mock:   func(repo *MockProductRepository) {},
+			target: usecase.ErrValidation,
+		},
+		{
+			name:   "reason too long",
+			ctx:    entity.WithChange(context.Background(), entity.Change{Actor: "jane", Reason: strings.Repeat("a", entity.MaxChangeReasonLen+1)}),
+			to:     entity.VersionRef{HistoryID: 10},
+			mock:   func(repo *MockProductRepository) {},
+			target: usecase.ErrValidation,
+		},
+		{
+			name:   "no version",
+			ctx:    ctx,
+			mock:   func(repo *MockProductRepository) {},
+			target: usecase.ErrValidation,
+		},
+		{
+			name: "unknown version",
+			ctx:  ctx,
+			to:   entity.VersionRef{HistoryID: 99},
+			mock: func(repo *MockProductRepository) {
+				repo.EXPECT().GetProductHistory(gomock.Any(), uint64(1)).Return(current, diffHistory(), nil)
+			},
+			target: usecase.ErrProductVersionNotFound,
+		},
+		{
+			name: "deleted version",
+			ctx:  ctx,
+			to:   entity.VersionRef{HistoryID: 12},
+			mock: func(repo *MockProductRepository) {
+				repo.EXPECT().GetProductHistory(gomock.Any(), uint64(1)).Return(current, diffHistory(), nil)
+			},
+			target: usecase.ErrRevertToDeletedVersion,
+		},
+		{
+			name: "modified in between",
+			ctx:  ctx,
+			to:   entity.VersionRef{HistoryID: 10},
+			mock: func(repo *MockProductRepository) {
+				repo.EXPECT().GetProductHistory(gomock.Any(), uint64(1)).Return(current, diffHistory(), nil)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(&repository.ConflictError{ID: 1, Expected: 3, Actual: 4})
+			},
+			target: usecase.ErrConflict,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc, repo := product(t)
+			tc.mock(repo)
+
+			_, err := uc.Revert(tc.ctx, 1, tc.to, 3)
+			require.ErrorIs(t, err, tc.target)
+		})
+	}
+}
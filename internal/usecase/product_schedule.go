@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+const (
+	_scheduleBatchSize = 100
+	// _schedulerActor is recorded as the actor of the versions scheduled changes create.
+	_schedulerActor = "scheduler"
+)
+
+func (uc *productUseCase) ScheduleChange(ctx context.Context, c *entity.ScheduledChange) (*entity.ScheduledChange, error) {
+	at, err := time.ParseInLocation(entity.DateTimeLayout, c.EffectiveAt, time.UTC)
+	if err != nil || !at.After(time.Now().UTC()) || (c.Name == nil && c.Description == nil && c.Price == nil) {
+		return nil, ErrInvalidScheduledChange
+	}
+	if err := validateScheduledFields(c); err != nil {
+		return nil, err
+	}
+
+	p, err := uc.repo.GetByID(ctx, c.ProductID, false)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrProductNotFound
+	}
+
+	id, err := uc.repo.CreateScheduledChange(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return uc.repo.GetScheduledChange(ctx, id)
+}
+
+func (uc *productUseCase) ListScheduledChanges(ctx context.Context, productID uint64, status string) ([]*entity.ScheduledChange, error) {
+	return uc.repo.ListScheduledChanges(ctx, productID, status)
+}
+
+func (uc *productUseCase) CancelScheduledChange(ctx context.Context, productID, changeID uint64) error {
+	c, err := uc.repo.GetScheduledChange(ctx, changeID)
+	if err != nil {
+		return err
+	}
+	if c == nil || c.ProductID != productID {
+		return ErrScheduledChangeNotFound
+	}
+
+	ok, err := uc.repo.TransitionScheduledChange(ctx, changeID, entity.ScheduledChangePending, entity.ScheduledChangeCancelled)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrScheduledChangeNotPending
+	}
+	return nil
+}
+
+func (uc *productUseCase) ApplyDueChanges(ctx context.Context) (int, error) {
+	changes, err := uc.repo.DueScheduledChanges(ctx, _scheduleBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// A change that fails is left pending and does not hold back the changes after it.
+	var errs []error
+	applied := 0
+	for _, c := range changes {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		var done bool
+		// The claim, the write and the cancellation of a change for a deleted product commit together,
+		// so that a change that fails to apply stays pending for the next run.
+		err := uc.repo.InTx(ctx, func(repo repository.ProductRepository) error {
+			var err error
+			done, err = uc.withRepo(repo).applyScheduledChange(entity.WithChange(ctx, scheduledChange(c)), c)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("apply scheduled change %d: %w", c.ID, err))
+			continue
+		}
+		if done {
+			applied++
+		}
+	}
+	return applied, errors.Join(errs...)
+}
+
+// scheduledChange is the entity.Change recorded with the version a scheduled change creates.
+func scheduledChange(c *entity.ScheduledChange) entity.Change {
+	return entity.Change{Actor: _schedulerActor, Reason: "scheduled change " + strconv.FormatUint(c.ID, 10)}
+}
+
+// applyScheduledChange reports false when another worker claimed the change or when the product is gone
+// and the change was cancelled instead.
+func (uc *productUseCase) applyScheduledChange(ctx context.Context, c *entity.ScheduledChange) (bool, error) {
+	// Claim first so that concurrent workers never apply the same change twice.
+	ok, err := uc.repo.TransitionScheduledChange(ctx, c.ID, entity.ScheduledChangePending, entity.ScheduledChangeApplied)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	p, err := uc.repo.GetByID(ctx, c.ProductID, false)
+	if err != nil {
+		return false, err
+	}
+	if p == nil {
+		_, err = uc.repo.TransitionScheduledChange(ctx, c.ID, entity.ScheduledChangeApplied, entity.ScheduledChangeCancelled)
+		return false, err
+	}
+
+	changed := &entity.Product{
+		ID:            p.ID,
+		SKU:           p.SKU,
+		Name:          p.Name,
+		Description:   p.Description,
+		Price:         p.Price,
+		EffectiveFrom: c.EffectiveAt,
+		Version:       p.Version,
+	}
+	if c.Name != nil {
+		changed.Name = *c.Name
+	}
+	if c.Description != nil {
+		changed.Description = *c.Description
+	}
+	if c.Price != nil {
+		changed.Price = *c.Price
+	}
+
+	return true, uc.Update(ctx, changed)
+}
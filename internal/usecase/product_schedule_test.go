@@ -0,0 +1,141 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+func TestScheduleChangeValidation(t *testing.T) {
+	t.Parallel()
+
+	uc, _ := product(t)
+
+	price := entity.Money{Amount: 120, Currency: "EUR"}
+	future := time.Now().UTC().Add(time.Hour).Format(entity.DateTimeLayout)
+	past := time.Now().UTC().Add(-time.Hour).Format(entity.DateTimeLayout)
+
+	tests := []struct {
+		name   string
+		change *entity.ScheduledChange
+	}{
+		{
+			name:   "nothing to change",
+			change: &entity.ScheduledChange{ProductID: 1, EffectiveAt: future},
+		},
+		{
+			name:   "in the past",
+			change: &entity.ScheduledChange{ProductID: 1, Price: &price, EffectiveAt: past},
+		},
+		{
+			name:   "malformed time",
+			change: &entity.ScheduledChange{ProductID: 1, Price: &price, EffectiveAt: "tomorrow"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := uc.ScheduleChange(context.Background(), tc.change)
+			require.ErrorIs(t, err, usecase.ErrInvalidScheduledChange)
+		})
+	}
+}
+
+func TestApplyDueChanges(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	price := entity.Money{Amount: 120, Currency: "EUR"}
+	due := []*entity.ScheduledChange{
+		{ID: 1, ProductID: 7, Price: &price, EffectiveAt: "2030-01-01 00:00:00"},
+		{ID: 2, ProductID: 8, Price: &price, EffectiveAt: "2030-01-01 00:00:00"},
+		{ID: 3, ProductID: 9, Price: &price, EffectiveAt: "2030-01-01 00:00:00"},
+	}
+
+	repo.EXPECT().DueScheduledChanges(gomock.Any(), gomock.Any()).Return(due, nil)
+	inTx(repo).Times(3)
+
+	// 1 is applied through Update with the scheduled time as business time; the timestamps read are not written.
+	repo.EXPECT().TransitionScheduledChange(gomock.Any(), uint64(1), entity.ScheduledChangePending, entity.ScheduledChangeApplied).Return(true, nil)
+	repo.EXPECT().GetByID(gomock.Any(), uint64(7), false).Return(&entity.Product{
+		ID: "7", SKU: "S-7", Name: "n", Price: entity.Money{Amount: 100, Currency: "EUR"}, CreatedAt: "2020-01-01 00:00:00", UpdatedAt: "2020-01-02 00:00:00",
+	}, nil)
+	repo.EXPECT().Update(gomock.Any(), &entity.Product{ID: "7", SKU: "S-7", Name: "n", Price: price, EffectiveFrom: "2030-01-01 00:00:00"}).DoAndReturn(
+		func(ctx context.Context, _ *entity.Product) error {
+			require.Equal(t, entity.Change{Actor: "scheduler", Reason: "scheduled change 1"}, entity.ChangeFromContext(ctx))
+			return nil
+		})
+
+	// 2 was claimed by another worker.
+	repo.EXPECT().TransitionScheduledChange(gomock.Any(), uint64(2), entity.ScheduledChangePending, entity.ScheduledChangeApplied).Return(false, nil)
+
+	// 3 belongs to a deleted product and is cancelled.
+	repo.EXPECT().TransitionScheduledChange(gomock.Any(), uint64(3), entity.ScheduledChangePending, entity.ScheduledChangeApplied).Return(true, nil)
+	repo.EXPECT().GetByID(gomock.Any(), uint64(9), false).Return(nil, nil)
+	repo.EXPECT().TransitionScheduledChange(gomock.Any(), uint64(3), entity.ScheduledChangeApplied, entity.ScheduledChangeCancelled).Return(true, nil)
+
+	n, err := uc.ApplyDueChanges(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
+
+func TestApplyDueChangesRollsBackOnError(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	price := entity.Money{Amount: 120, Currency: "EUR"}
+	due := []*entity.ScheduledChange{
+		{ID: 1, ProductID: 7, Price: &price, EffectiveAt: "2030-01-01 00:00:00"},
+		{ID: 2, ProductID: 8, Price: &price, EffectiveAt: "2030-01-01 00:00:00"},
+	}
+
+	// The claim of 1 is undone with the transaction, not by a second write.
+	repo.EXPECT().DueScheduledChanges(gomock.Any(), gomock.Any()).Return(due, nil)
+	inTx(repo).Times(2)
+	repo.EXPECT().TransitionScheduledChange(gomock.Any(), uint64(1), entity.ScheduledChangePending, entity.ScheduledChangeApplied).Return(true, nil)
+	repo.EXPECT().GetByID(gomock.Any(), uint64(7), false).Return(nil, errInternalServErr)
+
+	// 2 is still applied.
+	repo.EXPECT().TransitionScheduledChange(gomock.Any(), uint64(2), entity.ScheduledChangePending, entity.ScheduledChangeApplied).Return(true, nil)
+	repo.EXPECT().GetByID(gomock.Any(), uint64(8), false).Return(&entity.Product{ID: "8", SKU: "S-8", Name: "n", Price: entity.Money{Amount: 100, Currency: "EUR"}}, nil)
+	repo.EXPECT().Update(gomock.Any(), &entity.Product{ID: "8", SKU: "S-8", Name: "n", Price: price, EffectiveFrom: "2030-01-01 00:00:00"}).Return(nil)
+
+	n, err := uc.ApplyDueChanges(context.Background())
+	require.ErrorIs(t, err, errInternalServErr)
+	require.ErrorContains(t, err, "apply scheduled change 1")
+	require.Equal(t, 1, n)
+}
+
+func TestApplyDueChangesRollsBackOnVersionConflict(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	price := entity.Money{Amount: 120, Currency: "EUR"}
+	due := []*entity.ScheduledChange{{ID: 1, ProductID: 7, Price: &price, EffectiveAt: "2030-01-01 00:00:00"}}
+	conflict := &repository.ConflictError{ID: 7, Expected: 3, Actual: 4}
+
+	// The product is edited between the read and the write; the change is retried on the next run.
+	repo.EXPECT().DueScheduledChanges(gomock.Any(), gomock.Any()).Return(due, nil)
+	inTx(repo)
+	repo.EXPECT().TransitionScheduledChange(gomock.Any(), uint64(1), entity.ScheduledChangePending, entity.ScheduledChangeApplied).Return(true, nil)
+	repo.EXPECT().GetByID(gomock.Any(), uint64(7), false).Return(&entity.Product{ID: "7", SKU: "S-7", Name: "n", Price: entity.Money{Amount: 100, Currency: "EUR"}, Version: 3}, nil)
+	repo.EXPECT().Update(gomock.Any(), &entity.Product{ID: "7", SKU: "S-7", Name: "n", Price: price, EffectiveFrom: "2030-01-01 00:00:00", Version: 3}).Return(conflict)
+
+	n, err := uc.ApplyDueChanges(context.Background())
+	require.ErrorIs(t, err, usecase.ErrVersionConflict)
+	require.Equal(t, 0, n)
+}
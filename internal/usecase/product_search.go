@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+// Limits of searches.
+const (
+	MaxSearchQueryLen = 200
+	MaxSearchTerms    = 10
+)
+
+const (
+	// _minSearchTermLen drops single characters, which as prefixes would match nearly everything.
+	_minSearchTermLen = 2
+	// _searchSnippetLen is the length of the description excerpt of a hit, in characters.
+	_searchSnippetLen = 160
+	// _searchSnippetLead is how many words and separators of a long description come before its first match.
+	_searchSnippetLead = 8
+	// _minTypoTermLen is the length from which a term also finds the words one typo away from it; a typo
+	// in a shorter word mostly makes another word.
+	_minTypoTermLen = 4
+	// _maxTypoWords is how many such words a term is searched as at most.
+	_maxTypoWords = 5
+)
+
+func (uc *productUseCase) Search(ctx context.Context, query string, f *entity.ProductFilter) (*entity.ProductSearchPage, error) {
+	var fe fieldErrors
+	terms := searchTerms(query)
+	switch {
+	case utf8.RuneCountInString(query) > MaxSearchQueryLen:
+		fe.add("q", "must be at most "+strconv.Itoa(MaxSearchQueryLen)+" characters")
+	case len(terms) == 0:
+		fe.add("q", "must have a word of at least "+strconv.Itoa(_minSearchTermLen)+" letters or digits")
+	case len(terms) > MaxSearchTerms:
+		fe.add("q", "must have at most "+strconv.Itoa(MaxSearchTerms)+" words")
+	}
+	validatePriceBounds(&fe, f)
+	if err := fe.err(); err != nil {
+		return nil, err
+	}
+
+	s := &entity.ProductSearch{Filter: *f}
+	s.Filter.Tag = normalizeTag(s.Filter.Tag)
+	s.Filter.SortBy, s.Filter.Desc = "", false
+	if s.Filter.Limit == 0 {
+		s.Filter.Limit = _defaultListLimit
+	}
+	if s.Filter.Limit > _maxListLimit {
+		s.Filter.Limit = _maxListLimit
+	}
+	if s.Filter.Cursor != "" {
+		after, err := decodeCursor(s.Filter.Cursor)
+		if err != nil || after.SortBy != repository.SearchCursorSort {
+			return nil, ErrInvalidCursor
+		}
+		if _, err := strconv.ParseFloat(after.Value, 64); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		s.Filter.After = after
+		s.Filter.Offset = 0
+	}
+	var prefixes []string
+	for _, term := range terms {
+		alternatives, err := uc.searchAlternatives(ctx, term)
+		if err != nil {
+			return nil, err
+		}
+		s.Terms = append(s.Terms, alternatives)
+		prefixes = append(prefixes, alternatives...)
+	}
+
+	// One extra row tells whether another page exists, like in List.
+	limit := s.Filter.Limit
+	s.Filter.Limit++
+	hits, total, err := uc.repo.Search(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.ProductSearchPage{Hits: hits, Total: total}
+	if uint64(len(hits)) > limit {
+		page.Hits = hits[:limit]
+		last := page.Hits[limit-1]
+		c := &entity.ProductCursor{SortBy: repository.SearchCursorSort, Value: strconv.FormatFloat(last.Score, 'g', -1, 64)}
+		c.ID, _ = strconv.ParseUint(last.Product.ID, 10, 64)
+		page.NextCursor = encodeCursor(c)
+	}
+	if page.Hits == nil {
+		page.Hits = []*entity.ProductSearchHit{}
+	}
+	for _, hit := range page.Hits {
+		hit.Highlights = entity.ProductHighlights{
+			Name:        highlight(hit.Product.Name, prefixes, 0),
+			Description: highlight(hit.Product.Description, prefixes, _searchSnippetLen),
+		}
+	}
+	return page, nil
+}
+
+// searchTerms splits a query into its distinct lower-case words, dropping those too short to search for.
+// Anything but letters and digits separates words, so no query syntax reaches the store.
+func searchTerms(query string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), isNotWordRune) {
+		if utf8.RuneCountInString(word) < _minSearchTermLen || seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// searchAlternatives are the prefixes a term is searched as: its relaxedPrefixes and, from _minTypoTermLen
+// characters on, the words of products one typo away from it, so that e.g. "bakcpack" finds "backpack"
+// and "chiar" finds "chair". Words already found by a prefix are left out.
+func (uc *productUseCase) searchAlternatives(ctx context.Context, term string) ([]string, error) {
+	alternatives := relaxedPrefixes(term)
+	if utf8.RuneCountInString(term) < _minTypoTermLen {
+		return alternatives, nil
+	}
+
+	words, err := uc.repo.SearchWords(ctx, term)
+	if err != nil {
+		return nil, err
+	}
+	typos := 0
+	for _, word := range words {
+		if typos == _maxTypoWords {
+			break
+		}
+		if oneTypoApart([]rune(term), []rune(word)) && !hasAnyPrefix(word, alternatives) {
+			alternatives = append(alternatives, word)
+			typos++
+		}
+	}
+	return alternatives, nil
+}
+
+// oneTypoApart reports whether b is a with one character replaced, added or removed, or with two
+// neighbouring characters swapped.
+func oneTypoApart(a, b []rune) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	i := 0
+	for i < len(a) && a[i] == b[i] {
+		i++
+	}
+	switch len(b) - len(a) {
+	case 0:
+		if i == len(a) {
+			return false
+		}
+		if slices.Equal(a[i+1:], b[i+1:]) {
+			return true
+		}
+		return i+1 < len(a) && a[i] == b[i+1] && a[i+1] == b[i] && slices.Equal(a[i+2:], b[i+2:])
+	case 1:
+		return slices.Equal(a[i:], b[i+1:])
+	default:
+		return false
+	}
+}
+
+// relaxedPrefixes are the term itself and, relaxed, the term without its last character from 4 characters
+// on, or its last two from 6, so that e.g. "lamps" or "chairs" also find "lamp" and "chair". Matching the
+// term itself as well ranks exact prefixes higher.
+func relaxedPrefixes(term string) []string {
+	runes := []rune(term)
+	switch n := len(runes); {
+	case n >= 6:
+		return []string{term, string(runes[:n-2])}
+	case n >= 4:
+		return []string{term, string(runes[:n-1])}
+	default:
+		return []string{term}
+	}
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight escapes text for HTML and wraps its words that start with one of prefixes in <mark> tags.
+// When max is non-zero and text is longer, only an excerpt of about max characters from just before
+// the first match is kept, with an ellipsis where text was cut.
+func highlight(text string, prefixes []string, max int) string {
+	var (
+		segments []string
+		matched  []bool
+		first    = -1
+	)
+	for len(text) > 0 {
+		r, _ := utf8.DecodeRuneInString(text)
+		word := !isNotWordRune(r)
+		end := strings.IndexFunc(text, func(r rune) bool { return isNotWordRune(r) == word })
+		if end < 0 {
+			end = len(text)
+		}
+		match := word && hasAnyPrefix(strings.ToLower(text[:end]), prefixes)
+		if match && first < 0 {
+			first = len(segments)
+		}
+		segments = append(segments, text[:end])
+		matched = append(matched, match)
+		text = text[end:]
+	}
+
+	from, to := 0, len(segments)
+	if max > 0 && utf8.RuneCountInString(strings.Join(segments, "")) > max {
+		if first > _searchSnippetLead {
+			from = first - _searchSnippetLead
+		}
+		n := 0
+		for to = from; to < len(segments); to++ {
+			l := utf8.RuneCountInString(segments[to])
+			if to > from && n+l > max {
+				break
+			}
+			n += l
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	for i := from; i < to; i++ {
+		if matched[i] {
+			b.WriteString("<mark>" + html.EscapeString(segments[i]) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(segments[i]))
+		}
+	}
+	if to < len(segments) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+package usecase_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+)
+
+func TestSearchProducts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo := product(t)
+
+	// A word one typo away that the relaxed prefixes find anyway, and one too far.
+	repo.EXPECT().SearchWords(ctx, "laptpo").Return([]string{"lapdog", "laptop"}, nil).Times(2)
+
+	var searched *entity.ProductSearch
+	repo.EXPECT().Search(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, s *entity.ProductSearch) ([]*entity.ProductSearchHit, uint64, error) {
+			searched = s
+			return []*entity.ProductSearchHit{
+				{Product: &entity.Product{ID: "4", Name: "Laptop <Pro>", Description: "A laptop bag included"}, Score: 0.9},
+				{Product: &entity.Product{ID: "2", Name: "Laptop bag"}, Score: 0.5},
+				{Product: &entity.Product{ID: "9", Name: "Bag for laptops"}, Score: 0.5},
+			}, 3, nil
+		})
+
+	page, err := uc.Search(ctx, "LAPTPO, bag a bag", &entity.ProductFilter{Limit: 2, SortBy: "name", Tag: " Sale "})
+	require.NoError(t, err)
+
+	require.Equal(t, [][]string{{"laptpo", "lapt"}, {"bag"}}, searched.Terms)
+	require.Equal(t, "sale", searched.Filter.Tag)
+	require.Empty(t, searched.Filter.SortBy)
+	require.Equal(t, uint64(3), searched.Filter.Limit)
+
+	require.Len(t, page.Hits, 2)
+	require.Equal(t, uint64(3), page.Total)
+	require.Equal(t, "<mark>Laptop</mark> &lt;Pro&gt;", page.Hits[0].Highlights.Name)
+	require.Equal(t, "A <mark>laptop</mark> <mark>bag</mark> included", page.Hits[0].Highlights.Description)
+	require.Empty(t, page.Hits[1].Highlights.Description)
+	require.NotEmpty(t, page.NextCursor)
+
+	repo.EXPECT().Search(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, s *entity.ProductSearch) ([]*entity.ProductSearchHit, uint64, error) {
+			require.Equal(t, &entity.ProductCursor{SortBy: "relevance", Value: "0.5", ID: 2}, s.Filter.After)
+			return nil, 3, nil
+		})
+
+	page, err = uc.Search(ctx, "laptpo bag", &entity.ProductFilter{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Empty(t, page.Hits)
+}
+
+func TestSearchProductsTypos(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo := product(t)
+
+	repo.EXPECT().SearchWords(ctx, "bakcpack").Return([]string{"backpack", "backpacks", "bakery"}, nil)
+	repo.EXPECT().SearchWords(ctx, "chiar").Return([]string{"chair", "chairs", "char", "chia", "choir"}, nil)
+	// Only so many words one typo away are searched for.
+	repo.EXPECT().SearchWords(ctx, "wxyz").Return([]string{"axyz", "bxyz", "cxyz", "dxyz", "exyz", "fxyz"}, nil)
+
+	var searched *entity.ProductSearch
+	repo.EXPECT().Search(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, s *entity.ProductSearch) ([]*entity.ProductSearchHit, uint64, error) {
+			searched = s
+			return []*entity.ProductSearchHit{
+				{Product: &entity.Product{ID: "1", Name: "Oak chair", Description: "Fits a backpack, or a bag"}, Score: 1},
+			}, 1, nil
+		})
+
+	// Words of 3 characters are not looked up.
+	page, err := uc.Search(ctx, "bakcpack chiar bag wxyz", &entity.ProductFilter{})
+	require.NoError(t, err)
+
+	require.Equal(t, [][]string{
+		{"bakcpack", "bakcpa", "backpack"},
+		{"chiar", "chia", "chair", "char"},
+		{"bag"},
+		{"wxyz", "wxy", "axyz", "bxyz", "cxyz", "dxyz", "exyz"},
+	}, searched.Terms)
+	require.Equal(t, "Oak <mark>chair</mark>", page.Hits[0].Highlights.Name)
+	require.Equal(t, "Fits a <mark>backpack</mark>, or a <mark>bag</mark>", page.Hits[0].Highlights.Description)
+
+	repo.EXPECT().SearchWords(ctx, "bakcpack").Return(nil, errInternalServErr)
+
+	_, err = uc.Search(ctx, "bakcpack", &entity.ProductFilter{})
+	require.ErrorIs(t, err, errInternalServErr)
+}
+
+func TestSearchProductsInvalid(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, _ := product(t)
+
+	tooMany := make([]string, usecase.MaxSearchTerms+1)
+	for i := range tooMany {
+		tooMany[i] = "word" + strconv.Itoa(i)
+	}
+
+	for _, query := range []string{"", "a - b", strings.Join(tooMany, " ")} {
+		_, err := uc.Search(ctx, query, &entity.ProductFilter{})
+		require.ErrorIs(t, err, usecase.ErrValidation, query)
+	}
+
+	// A listing cursor is not a search cursor.
+	listing, err := uc.Search(ctx, "bag", &entity.ProductFilter{Cursor: "eyJzIjoiaWQiLCJ2IjoiMjAiLCJpZCI6MjB9"})
+	require.Nil(t, listing)
+	require.ErrorIs(t, err, usecase.ErrInvalidCursor)
+}
+
+func TestSearchProductsSnippet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo := product(t)
+
+	description := strings.Repeat("filler words here ", 20) + "the waterproof backpack " + strings.Repeat("more text ", 30)
+	repo.EXPECT().SearchWords(ctx, "backpack").Return([]string{"backpack"}, nil)
+	repo.EXPECT().Search(ctx, gomock.Any()).
+		Return([]*entity.ProductSearchHit{{Product: &entity.Product{ID: "1", Name: "Backpack", Description: description}, Score: 1}}, uint64(1), nil)
+
+	page, err := uc.Search(ctx, "backpack", &entity.ProductFilter{})
+	require.NoError(t, err)
+
+	snippet := page.Hits[0].Highlights.Description
+	require.True(t, strings.HasPrefix(snippet, "…"), snippet)
+	require.True(t, strings.HasSuffix(snippet, "…"), snippet)
+	require.Contains(t, snippet, "the waterproof <mark>backpack</mark> more")
+}
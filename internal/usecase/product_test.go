@@ -0,0 +1,273 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+func product(t *testing.T) (usecase.ProductUseCase, *MockProductRepository) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	repo := NewMockProductRepository(mockCtl)
+
+	return usecase.NewProductUseCase(repo), repo
+}
+
+func TestProductList(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	products := []*entity.Product{{ID: "1", Price: entity.Money{Amount: 10, Currency: "EUR"}}, {ID: "2", Price: entity.Money{Amount: 20, Currency: "EUR"}}, {ID: "3", Price: entity.Money{Amount: 20, Currency: "EUR"}}}
+
+	// First page: the repository is asked for one row more than the page size.
+	repo.EXPECT().
+		List(context.Background(), &entity.ProductFilter{SortBy: "price", Limit: 3}).
+		Return(products, uint64(5), nil)
+
+	page, err := uc.List(context.Background(), &entity.ProductFilter{SortBy: "price", Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), page.Total)
+	require.Equal(t, products[:2], page.Products)
+	require.NotEmpty(t, page.NextCursor)
+
+	// Second page: the cursor resumes after the last product of the first one.
+	repo.EXPECT().
+		List(context.Background(), &entity.ProductFilter{
+			SortBy: "price",
+			Limit:  3,
+			Cursor: page.NextCursor,
+			After:  &entity.ProductCursor{SortBy: "price", Value: "20", ID: 2},
+		}).
+		Return(products[2:], uint64(5), nil)
+
+	next, err := uc.List(context.Background(), &entity.ProductFilter{SortBy: "price", Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Equal(t, products[2:], next.Products)
+	require.Empty(t, next.NextCursor)
+}
+
+func TestProductListDefaults(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	repo.EXPECT().
+		List(context.Background(), &entity.ProductFilter{SortBy: "id", Limit: 21}).
+		Return(nil, uint64(0), nil)
+
+	page, err := uc.List(context.Background(), &entity.ProductFilter{})
+	require.NoError(t, err)
+	require.Equal(t, []*entity.Product{}, page.Products)
+}
+
+func TestProductListPriceWithoutCurrency(t *testing.T) {
+	t.Parallel()
+
+	uc, _ := product(t)
+
+	minPrice, maxPrice := int64(100), int64(200)
+
+	// Amounts in minor units of different currencies do not compare, so nothing is listed.
+	_, err := uc.List(context.Background(), &entity.ProductFilter{MinPrice: &minPrice, MaxPrice: &maxPrice})
+	require.ErrorIs(t, err, usecase.ErrValidation)
+
+	var ve *usecase.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, []usecase.FieldError{
+		{Field: "min_price", Message: "requires currency"},
+		{Field: "max_price", Message: "requires currency"},
+	}, ve.Fields)
+}
+
+func TestProductListInvalidCursor(t *testing.T) {
+	t.Parallel()
+
+	uc, _ := product(t)
+
+	tests := []struct {
+		name   string
+		filter *entity.ProductFilter
+	}{
+		{
+			name:   "not base64",
+			filter: &entity.ProductFilter{Cursor: "!!!"},
+		},
+		{
+			name:   "other sort column",
+			filter: &entity.ProductFilter{SortBy: "name", Cursor: "eyJzIjoiaWQiLCJ2IjoiIiwiaWQiOjJ9"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := uc.List(context.Background(), tc.filter)
+			require.ErrorIs(t, err, usecase.ErrInvalidCursor)
+		})
+	}
+}
+
+func TestProductUpdateKeepsSKU(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	price := entity.Money{Amount: 100, Currency: "EUR"}
+
+	// A write without a sku keeps the stored one.
+	repo.EXPECT().GetByID(context.Background(), uint64(1), false).Return(&entity.Product{ID: "1", SKU: "SKU-1", Name: "n", Price: price, Version: 2}, nil)
+	repo.EXPECT().Update(context.Background(), &entity.Product{ID: "1", SKU: "SKU-1", Name: "renamed", Price: price, Version: 2}).Return(nil)
+
+	require.NoError(t, uc.Update(context.Background(), &entity.Product{ID: "1", Name: "renamed", Price: price, Version: 2}))
+
+	// A write with one replaces it without a read.
+	repo.EXPECT().Update(context.Background(), &entity.Product{ID: "1", SKU: "SKU-2", Name: "renamed", Price: price, Version: 3}).Return(nil)
+
+	require.NoError(t, uc.Update(context.Background(), &entity.Product{ID: "1", SKU: "SKU-2", Name: "renamed", Price: price, Version: 3}))
+}
+
+func TestProductRestore(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	repo.EXPECT().GetByID(context.Background(), uint64(1), true).Return(&entity.Product{ID: "1", DeletedAt: "2030-01-01 00:00:00"}, nil)
+	repo.EXPECT().Restore(context.Background(), uint64(1), uint64(4)).Return(nil)
+
+	require.NoError(t, uc.Restore(context.Background(), 1, 4))
+
+	repo.EXPECT().GetByID(context.Background(), uint64(2), true).Return(&entity.Product{ID: "2"}, nil)
+	require.ErrorIs(t, uc.Restore(context.Background(), 2, 0), usecase.ErrProductNotDeleted)
+
+	repo.EXPECT().GetByID(context.Background(), uint64(3), true).Return(nil, nil)
+	require.ErrorIs(t, uc.Restore(context.Background(), 3, 0), usecase.ErrProductNotFound)
+}
+
+func TestProductErrorKinds(t *testing.T) {
+	t.Parallel()
+
+	uc, repo := product(t)
+
+	repo.EXPECT().GetByID(context.Background(), uint64(1), false).Return(nil, nil)
+	_, err := uc.GetByID(context.Background(), 1, false)
+	require.ErrorIs(t, err, usecase.ErrProductNotFound)
+	require.ErrorIs(t, err, usecase.ErrNotFound)
+
+	repo.EXPECT().Delete(context.Background(), uint64(2), uint64(0)).Return(repository.ErrProductNotFound)
+	err = uc.Delete(context.Background(), 2, 0)
+	require.ErrorIs(t, err, usecase.ErrNotFound)
+
+	repo.EXPECT().Delete(context.Background(), uint64(3), uint64(1)).Return(&repository.ConflictError{ID: 3, Expected: 1, Actual: 2})
+	err = uc.Delete(context.Background(), 3, 1)
+	require.ErrorIs(t, err, usecase.ErrVersionConflict)
+	require.ErrorIs(t, err, usecase.ErrConflict)
+
+	repo.EXPECT().GetTimeDiff(context.Background(), uint64(4)).Return(nil, nil)
+	_, err = uc.GetTimeDiff(context.Background(), 4)
+	require.ErrorIs(t, err, usecase.ErrNotFound)
+
+	repo.EXPECT().GetByDate(context.Background(), uint64(5), gomock.Any()).Return(nil, nil)
+	_, err = uc.GetByDate(context.Background(), 5, &entity.ReferenceDate{DateTime: "2000-01-01 00:00:00"})
+	require.ErrorIs(t, err, usecase.ErrProductVersionNotFound)
+
+	_, err = uc.List(context.Background(), &entity.ProductFilter{Cursor: "!!!"})
+	require.ErrorIs(t, err, usecase.ErrValidation)
+}
+
+func TestProductValidation(t *testing.T) {
+	t.Parallel()
+
+	uc, _ := product(t)
+
+	tests := []struct {
+		name   string
+		create bool
+		p      *entity.Product
+		fields []string
+	}{
+		{
+			name:   "empty name and negative price",
+			create: true,
+			p:      &entity.Product{Name: " ", Price: entity.Money{Amount: -1, Currency: "EUR"}},
+			fields: []string{"name", "price.amount"},
+		},
+		{
+			name:   "read-only fields on create",
+			create: true,
+			p:      &entity.Product{ID: "1", Name: "n", Price: entity.Money{Currency: "EUR"}, CreatedAt: "2020-01-01 00:00:00"},
+			fields: []string{"id", "created_at"},
+		},
+		{
+			name:   "too long",
+			create: true,
+			p:      &entity.Product{Name: strings.Repeat("ü", usecase.MaxProductNameLen+1), Description: strings.Repeat("d", usecase.MaxProductDescriptionLen+1)},
+			fields: []string{"name", "description", "price.currency"},
+		},
+		{
+			name:   "update without id",
+			create: false,
+			p:      &entity.Product{Name: "n", Price: entity.Money{Currency: "EUR"}, UpdatedAt: "2020-01-01 00:00:00"},
+			fields: []string{"id", "updated_at"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var err error
+			if tc.create {
+				_, err = uc.Create(context.Background(), tc.p)
+			} else {
+				err = uc.Update(context.Background(), tc.p)
+			}
+
+			var ve *usecase.ValidationError
+			require.ErrorAs(t, err, &ve)
+			require.ErrorIs(t, err, usecase.ErrValidation)
+
+			fields := make([]string, 0, len(ve.Fields))
+			for _, f := range ve.Fields {
+				fields = append(fields, f.Field)
+			}
+			require.Equal(t, tc.fields, fields)
+		})
+	}
+}
+
+func TestProductChangeValidation(t *testing.T) {
+	t.Parallel()
+
+	uc, _ := product(t)
+
+	ctx := entity.WithChange(context.Background(), entity.Change{
+		Actor:     strings.Repeat("a", entity.MaxChangeActorLen+1),
+		RequestID: strings.Repeat("r", entity.MaxChangeRequestIDLen+1),
+	})
+
+	err := uc.Delete(ctx, 1, 0)
+
+	var ve *usecase.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, []usecase.FieldError{
+		{Field: "actor", Message: "must be at most 255 characters"},
+		{Field: "request_id", Message: "must be at most 255 characters"},
+	}, ve.Fields)
+}
@@ -0,0 +1,330 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+// MaxLocaleLen matches the VARCHAR column of translation locales.
+const MaxLocaleLen = 35
+
+// _translationFillBatch is how many missing translations one FillMissing call makes at most.
+const _translationFillBatch = 50
+
+// A failed machine translation is retried after _translationRetryMin, doubled with every further
+// failure up to _translationRetryMax.
+const (
+	_translationRetryMin = time.Minute
+	_translationRetryMax = 24 * time.Hour
+)
+
+// _machineSourceLanguage lets the translation service detect the language products are written in.
+const _machineSourceLanguage = "auto"
+
+var _localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+// ProductTranslationUseCase keeps the names and descriptions of products in other locales than the
+// default one they are written in. Translations are written by people or, for the configured locales,
+// filled in by the translation use case; a machine translation never replaces a human one.
+type ProductTranslationUseCase interface {
+	// List returns the translations of a live product ordered by locale.
+	List(ctx context.Context, productID uint64) ([]*entity.ProductTranslation, error)
+	// Put stores the human translation t of a live product and returns it as stored.
+	Put(ctx context.Context, t *entity.ProductTranslation) (*entity.ProductTranslation, error)
+	Delete(ctx context.Context, productID uint64, locale string) error
+	// Localize replaces the names and descriptions of products with their translation into the first of
+	// locales, in order of preference, that they have one for. A locale with a region also matches the
+	// translation into its bare language, and the default locale ends the search, as products are written in it.
+	Localize(ctx context.Context, products []*entity.Product, locales []string) error
+	// FillMissing machine-translates a batch of live products into the configured locales they have no
+	// translation into, or only a machine translation of an older version, and returns how many it stored.
+	// A failed translation does not hold back the others and is retried by a later call, after a wait
+	// that grows with its failures.
+	FillMissing(ctx context.Context) (int, error)
+}
+
+type productTranslationUseCase struct {
+	repo          repository.ProductTranslationRepository
+	products      repository.ProductRepository
+	translator    Translation
+	defaultLocale string
+	locales       []string
+}
+
+// NewProductTranslationUseCase -. The translator may be nil, and locales empty, when translations are only
+// written by people; invalid locales and the default one are not filled in. A translator made with
+// SkipHistory keeps machine translations out of the translation history.
+func NewProductTranslationUseCase(r repository.ProductTranslationRepository, products repository.ProductRepository,
+	translator Translation, defaultLocale string, locales []string,
+) ProductTranslationUseCase {
+	uc := &productTranslationUseCase{repo: r, products: products, translator: translator}
+	uc.defaultLocale, _ = normalizeLocale(defaultLocale)
+	seen := map[string]bool{uc.defaultLocale: true}
+	for _, locale := range locales {
+		locale, ok := normalizeLocale(locale)
+		if ok && !seen[locale] {
+			seen[locale] = true
+			uc.locales = append(uc.locales, locale)
+		}
+	}
+	return uc
+}
+
+func (uc *productTranslationUseCase) List(ctx context.Context, productID uint64) ([]*entity.ProductTranslation, error) {
+	if err := uc.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListByProduct(ctx, productID)
+}
+
+func (uc *productTranslationUseCase) Put(ctx context.Context, t *entity.ProductTranslation) (*entity.ProductTranslation, error) {
+	var fe fieldErrors
+	locale := uc.validateLocale(&fe, t.Locale)
+	validateName(&fe, t.Name)
+	validateDescription(&fe, t.Description)
+	if err := fe.err(); err != nil {
+		return nil, err
+	}
+	if err := uc.checkProduct(ctx, t.ProductID); err != nil {
+		return nil, err
+	}
+
+	stored := &entity.ProductTranslation{
+		ProductID:   t.ProductID,
+		Locale:      locale,
+		Name:        t.Name,
+		Description: t.Description,
+		Source:      entity.TranslationSourceHuman,
+	}
+	if _, err := uc.repo.Save(ctx, stored); err != nil {
+		return nil, err
+	}
+	return uc.repo.Get(ctx, stored.ProductID, stored.Locale)
+}
+
+func (uc *productTranslationUseCase) Delete(ctx context.Context, productID uint64, locale string) error {
+	var fe fieldErrors
+	locale = uc.validateLocale(&fe, locale)
+	if err := fe.err(); err != nil {
+		return err
+	}
+	if err := uc.checkProduct(ctx, productID); err != nil {
+		return err
+	}
+	deleted, err := uc.repo.Delete(ctx, productID, locale)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrTranslationNotFound
+	}
+	return nil
+}
+
+func (uc *productTranslationUseCase) Localize(ctx context.Context, products []*entity.Product, locales []string) error {
+	candidates := uc.candidates(locales)
+	if len(products) == 0 || len(candidates) == 0 {
+		return nil
+	}
+	ids := make([]uint64, 0, len(products))
+	for _, p := range products {
+		if id, err := strconv.ParseUint(p.ID, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	translations, err := uc.repo.ListForProducts(ctx, ids, candidates)
+	if err != nil {
+		return err
+	}
+
+	byProduct := make(map[string]map[string]*entity.ProductTranslation, len(products))
+	for _, t := range translations {
+		id := strconv.FormatUint(t.ProductID, 10)
+		if byProduct[id] == nil {
+			byProduct[id] = map[string]*entity.ProductTranslation{}
+		}
+		byProduct[id][t.Locale] = t
+	}
+	for _, p := range products {
+		for _, locale := range candidates {
+			if t, ok := byProduct[p.ID][locale]; ok {
+				p.Name, p.Description, p.Locale = t.Name, t.Description, t.Locale
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (uc *productTranslationUseCase) FillMissing(ctx context.Context) (int, error) {
+	if uc.translator == nil || len(uc.locales) == 0 {
+		return 0, nil
+	}
+	missing, err := uc.repo.Missing(ctx, uc.locales, _translationFillBatch)
+	if err != nil {
+		return 0, fmt.Errorf("ProductTranslationUseCase - FillMissing - uc.repo.Missing: %w", err)
+	}
+
+	var n int
+	var errs []error
+	products := map[uint64]*entity.Product{}
+	for _, m := range missing {
+		p, ok := products[m.ProductID]
+		if !ok {
+			p, err = uc.products.GetByID(ctx, m.ProductID, false)
+			if err != nil {
+				return n, fmt.Errorf("ProductTranslationUseCase - FillMissing - uc.products.GetByID: %w", err)
+			}
+			products[m.ProductID] = p
+		}
+		if p == nil {
+			// Deleted since it was found missing.
+			continue
+		}
+		t, err := uc.machineTranslate(ctx, p, m.Locale)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("product %d into %s: %w", m.ProductID, m.Locale, err))
+			retryAt := time.Now().Add(translationRetryDelay(m.Attempts + 1))
+			if err := uc.repo.RecordFailure(ctx, m.ProductID, m.Locale, retryAt); err != nil {
+				return n, fmt.Errorf("ProductTranslationUseCase - FillMissing - uc.repo.RecordFailure: %w", err)
+			}
+			continue
+		}
+		saved, err := uc.repo.Save(ctx, t)
+		if err != nil {
+			return n, fmt.Errorf("ProductTranslationUseCase - FillMissing - uc.repo.Save: %w", err)
+		}
+		if saved {
+			n++
+		}
+		if m.Attempts > 0 {
+			if err := uc.repo.ClearFailures(ctx, m.ProductID, m.Locale); err != nil {
+				return n, fmt.Errorf("ProductTranslationUseCase - FillMissing - uc.repo.ClearFailures: %w", err)
+			}
+		}
+	}
+	return n, errors.Join(errs...)
+}
+
+// translationRetryDelay is how long a machine translation waits after its attempts-th failure.
+func translationRetryDelay(attempts int) time.Duration {
+	delay := _translationRetryMin
+	for i := 1; i < attempts && delay < _translationRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > _translationRetryMax {
+		return _translationRetryMax
+	}
+	return delay
+}
+
+// machineTranslate translates the name and description of p into locale.
+func (uc *productTranslationUseCase) machineTranslate(ctx context.Context, p *entity.Product, locale string) (*entity.ProductTranslation, error) {
+	t := &entity.ProductTranslation{
+		Locale:        locale,
+		Source:        entity.TranslationSourceMachine,
+		SourceVersion: p.Version,
+	}
+	t.ProductID, _ = strconv.ParseUint(p.ID, 10, 64)
+	for _, field := range []struct{ original, translation *string }{
+		{&p.Name, &t.Name},
+		{&p.Description, &t.Description},
+	} {
+		if *field.original == "" {
+			continue
+		}
+		translated, err := uc.translator.Translate(ctx, entity.Translation{
+			Source:      _machineSourceLanguage,
+			Destination: locale,
+			Original:    *field.original,
+		})
+		if err != nil {
+			return nil, err
+		}
+		*field.translation = translated.Translation
+	}
+	return t, nil
+}
+
+// candidates lists the locales Localize looks for in order: each valid locale of locales followed by its
+// bare language, up to the default locale or its language.
+func (uc *productTranslationUseCase) candidates(locales []string) []string {
+	var candidates []string
+	seen := map[string]bool{}
+	for _, locale := range locales {
+		locale, ok := normalizeLocale(locale)
+		if !ok {
+			continue
+		}
+		for _, c := range []string{locale, baseLanguage(locale)} {
+			if c == uc.defaultLocale || c == baseLanguage(uc.defaultLocale) {
+				return candidates
+			}
+			if !seen[c] {
+				seen[c] = true
+				candidates = append(candidates, c)
+			}
+		}
+	}
+	return candidates
+}
+
+func (uc *productTranslationUseCase) checkProduct(ctx context.Context, id uint64) error {
+	p, err := uc.products.GetByID(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// validateLocale checks the locale of a translation and returns it normalized.
+func (uc *productTranslationUseCase) validateLocale(fe *fieldErrors, locale string) string {
+	normalized, ok := normalizeLocale(locale)
+	switch {
+	case strings.TrimSpace(locale) == "":
+		fe.add("locale", "is required")
+	case len(locale) > MaxLocaleLen:
+		fe.add("locale", "must be at most "+strconv.Itoa(MaxLocaleLen)+" characters")
+	case !ok:
+		fe.add("locale", "must be a language tag such as de or pt-BR")
+	case normalized == uc.defaultLocale:
+		fe.add("locale", "is the locale products are written in")
+	}
+	return normalized
+}
+
+// normalizeLocale returns a language tag in its usual case, such as "pt-BR" or "zh-Hant", and
+// whether it is one.
+func normalizeLocale(locale string) (string, bool) {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if len(locale) > MaxLocaleLen || !_localePattern.MatchString(locale) {
+		return "", false
+	}
+	subtags := strings.Split(strings.ToLower(locale), "-")
+	for i := 1; i < len(subtags); i++ {
+		switch len(subtags[i]) {
+		case 2:
+			subtags[i] = strings.ToUpper(subtags[i])
+		case 4:
+			subtags[i] = strings.ToUpper(subtags[i][:1]) + subtags[i][1:]
+		}
+	}
+	return strings.Join(subtags, "-"), true
+}
+
+// baseLanguage is the language subtag of a normalized locale.
+func baseLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	return language
+}
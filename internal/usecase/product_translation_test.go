@@ -0,0 +1,218 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+)
+
+type productTranslationMocks struct {
+	repo       *MockProductTranslationRepository
+	products   *MockProductRepository
+	translator *MockTranslation
+}
+
+func productTranslation(t *testing.T, locales ...string) (usecase.ProductTranslationUseCase, productTranslationMocks) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	m := productTranslationMocks{
+		repo:       NewMockProductTranslationRepository(mockCtl),
+		products:   NewMockProductRepository(mockCtl),
+		translator: NewMockTranslation(mockCtl),
+	}
+
+	return usecase.NewProductTranslationUseCase(m.repo, m.products, m.translator, "en", locales), m
+}
+
+func TestPutProductTranslation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, m := productTranslation(t)
+
+	stored := &entity.ProductTranslation{ProductID: 1, Locale: "pt-BR", Name: "Mochila", Source: entity.TranslationSourceHuman}
+	m.products.EXPECT().GetByID(ctx, uint64(1), false).Return(&entity.Product{ID: "1"}, nil)
+	m.repo.EXPECT().Save(ctx, &entity.ProductTranslation{ProductID: 1, Locale: "pt-BR", Name: "Mochila", Source: entity.TranslationSourceHuman}).
+		Return(true, nil)
+	m.repo.EXPECT().Get(ctx, uint64(1), "pt-BR").Return(stored, nil)
+
+	res, err := uc.Put(ctx, &entity.ProductTranslation{ProductID: 1, Locale: "PT_br", Name: "Mochila", Source: entity.TranslationSourceMachine})
+	require.NoError(t, err)
+	require.Equal(t, stored, res)
+}
+
+func TestPutProductTranslationInvalid(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, _ := productTranslation(t)
+
+	tests := []struct {
+		name string
+		t    *entity.ProductTranslation
+	}{
+		{name: "no locale", t: &entity.ProductTranslation{ProductID: 1, Name: "Mochila"}},
+		{name: "malformed locale", t: &entity.ProductTranslation{ProductID: 1, Locale: "de/AT", Name: "Mochila"}},
+		{name: "default locale", t: &entity.ProductTranslation{ProductID: 1, Locale: "EN", Name: "Backpack"}},
+		{name: "no name", t: &entity.ProductTranslation{ProductID: 1, Locale: "de"}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := uc.Put(ctx, tc.t)
+			require.ErrorIs(t, err, usecase.ErrValidation)
+		})
+	}
+}
+
+func TestDeleteProductTranslation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, m := productTranslation(t)
+
+	m.products.EXPECT().GetByID(ctx, uint64(1), false).Return(&entity.Product{ID: "1"}, nil).Times(2)
+	m.repo.EXPECT().Delete(ctx, uint64(1), "de").Return(true, nil)
+	m.repo.EXPECT().Delete(ctx, uint64(1), "fr").Return(false, nil)
+
+	require.NoError(t, uc.Delete(ctx, 1, "de"))
+	require.ErrorIs(t, uc.Delete(ctx, 1, "fr"), usecase.ErrTranslationNotFound)
+}
+
+func TestLocalizeProducts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, m := productTranslation(t)
+
+	// The default language ends the candidates, as products are written in it.
+	m.repo.EXPECT().ListForProducts(ctx, []uint64{1, 2, 3}, []string{"de-AT", "de", "fr", "en-GB"}).
+		Return([]*entity.ProductTranslation{
+			{ProductID: 1, Locale: "fr", Name: "Sac", Description: "Un sac"},
+			{ProductID: 1, Locale: "de", Name: "Tasche", Description: "Eine Tasche"},
+			{ProductID: 2, Locale: "fr", Name: "Sac à dos"},
+		}, nil)
+
+	products := []*entity.Product{
+		{ID: "1", Name: "Bag", Description: "A bag"},
+		{ID: "2", Name: "Backpack", Description: "A backpack"},
+		{ID: "3", Name: "Box"},
+	}
+	require.NoError(t, uc.Localize(ctx, products, []string{"de-at", "*", "fr", "en-GB", "it"}))
+
+	require.Equal(t, &entity.Product{ID: "1", Name: "Tasche", Description: "Eine Tasche", Locale: "de"}, products[0])
+	require.Equal(t, &entity.Product{ID: "2", Name: "Sac à dos", Locale: "fr"}, products[1])
+	require.Equal(t, &entity.Product{ID: "3", Name: "Box"}, products[2])
+
+	// Nothing is looked up for the default locale.
+	require.NoError(t, uc.Localize(ctx, products, []string{"EN", "de"}))
+	require.NoError(t, uc.Localize(ctx, products, nil))
+}
+
+func TestFillMissingProductTranslations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, m := productTranslation(t, "de", "EN", "pt_br", "de")
+
+	m.repo.EXPECT().Missing(ctx, []string{"de", "pt-BR"}, gomock.Any()).Return([]*entity.ProductLocale{
+		{ProductID: 1, Locale: "de"},
+		{ProductID: 1, Locale: "pt-BR"},
+		{ProductID: 2, Locale: "de"},
+		{ProductID: 3, Locale: "de", Attempts: 2},
+	}, nil)
+	m.products.EXPECT().GetByID(ctx, uint64(1), false).Return(&entity.Product{ID: "1", Name: "Bag", Version: 4}, nil)
+	m.products.EXPECT().GetByID(ctx, uint64(2), false).Return(nil, nil)
+	m.products.EXPECT().GetByID(ctx, uint64(3), false).Return(&entity.Product{ID: "3", Name: "Box", Description: "A box", Version: 1}, nil)
+
+	m.translator.EXPECT().Translate(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, t entity.Translation) (entity.Translation, error) {
+			if t.Destination == "pt-BR" {
+				return entity.Translation{}, errors.New("unsupported language")
+			}
+			t.Translation = "[" + t.Destination + "] " + t.Original
+			return t, nil
+		}).Times(4)
+
+	m.repo.EXPECT().RecordFailure(ctx, uint64(1), "pt-BR", gomock.Any()).Return(nil)
+	m.repo.EXPECT().Save(ctx, &entity.ProductTranslation{
+		ProductID: 1, Locale: "de", Name: "[de] Bag", Source: entity.TranslationSourceMachine, SourceVersion: 4,
+	}).Return(true, nil)
+	// A human translation stored in the meantime is kept.
+	m.repo.EXPECT().Save(ctx, &entity.ProductTranslation{
+		ProductID: 3, Locale: "de", Name: "[de] Box", Description: "[de] A box", Source: entity.TranslationSourceMachine, SourceVersion: 1,
+	}).Return(false, nil)
+	// Product 3 failed before; its failures are forgotten once it is translated.
+	m.repo.EXPECT().ClearFailures(ctx, uint64(3), "de").Return(nil)
+
+	n, err := uc.FillMissing(ctx)
+	require.Equal(t, 1, n)
+	require.ErrorContains(t, err, "product 1 into pt-BR: unsupported language")
+}
+
+func TestFillMissingProductTranslationsAllFailing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, m := productTranslation(t, "de")
+
+	// A batch made only of failures: each is put off for longer the more often it failed, so the next
+	// batches reach the pairs behind it.
+	missing := make([]*entity.ProductLocale, 50)
+	for i := range missing {
+		missing[i] = &entity.ProductLocale{ProductID: uint64(i + 1), Locale: "de", Attempts: i % 3}
+	}
+	m.repo.EXPECT().Missing(ctx, []string{"de"}, uint64(50)).Return(missing, nil)
+	m.products.EXPECT().GetByID(ctx, gomock.Any(), false).
+		DoAndReturn(func(_ context.Context, id uint64, _ bool) (*entity.Product, error) {
+			return &entity.Product{ID: strconv.FormatUint(id, 10), Name: "Bag", Version: 1}, nil
+		}).Times(50)
+	m.translator.EXPECT().Translate(ctx, gomock.Any()).Return(entity.Translation{}, errors.New("quota exceeded")).Times(50)
+
+	delays := map[uint64]time.Duration{}
+	start := time.Now()
+	m.repo.EXPECT().RecordFailure(ctx, gomock.Any(), "de", gomock.Any()).
+		DoAndReturn(func(_ context.Context, id uint64, _ string, retryAt time.Time) error {
+			delays[id] = retryAt.Sub(start).Round(time.Minute)
+			return nil
+		}).Times(50)
+
+	n, err := uc.FillMissing(ctx)
+	require.Zero(t, n)
+	require.ErrorContains(t, err, "product 50 into de: quota exceeded")
+	require.Len(t, delays, 50)
+	require.Equal(t, time.Minute, delays[1])
+	require.Equal(t, 2*time.Minute, delays[2])
+	require.Equal(t, 4*time.Minute, delays[3])
+}
+
+func TestFillMissingProductTranslationsDisabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, _ := productTranslation(t)
+
+	n, err := uc.FillMissing(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	uc = usecase.NewProductTranslationUseCase(nil, nil, nil, "en", []string{"de"})
+	n, err = uc.FillMissing(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
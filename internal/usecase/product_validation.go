@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+// Limits of product fields; name and sku match their VARCHAR columns.
+const (
+	MaxProductNameLen        = 255
+	MaxProductDescriptionLen = 2000
+	MaxProductSKULen         = 64
+)
+
+// FieldError describes why one field of a payload is invalid.
+type FieldError struct {
+	Field   string `json:"field" example:"name"`
+	Message string `json:"message" example:"is required"`
+}
+
+// ValidationError lists every invalid field of a payload; it is of kind ErrValidation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+" "+f.Message)
+	}
+	return ErrValidation.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+type fieldErrors []FieldError
+
+func (fe *fieldErrors) add(field, msg string) {
+	*fe = append(*fe, FieldError{Field: field, Message: msg})
+}
+
+func (fe fieldErrors) err() error {
+	if len(fe) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fe}
+}
+
+// validatePriceBounds requires the currency of a filter bounding the price, whose amounts in minor
+// units only compare within one currency.
+func validatePriceBounds(fe *fieldErrors, f *entity.ProductFilter) {
+	if f.Currency != "" {
+		return
+	}
+	if f.MinPrice != nil {
+		fe.add("min_price", "requires currency")
+	}
+	if f.MaxPrice != nil {
+		fe.add("max_price", "requires currency")
+	}
+}
+
+// validateProduct checks a payload for Create (create) or Update. The id is assigned by the store
+// on create and identifies the product on update; timestamps are always maintained by the store.
+func validateProduct(p *entity.Product, create bool) error {
+	var fe fieldErrors
+	if create && p.ID != "" {
+		fe.add("id", "is read-only")
+	}
+	if !create {
+		if _, err := strconv.ParseUint(p.ID, 10, 64); err != nil {
+			fe.add("id", "must be a product id")
+		}
+	}
+	if utf8.RuneCountInString(p.SKU) > MaxProductSKULen {
+		fe.add("sku", "must be at most "+strconv.Itoa(MaxProductSKULen)+" characters")
+	}
+	validateName(&fe, p.Name)
+	validateDescription(&fe, p.Description)
+	validatePrice(&fe, p.Price)
+	if p.EffectiveFrom != "" {
+		if _, err := time.Parse(entity.DateTimeLayout, p.EffectiveFrom); err != nil {
+			fe.add("effective_from", "must be formatted as "+entity.DateTimeLayout)
+		}
+	}
+	if p.CreatedAt != "" {
+		fe.add("created_at", "is read-only")
+	}
+	if p.UpdatedAt != "" {
+		fe.add("updated_at", "is read-only")
+	}
+	if p.DeletedAt != "" {
+		fe.add("deleted_at", "is read-only")
+	}
+	return fe.err()
+}
+
+// validateScheduledFields applies the product rules to the fields a scheduled change sets.
+func validateScheduledFields(c *entity.ScheduledChange) error {
+	var fe fieldErrors
+	if c.Name != nil {
+		validateName(&fe, *c.Name)
+	}
+	if c.Description != nil {
+		validateDescription(&fe, *c.Description)
+	}
+	if c.Price != nil {
+		validatePrice(&fe, *c.Price)
+	}
+	return fe.err()
+}
+
+// validateChange checks the entity.Change a write carries in ctx against the columns it is stored in.
+func validateChange(ctx context.Context) error {
+	c := entity.ChangeFromContext(ctx)
+	var fe fieldErrors
+	if utf8.RuneCountInString(c.Actor) > entity.MaxChangeActorLen {
+		fe.add("actor", "must be at most "+strconv.Itoa(entity.MaxChangeActorLen)+" characters")
+	}
+	if utf8.RuneCountInString(c.Reason) > entity.MaxChangeReasonLen {
+		fe.add("reason", "must be at most "+strconv.Itoa(entity.MaxChangeReasonLen)+" characters")
+	}
+	if utf8.RuneCountInString(c.RequestID) > entity.MaxChangeRequestIDLen {
+		fe.add("request_id", "must be at most "+strconv.Itoa(entity.MaxChangeRequestIDLen)+" characters")
+	}
+	return fe.err()
+}
+
+func validateName(fe *fieldErrors, name string) {
+	switch {
+	case strings.TrimSpace(name) == "":
+		fe.add("name", "is required")
+	case utf8.RuneCountInString(name) > MaxProductNameLen:
+		fe.add("name", "must be at most "+strconv.Itoa(MaxProductNameLen)+" characters")
+	}
+}
+
+func validateDescription(fe *fieldErrors, description string) {
+	if utf8.RuneCountInString(description) > MaxProductDescriptionLen {
+		fe.add("description", "must be at most "+strconv.Itoa(MaxProductDescriptionLen)+" characters")
+	}
+}
+
+func validatePrice(fe *fieldErrors, price entity.Money) {
+	if price.Amount < 0 {
+		fe.add("price.amount", "must not be negative")
+	}
+	validateCurrency(fe, "price.currency", price.Currency)
+}
+
+func validateCurrency(fe *fieldErrors, field, code string) {
+	if _, ok := entity.CurrencyExponent(code); !ok {
+		fe.add(field, "must be a supported ISO 4217 code")
+	}
+}
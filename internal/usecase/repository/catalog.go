@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+//go:generate mockgen -source=catalog.go -destination=../mocks_catalog_test.go -package=usecase_test
+
+var (
+	// ErrCategoryHasChildren is returned by DeleteCategory for a category with subcategories.
+	ErrCategoryHasChildren = errors.New("category has subcategories")
+	// ErrDuplicateTag is returned by CreateTag and RenameTag when another tag has the name.
+	ErrDuplicateTag = errors.New("tag already exists")
+)
+
+// CatalogRepository stores the category tree, tags and their assignment to products.
+// Getters return nil for unknown ids like ProductRepository.GetByID; assigning is idempotent.
+type CatalogRepository interface {
+	// InTx runs fn with a repository whose reads and writes share one transaction, committed when fn returns nil.
+	InTx(ctx context.Context, fn func(repo CatalogRepository) error) error
+	CreateCategory(ctx context.Context, c *entity.Category) (uint64, error)
+	GetCategory(ctx context.Context, id uint64) (*entity.Category, error)
+	// LockCategory is GetCategory locking the category until the transaction of InTx ends, so that
+	// the parents of a category do not change while they are checked.
+	LockCategory(ctx context.Context, id uint64) (*entity.Category, error)
+	// ListCategories returns every category ordered by id, so that parents of a category come before it
+	// unless it was moved.
+	ListCategories(ctx context.Context) ([]*entity.Category, error)
+	UpdateCategory(ctx context.Context, c *entity.Category) error
+	// DeleteCategory reports whether the category existed; its product assignments go with it.
+	DeleteCategory(ctx context.Context, id uint64) (bool, error)
+	ProductCategories(ctx context.Context, productID uint64) ([]*entity.Category, error)
+	AssignCategory(ctx context.Context, productID, categoryID uint64) error
+	UnassignCategory(ctx context.Context, productID, categoryID uint64) (bool, error)
+
+	ListTags(ctx context.Context) ([]*entity.Tag, error)
+	GetTag(ctx context.Context, id uint64) (*entity.Tag, error)
+	GetTagByName(ctx context.Context, name string) (*entity.Tag, error)
+	CreateTag(ctx context.Context, name string) (uint64, error)
+	RenameTag(ctx context.Context, id uint64, name string) error
+	// DeleteTag reports whether the tag existed; it is removed from every product.
+	DeleteTag(ctx context.Context, id uint64) (bool, error)
+	ProductTags(ctx context.Context, productID uint64) ([]*entity.Tag, error)
+	TagProduct(ctx context.Context, productID, tagID uint64) error
+	UntagProduct(ctx context.Context, productID, tagID uint64) (bool, error)
+}
+
+type catalogRepo struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+const (
+	_categoryColumns = "id, parent_id, name, created_at"
+	_tagColumns      = "id, name"
+)
+
+func NewCatalogRepository(db *sql.DB) CatalogRepository {
+	return &catalogRepo{db: db}
+}
+
+func (r *catalogRepo) InTx(ctx context.Context, fn func(repo CatalogRepository) error) (err error) {
+	if r.tx != nil {
+		return fn(r)
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+	return fn(&catalogRepo{db: r.db, tx: tx})
+}
+
+// conn is the transaction of InTx, if any, else the pool.
+func (r *catalogRepo) conn() mysqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+func (r *catalogRepo) CreateCategory(ctx context.Context, c *entity.Category) (uint64, error) {
+	result, err := r.conn().ExecContext(ctx, `INSERT INTO categories (parent_id, name) VALUES (?, ?)`, c.ParentID, c.Name)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return uint64(id), err
+}
+func (r *catalogRepo) GetCategory(ctx context.Context, id uint64) (*entity.Category, error) {
+	return r.getCategory(ctx, `SELECT `+_categoryColumns+` FROM categories WHERE id = ?`, id)
+}
+func (r *catalogRepo) LockCategory(ctx context.Context, id uint64) (*entity.Category, error) {
+	return r.getCategory(ctx, `SELECT `+_categoryColumns+` FROM categories WHERE id = ? FOR UPDATE`, id)
+}
+func (r *catalogRepo) getCategory(ctx context.Context, query string, id uint64) (*entity.Category, error) {
+	c, err := scanMySQLCategory(r.conn().QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+func (r *catalogRepo) ListCategories(ctx context.Context) ([]*entity.Category, error) {
+	return r.queryCategories(ctx, `SELECT `+_categoryColumns+` FROM categories ORDER BY id`)
+}
+func (r *catalogRepo) UpdateCategory(ctx context.Context, c *entity.Category) error {
+	_, err := r.conn().ExecContext(ctx, `UPDATE categories SET parent_id = ?, name = ? WHERE id = ?`, c.ParentID, c.Name, c.ID)
+	return err
+}
+func (r *catalogRepo) DeleteCategory(ctx context.Context, id uint64) (bool, error) {
+	deleted, err := r.deleteRows(ctx, `DELETE FROM categories WHERE id = ?`, id)
+	if isReferenced(err) {
+		return false, fmt.Errorf("%w: %v", ErrCategoryHasChildren, err)
+	}
+	return deleted, err
+}
+func (r *catalogRepo) ProductCategories(ctx context.Context, productID uint64) ([]*entity.Category, error) {
+	query := `SELECT c.id, c.parent_id, c.name, c.created_at FROM categories c JOIN product_categories pc ON pc.category_id = c.id WHERE pc.product_id = ? ORDER BY c.id`
+	return r.queryCategories(ctx, query, productID)
+}
+func (r *catalogRepo) AssignCategory(ctx context.Context, productID, categoryID uint64) error {
+	_, err := r.conn().ExecContext(ctx, `INSERT INTO product_categories (product_id, category_id) VALUES (?, ?) ON DUPLICATE KEY UPDATE product_id = product_id`, productID, categoryID)
+	return err
+}
+func (r *catalogRepo) UnassignCategory(ctx context.Context, productID, categoryID uint64) (bool, error) {
+	return r.deleteRows(ctx, `DELETE FROM product_categories WHERE product_id = ? AND category_id = ?`, productID, categoryID)
+}
+func (r *catalogRepo) ListTags(ctx context.Context) ([]*entity.Tag, error) {
+	return r.queryTags(ctx, `SELECT `+_tagColumns+` FROM tags ORDER BY name`)
+}
+func (r *catalogRepo) GetTag(ctx context.Context, id uint64) (*entity.Tag, error) {
+	t := &entity.Tag{}
+	err := r.conn().QueryRowContext(ctx, `SELECT `+_tagColumns+` FROM tags WHERE id = ?`, id).Scan(&t.ID, &t.Name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+func (r *catalogRepo) GetTagByName(ctx context.Context, name string) (*entity.Tag, error) {
+	t := &entity.Tag{}
+	err := r.conn().QueryRowContext(ctx, `SELECT `+_tagColumns+` FROM tags WHERE name = ?`, name).Scan(&t.ID, &t.Name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+func (r *catalogRepo) CreateTag(ctx context.Context, name string) (uint64, error) {
+	result, err := r.conn().ExecContext(ctx, `INSERT INTO tags (name) VALUES (?)`, name)
+	if isUniqueViolation(err) {
+		return 0, fmt.Errorf("%w: %v", ErrDuplicateTag, err)
+	}
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return uint64(id), err
+}
+func (r *catalogRepo) RenameTag(ctx context.Context, id uint64, name string) error {
+	_, err := r.conn().ExecContext(ctx, `UPDATE tags SET name = ? WHERE id = ?`, name, id)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: %v", ErrDuplicateTag, err)
+	}
+	return err
+}
+func (r *catalogRepo) DeleteTag(ctx context.Context, id uint64) (bool, error) {
+	return r.deleteRows(ctx, `DELETE FROM tags WHERE id = ?`, id)
+}
+func (r *catalogRepo) ProductTags(ctx context.Context, productID uint64) ([]*entity.Tag, error) {
+	return r.queryTags(ctx, `SELECT t.id, t.name FROM tags t JOIN product_tags pt ON pt.tag_id = t.id WHERE pt.product_id = ? ORDER BY t.name`, productID)
+}
+func (r *catalogRepo) TagProduct(ctx context.Context, productID, tagID uint64) error {
+	_, err := r.conn().ExecContext(ctx, `INSERT INTO product_tags (product_id, tag_id) VALUES (?, ?) ON DUPLICATE KEY UPDATE product_id = product_id`, productID, tagID)
+	return err
+}
+func (r *catalogRepo) UntagProduct(ctx context.Context, productID, tagID uint64) (bool, error) {
+	return r.deleteRows(ctx, `DELETE FROM product_tags WHERE product_id = ? AND tag_id = ?`, productID, tagID)
+}
+
+// deleteRows runs a DELETE and reports whether it removed a row.
+func (r *catalogRepo) deleteRows(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	result, err := r.conn().ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+func (r *catalogRepo) queryCategories(ctx context.Context, query string, args ...interface{}) ([]*entity.Category, error) {
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	categories := []*entity.Category{}
+	for rows.Next() {
+		c, err := scanMySQLCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+func (r *catalogRepo) queryTags(ctx context.Context, query string, args ...interface{}) ([]*entity.Tag, error) {
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tags := []*entity.Tag{}
+	for rows.Next() {
+		t := &entity.Tag{}
+		if err := rows.Scan(&t.ID, &t.Name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+func scanMySQLCategory(row rowScanner) (*entity.Category, error) {
+	c := &entity.Category{}
+	var parentID sql.NullInt64
+	if err := row.Scan(&c.ID, &parentID, &c.Name, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		id := uint64(parentID.Int64)
+		c.ParentID = &id
+	}
+	return c, nil
+}
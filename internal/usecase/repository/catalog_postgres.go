@@ -0,0 +1,465 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/pkg/postgres"
+)
+
+// CatalogPostgresRepo -.
+type CatalogPostgresRepo struct {
+	*postgres.Postgres
+	tx pgx.Tx
+}
+
+// NewCatalogPostgresRepository -.
+func NewCatalogPostgresRepository(pg *postgres.Postgres) CatalogRepository {
+	return &CatalogPostgresRepo{Postgres: pg}
+}
+
+// InTx -.
+func (r *CatalogPostgresRepo) InTx(ctx context.Context, fn func(repo CatalogRepository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("CatalogPostgresRepo - InTx - r.Pool.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	err = fn(&CatalogPostgresRepo{Postgres: r.Postgres, tx: tx})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("CatalogPostgresRepo - InTx - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// conn is the transaction of InTx, if any, else the pool.
+func (r *CatalogPostgresRepo) conn() pgConn {
+	if r.tx != nil {
+		return r.tx
+	}
+
+	return r.Pool
+}
+
+// CreateCategory -.
+func (r *CatalogPostgresRepo) CreateCategory(ctx context.Context, c *entity.Category) (uint64, error) {
+	sql, args, err := r.Builder.
+		Insert("categories").
+		Columns("parent_id, name").
+		Values(c.ParentID, c.Name).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("CatalogPostgresRepo - CreateCategory - r.Builder: %w", err)
+	}
+
+	var id uint64
+
+	err = r.conn().QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("CatalogPostgresRepo - CreateCategory - r.conn().QueryRow: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetCategory -.
+func (r *CatalogPostgresRepo) GetCategory(ctx context.Context, id uint64) (*entity.Category, error) {
+	c, err := r.getCategory(ctx, id, "")
+	if err != nil {
+		return nil, fmt.Errorf("CatalogPostgresRepo - GetCategory - r.getCategory: %w", err)
+	}
+
+	return c, nil
+}
+
+// LockCategory -.
+func (r *CatalogPostgresRepo) LockCategory(ctx context.Context, id uint64) (*entity.Category, error) {
+	c, err := r.getCategory(ctx, id, "FOR UPDATE")
+	if err != nil {
+		return nil, fmt.Errorf("CatalogPostgresRepo - LockCategory - r.getCategory: %w", err)
+	}
+
+	return c, nil
+}
+
+func (r *CatalogPostgresRepo) getCategory(ctx context.Context, id uint64, suffix string) (*entity.Category, error) {
+	sql, args, err := r.Builder.
+		Select(_categoryColumns).
+		From("categories").
+		Where(squirrel.Eq{"id": id}).
+		Suffix(suffix).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("r.Builder: %w", err)
+	}
+
+	c, err := scanCategory(r.conn().QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("scanCategory: %w", err)
+	}
+
+	return c, nil
+}
+
+// ListCategories -.
+func (r *CatalogPostgresRepo) ListCategories(ctx context.Context) ([]*entity.Category, error) {
+	q := r.Builder.
+		Select(_categoryColumns).
+		From("categories").
+		OrderBy("id")
+
+	categories, err := r.queryCategories(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("CatalogPostgresRepo - ListCategories - r.queryCategories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// UpdateCategory -.
+func (r *CatalogPostgresRepo) UpdateCategory(ctx context.Context, c *entity.Category) error {
+	sql, args, err := r.Builder.
+		Update("categories").
+		Set("parent_id", c.ParentID).
+		Set("name", c.Name).
+		Where(squirrel.Eq{"id": c.ID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CatalogPostgresRepo - UpdateCategory - r.Builder: %w", err)
+	}
+
+	_, err = r.conn().Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("CatalogPostgresRepo - UpdateCategory - r.conn().Exec: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCategory -.
+func (r *CatalogPostgresRepo) DeleteCategory(ctx context.Context, id uint64) (bool, error) {
+	deleted, err := r.deleteRows(ctx, r.Builder.Delete("categories").Where(squirrel.Eq{"id": id}))
+	if isReferenced(err) {
+		return false, fmt.Errorf("%w: %v", ErrCategoryHasChildren, err)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("CatalogPostgresRepo - DeleteCategory - r.deleteRows: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// ProductCategories -.
+func (r *CatalogPostgresRepo) ProductCategories(ctx context.Context, productID uint64) ([]*entity.Category, error) {
+	q := r.Builder.
+		Select("c.id, c.parent_id, c.name, c.created_at").
+		From("categories c").
+		Join("product_categories pc ON pc.category_id = c.id").
+		Where(squirrel.Eq{"pc.product_id": productID}).
+		OrderBy("c.id")
+
+	categories, err := r.queryCategories(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("CatalogPostgresRepo - ProductCategories - r.queryCategories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// AssignCategory -.
+func (r *CatalogPostgresRepo) AssignCategory(ctx context.Context, productID, categoryID uint64) error {
+	sql, args, err := r.Builder.
+		Insert("product_categories").
+		Columns("product_id, category_id").
+		Values(productID, categoryID).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CatalogPostgresRepo - AssignCategory - r.Builder: %w", err)
+	}
+
+	_, err = r.conn().Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("CatalogPostgresRepo - AssignCategory - r.conn().Exec: %w", err)
+	}
+
+	return nil
+}
+
+// UnassignCategory -.
+func (r *CatalogPostgresRepo) UnassignCategory(ctx context.Context, productID, categoryID uint64) (bool, error) {
+	deleted, err := r.deleteRows(ctx, r.Builder.
+		Delete("product_categories").
+		Where(squirrel.Eq{"product_id": productID, "category_id": categoryID}))
+	if err != nil {
+		return false, fmt.Errorf("CatalogPostgresRepo - UnassignCategory - r.deleteRows: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// ListTags -.
+func (r *CatalogPostgresRepo) ListTags(ctx context.Context) ([]*entity.Tag, error) {
+	tags, err := r.queryTags(ctx, r.Builder.Select(_tagColumns).From("tags").OrderBy("name"))
+	if err != nil {
+		return nil, fmt.Errorf("CatalogPostgresRepo - ListTags - r.queryTags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// GetTag -.
+func (r *CatalogPostgresRepo) GetTag(ctx context.Context, id uint64) (*entity.Tag, error) {
+	t, err := r.getTag(ctx, squirrel.Eq{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("CatalogPostgresRepo - GetTag - r.getTag: %w", err)
+	}
+
+	return t, nil
+}
+
+// GetTagByName -.
+func (r *CatalogPostgresRepo) GetTagByName(ctx context.Context, name string) (*entity.Tag, error) {
+	t, err := r.getTag(ctx, squirrel.Eq{"name": name})
+	if err != nil {
+		return nil, fmt.Errorf("CatalogPostgresRepo - GetTagByName - r.getTag: %w", err)
+	}
+
+	return t, nil
+}
+
+// CreateTag -.
+func (r *CatalogPostgresRepo) CreateTag(ctx context.Context, name string) (uint64, error) {
+	sql, args, err := r.Builder.
+		Insert("tags").
+		Columns("name").
+		Values(name).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("CatalogPostgresRepo - CreateTag - r.Builder: %w", err)
+	}
+
+	var id uint64
+
+	err = r.conn().QueryRow(ctx, sql, args...).Scan(&id)
+	if isUniqueViolation(err) {
+		return 0, fmt.Errorf("%w: %v", ErrDuplicateTag, err)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("CatalogPostgresRepo - CreateTag - r.conn().QueryRow: %w", err)
+	}
+
+	return id, nil
+}
+
+// RenameTag -.
+func (r *CatalogPostgresRepo) RenameTag(ctx context.Context, id uint64, name string) error {
+	sql, args, err := r.Builder.
+		Update("tags").
+		Set("name", name).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CatalogPostgresRepo - RenameTag - r.Builder: %w", err)
+	}
+
+	_, err = r.conn().Exec(ctx, sql, args...)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: %v", ErrDuplicateTag, err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("CatalogPostgresRepo - RenameTag - r.conn().Exec: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTag -.
+func (r *CatalogPostgresRepo) DeleteTag(ctx context.Context, id uint64) (bool, error) {
+	deleted, err := r.deleteRows(ctx, r.Builder.Delete("tags").Where(squirrel.Eq{"id": id}))
+	if err != nil {
+		return false, fmt.Errorf("CatalogPostgresRepo - DeleteTag - r.deleteRows: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// ProductTags -.
+func (r *CatalogPostgresRepo) ProductTags(ctx context.Context, productID uint64) ([]*entity.Tag, error) {
+	q := r.Builder.
+		Select("t.id, t.name").
+		From("tags t").
+		Join("product_tags pt ON pt.tag_id = t.id").
+		Where(squirrel.Eq{"pt.product_id": productID}).
+		OrderBy("t.name")
+
+	tags, err := r.queryTags(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("CatalogPostgresRepo - ProductTags - r.queryTags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// TagProduct -.
+func (r *CatalogPostgresRepo) TagProduct(ctx context.Context, productID, tagID uint64) error {
+	sql, args, err := r.Builder.
+		Insert("product_tags").
+		Columns("product_id, tag_id").
+		Values(productID, tagID).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CatalogPostgresRepo - TagProduct - r.Builder: %w", err)
+	}
+
+	_, err = r.conn().Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("CatalogPostgresRepo - TagProduct - r.conn().Exec: %w", err)
+	}
+
+	return nil
+}
+
+// UntagProduct -.
+func (r *CatalogPostgresRepo) UntagProduct(ctx context.Context, productID, tagID uint64) (bool, error) {
+	deleted, err := r.deleteRows(ctx, r.Builder.
+		Delete("product_tags").
+		Where(squirrel.Eq{"product_id": productID, "tag_id": tagID}))
+	if err != nil {
+		return false, fmt.Errorf("CatalogPostgresRepo - UntagProduct - r.deleteRows: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// deleteRows runs q and reports whether it removed a row.
+func (r *CatalogPostgresRepo) deleteRows(ctx context.Context, q squirrel.DeleteBuilder) (bool, error) {
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := r.conn().Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *CatalogPostgresRepo) getTag(ctx context.Context, where squirrel.Eq) (*entity.Tag, error) {
+	sql, args, err := r.Builder.Select(_tagColumns).From("tags").Where(where).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &entity.Tag{}
+
+	err = r.conn().QueryRow(ctx, sql, args...).Scan(&t.ID, &t.Name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (r *CatalogPostgresRepo) queryCategories(ctx context.Context, q squirrel.SelectBuilder) ([]*entity.Category, error) {
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []*entity.Category{}
+
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
+func (r *CatalogPostgresRepo) queryTags(ctx context.Context, q squirrel.SelectBuilder) ([]*entity.Tag, error) {
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*entity.Tag{}
+
+	for rows.Next() {
+		t := &entity.Tag{}
+
+		err = rows.Scan(&t.ID, &t.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, t)
+	}
+
+	return tags, rows.Err()
+}
+
+func scanCategory(row pgx.Row) (*entity.Category, error) {
+	var (
+		c         = &entity.Category{}
+		createdAt time.Time
+	)
+
+	err := row.Scan(&c.ID, &c.ParentID, &c.Name, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.CreatedAt = createdAt.Format(_timeLayout)
+
+	return c, nil
+}
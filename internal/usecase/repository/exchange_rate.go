@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"strings"
+)
+
+//go:generate mockgen -source=exchange_rate.go -destination=../mocks_exchange_rate_test.go -package=usecase_test
+
+type ExchangeRateRepository interface {
+	// RateAt returns the rate from base to quote that was current at at, or nil when there was none.
+	RateAt(ctx context.Context, base, quote, at string) (*entity.ExchangeRate, error)
+	// ListRates returns every rate the pair has had, oldest first.
+	ListRates(ctx context.Context, base, quote string) ([]*entity.ExchangeRate, error)
+	// SaveRate makes r the current rate of its pair from r.ValidFrom, closing the previous one.
+	// It changes nothing and reports false when the rate is unchanged or r is not newer than the current rate.
+	SaveRate(ctx context.Context, r *entity.ExchangeRate) (bool, error)
+}
+
+type exchangeRateRepo struct {
+	db *sql.DB
+}
+
+const _exchangeRateColumns = "base, quote, rate, valid_from, valid_to"
+
+func NewExchangeRateRepository(db *sql.DB) ExchangeRateRepository {
+	return &exchangeRateRepo{db: db}
+}
+
+func (r *exchangeRateRepo) RateAt(ctx context.Context, base, quote, at string) (*entity.ExchangeRate, error) {
+	query := `SELECT ` + _exchangeRateColumns + ` FROM exchange_rates WHERE base = ? AND quote = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?) ORDER BY valid_from DESC, id DESC LIMIT 1`
+	rate, err := scanMySQLExchangeRate(r.db.QueryRowContext(ctx, query, base, quote, at, at))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rate, err
+}
+func (r *exchangeRateRepo) ListRates(ctx context.Context, base, quote string) ([]*entity.ExchangeRate, error) {
+	query := `SELECT ` + _exchangeRateColumns + ` FROM exchange_rates WHERE base = ? AND quote = ? ORDER BY valid_from, id`
+	rows, err := r.db.QueryContext(ctx, query, base, quote)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var rates []*entity.ExchangeRate
+	for rows.Next() {
+		rate, err := scanMySQLExchangeRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+func (r *exchangeRateRepo) SaveRate(ctx context.Context, rate *entity.ExchangeRate) (saved bool, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	// Lock the current rate so concurrent syncs close it only once
+	var id uint64
+	var current, validFrom string
+	query := `SELECT id, rate, valid_from FROM exchange_rates WHERE base = ? AND quote = ? AND valid_to IS NULL ORDER BY valid_from DESC, id DESC LIMIT 1 FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, rate.Base, rate.Quote).Scan(&id, &current, &validFrom)
+	switch {
+	case err == sql.ErrNoRows:
+		err = nil
+	case err != nil:
+		return false, err
+	case rate.ValidFrom <= validFrom || trimDecimal(current) == rate.Rate:
+		return false, nil
+	default:
+		if _, err = tx.ExecContext(ctx, `UPDATE exchange_rates SET valid_to = ? WHERE id = ?`, rate.ValidFrom, id); err != nil {
+			return false, err
+		}
+	}
+
+	// A pair without a current rate has nothing to lock; the unique pair and valid_from settle concurrent first rates.
+	// Unlike INSERT IGNORE, the no-op update skips only the duplicate, not other errors, and affects 0 rows
+	query = `INSERT INTO exchange_rates (base, quote, rate, valid_from) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE id = id`
+	result, err := tx.ExecContext(ctx, query, rate.Base, rate.Quote, rate.Rate, rate.ValidFrom)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+func scanMySQLExchangeRate(row rowScanner) (*entity.ExchangeRate, error) {
+	rate := &entity.ExchangeRate{}
+	var validTo sql.NullString
+	err := row.Scan(&rate.Base, &rate.Quote, &rate.Rate, &rate.ValidFrom, &validTo)
+	if err != nil {
+		return nil, err
+	}
+	rate.Rate = trimDecimal(rate.Rate)
+	rate.ValidTo = validTo.String
+	return rate, nil
+}
+
+// trimDecimal drops the trailing zeros a fixed-scale column pads rates with, e.g. "1.0850000000" is "1.085".
+func trimDecimal(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/pkg/postgres"
+)
+
+// _exchangeRatePostgresColumns reads rate as text so NUMERIC keeps its precision.
+const _exchangeRatePostgresColumns = "base, quote, rate::text, valid_from, valid_to"
+
+// ExchangeRatePostgresRepo -.
+type ExchangeRatePostgresRepo struct {
+	*postgres.Postgres
+}
+
+// NewExchangeRatePostgresRepository -.
+func NewExchangeRatePostgresRepository(pg *postgres.Postgres) ExchangeRateRepository {
+	return &ExchangeRatePostgresRepo{pg}
+}
+
+// RateAt -.
+func (r *ExchangeRatePostgresRepo) RateAt(ctx context.Context, base, quote, at string) (*entity.ExchangeRate, error) {
+	sql, args, err := r.Builder.
+		Select(_exchangeRatePostgresColumns).
+		From("exchange_rates").
+		Where(squirrel.Eq{"base": base, "quote": quote}).
+		Where("valid_from <= ?::timestamp", at).
+		Where("(valid_to IS NULL OR valid_to > ?::timestamp)", at).
+		OrderBy("valid_from DESC", "id DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ExchangeRatePostgresRepo - RateAt - r.Builder: %w", err)
+	}
+
+	rate, err := scanExchangeRate(r.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ExchangeRatePostgresRepo - RateAt - scanExchangeRate: %w", err)
+	}
+
+	return rate, nil
+}
+
+// ListRates -.
+func (r *ExchangeRatePostgresRepo) ListRates(ctx context.Context, base, quote string) ([]*entity.ExchangeRate, error) {
+	sql, args, err := r.Builder.
+		Select(_exchangeRatePostgresColumns).
+		From("exchange_rates").
+		Where(squirrel.Eq{"base": base, "quote": quote}).
+		OrderBy("valid_from", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ExchangeRatePostgresRepo - ListRates - r.Builder: %w", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ExchangeRatePostgresRepo - ListRates - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []*entity.ExchangeRate
+
+	for rows.Next() {
+		rate, err := scanExchangeRate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ExchangeRatePostgresRepo - ListRates - scanExchangeRate: %w", err)
+		}
+
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+// SaveRate -.
+func (r *ExchangeRatePostgresRepo) SaveRate(ctx context.Context, rate *entity.ExchangeRate) (bool, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("ExchangeRatePostgresRepo - SaveRate - r.Pool.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	// Lock the current rate so concurrent syncs close it only once
+	sql, args, err := r.Builder.
+		Select("id", "rate::text", "valid_from").
+		From("exchange_rates").
+		Where(squirrel.Eq{"base": rate.Base, "quote": rate.Quote, "valid_to": nil}).
+		OrderBy("valid_from DESC", "id DESC").
+		Limit(1).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("ExchangeRatePostgresRepo - SaveRate - r.Builder current: %w", err)
+	}
+
+	var (
+		id        uint64
+		current   string
+		validFrom time.Time
+	)
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id, &current, &validFrom)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return false, fmt.Errorf("ExchangeRatePostgresRepo - SaveRate - tx.QueryRow: %w", err)
+	case rate.ValidFrom <= validFrom.Format(_timeLayout) || trimDecimal(current) == rate.Rate:
+		return false, nil
+	default:
+		sql, args, err = r.Builder.
+			Update("exchange_rates").
+			Set("valid_to", rate.ValidFrom).
+			Where(squirrel.Eq{"id": id}).
+			ToSql()
+		if err != nil {
+			return false, fmt.Errorf("ExchangeRatePostgresRepo - SaveRate - r.Builder close: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, sql, args...)
+		if err != nil {
+			return false, fmt.Errorf("ExchangeRatePostgresRepo - SaveRate - tx.Exec close: %w", err)
+		}
+	}
+
+	sql, args, err = r.Builder.
+		Insert("exchange_rates").
+		Columns("base, quote, rate, valid_from").
+		Values(rate.Base, rate.Quote, rate.Rate, rate.ValidFrom).
+		// A pair without a current rate has nothing to lock; the unique pair and valid_from settle concurrent first rates
+		Suffix("ON CONFLICT (base, quote, valid_from) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("ExchangeRatePostgresRepo - SaveRate - r.Builder insert: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("ExchangeRatePostgresRepo - SaveRate - tx.Exec insert: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return false, fmt.Errorf("ExchangeRatePostgresRepo - SaveRate - tx.Commit: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func scanExchangeRate(row pgx.Row) (*entity.ExchangeRate, error) {
+	var (
+		rate      = &entity.ExchangeRate{}
+		validFrom time.Time
+		validTo   *time.Time
+	)
+
+	err := row.Scan(&rate.Base, &rate.Quote, &rate.Rate, &validFrom, &validTo)
+	if err != nil {
+		return nil, err
+	}
+
+	rate.Rate = trimDecimal(rate.Rate)
+	rate.ValidFrom = validFrom.Format(_timeLayout)
+	rate.ValidTo = formatTimePtr(validTo)
+
+	return rate, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+//go:generate mockgen -source=inventory.go -destination=../mocks_inventory_test.go -package=usecase_test
+
+// ErrDuplicateStockMovement is returned by Record when the product already has a movement of the kind for the reference.
+var ErrDuplicateStockMovement = errors.New("stock movement already recorded for the reference")
+
+// InventoryRepository stores the stock ledger of products alongside ProductRepository. The stock
+// level of a product is the running total of its ledger and is stored with every movement, so it
+// is read without adding the ledger up.
+type InventoryRepository interface {
+	// InTx runs fn with a repository whose reads and writes share one transaction, committed when fn returns nil.
+	InTx(ctx context.Context, fn func(repo InventoryRepository) error) error
+	// LockLevel returns the stock level of a product, locked until the transaction of InTx ends so
+	// the movements of a product are recorded one at a time. A product without stock gets an empty level first.
+	LockLevel(ctx context.Context, productID uint64) (*entity.StockLevel, error)
+	// GetLevel returns the stock level of a product, empty when it never had stock.
+	GetLevel(ctx context.Context, productID uint64) (*entity.StockLevel, error)
+	// Reserved returns the quantity of a product reserved for reference and not released or fulfilled yet.
+	Reserved(ctx context.Context, productID uint64, reference string) (int64, error)
+	// GetMovement returns the movement of kind of a product for reference, or nil when there is none.
+	GetMovement(ctx context.Context, productID uint64, reference, kind string) (*entity.StockMovement, error)
+	// Record appends m with the entity.Change carried by ctx to the ledger of its product and stores
+	// level as the stock level of the product; it belongs within InTx, after LockLevel.
+	Record(ctx context.Context, m *entity.StockMovement, level *entity.StockLevel) (uint64, error)
+	// ListMovements returns the ledger of a product, oldest first.
+	ListMovements(ctx context.Context, productID uint64) ([]*entity.StockMovement, error)
+	// LowStock returns the levels of up to limit live products whose available stock is at most
+	// threshold, lowest first; products that never had stock count as empty.
+	LowStock(ctx context.Context, threshold int64, limit uint64) ([]*entity.StockLevel, error)
+}
+
+type inventoryRepo struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+const (
+	_stockLevelColumns    = "product_id, on_hand, reserved, updated_at"
+	_stockMovementColumns = "id, product_id, kind, quantity, reference, created_at, changed_by, change_reason, request_id"
+	// _reservedSum adds up the reservations of a product for a reference less their releases and fulfilments.
+	_reservedSum = `COALESCE(SUM(CASE kind WHEN 'reservation' THEN quantity WHEN 'release' THEN -quantity WHEN 'fulfilment' THEN -quantity ELSE 0 END), 0)`
+)
+
+func NewInventoryRepository(db *sql.DB) InventoryRepository {
+	return &inventoryRepo{db: db}
+}
+
+func (r *inventoryRepo) InTx(ctx context.Context, fn func(repo InventoryRepository) error) (err error) {
+	if r.tx != nil {
+		return fn(r)
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+	return fn(&inventoryRepo{db: r.db, tx: tx})
+}
+
+// conn is the transaction of InTx, if any, else the pool.
+func (r *inventoryRepo) conn() mysqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+func (r *inventoryRepo) LockLevel(ctx context.Context, productID uint64) (*entity.StockLevel, error) {
+	// The upsert locks an existing row as well, and a product's first movement must lock something
+	if _, err := r.conn().ExecContext(ctx, `INSERT INTO stock_levels (product_id) VALUES (?) ON DUPLICATE KEY UPDATE product_id = product_id`, productID); err != nil {
+		return nil, err
+	}
+	return scanMySQLStockLevel(r.conn().QueryRowContext(ctx, `SELECT `+_stockLevelColumns+` FROM stock_levels WHERE product_id = ? FOR UPDATE`, productID))
+}
+func (r *inventoryRepo) GetLevel(ctx context.Context, productID uint64) (*entity.StockLevel, error) {
+	l, err := scanMySQLStockLevel(r.conn().QueryRowContext(ctx, `SELECT `+_stockLevelColumns+` FROM stock_levels WHERE product_id = ?`, productID))
+	if err == sql.ErrNoRows {
+		return &entity.StockLevel{ProductID: productID}, nil
+	}
+	return l, err
+}
+func (r *inventoryRepo) Reserved(ctx context.Context, productID uint64, reference string) (int64, error) {
+	var reserved int64
+	err := r.conn().QueryRowContext(ctx, `SELECT `+_reservedSum+` FROM stock_movements WHERE product_id = ? AND reference = ?`, productID, reference).Scan(&reserved)
+	return reserved, err
+}
+func (r *inventoryRepo) GetMovement(ctx context.Context, productID uint64, reference, kind string) (*entity.StockMovement, error) {
+	query := `SELECT ` + _stockMovementColumns + ` FROM stock_movements WHERE product_id = ? AND reference = ? AND kind = ?`
+	m, err := scanMySQLStockMovement(r.conn().QueryRowContext(ctx, query, productID, reference, kind))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return m, err
+}
+func (r *inventoryRepo) Record(ctx context.Context, m *entity.StockMovement, level *entity.StockLevel) (uint64, error) {
+	query := `INSERT INTO stock_movements (product_id, kind, quantity, reference, created_at, changed_by, change_reason, request_id) VALUES (?, ?, ?, ?, NOW(), ?, ?, ?)`
+	result, err := r.conn().ExecContext(ctx, query, append([]interface{}{m.ProductID, m.Kind, m.Quantity, nullIfEmpty(m.Reference)}, changeArgs(ctx)...)...)
+	if err != nil {
+		return 0, duplicateStockMovement(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	query = `UPDATE stock_levels SET on_hand = ?, reserved = ?, updated_at = NOW() WHERE product_id = ?`
+	if _, err = r.conn().ExecContext(ctx, query, level.OnHand, level.Reserved, m.ProductID); err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+func (r *inventoryRepo) ListMovements(ctx context.Context, productID uint64) ([]*entity.StockMovement, error) {
+	rows, err := r.conn().QueryContext(ctx, `SELECT `+_stockMovementColumns+` FROM stock_movements WHERE product_id = ? ORDER BY id`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	movements := []*entity.StockMovement{}
+	for rows.Next() {
+		m, err := scanMySQLStockMovement(rows)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+	return movements, rows.Err()
+}
+func (r *inventoryRepo) LowStock(ctx context.Context, threshold int64, limit uint64) ([]*entity.StockLevel, error) {
+	query := `SELECT p.id, COALESCE(s.on_hand, 0), COALESCE(s.reserved, 0), s.updated_at FROM products p LEFT JOIN stock_levels s ON s.product_id = p.id WHERE p.deleted_at IS NULL AND COALESCE(s.on_hand, 0) - COALESCE(s.reserved, 0) <= ? ORDER BY COALESCE(s.on_hand, 0) - COALESCE(s.reserved, 0), p.id LIMIT ?`
+	rows, err := r.conn().QueryContext(ctx, query, threshold, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	levels := []*entity.StockLevel{}
+	for rows.Next() {
+		l, err := scanMySQLStockLevel(rows)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, l)
+	}
+	return levels, rows.Err()
+}
+
+// duplicateStockMovement turns the unique key violation of a movement into ErrDuplicateStockMovement.
+func duplicateStockMovement(err error) error {
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: %v", ErrDuplicateStockMovement, err)
+	}
+	return err
+}
+
+func scanMySQLStockLevel(row rowScanner) (*entity.StockLevel, error) {
+	l := &entity.StockLevel{}
+	var updatedAt sql.NullString
+	if err := row.Scan(&l.ProductID, &l.OnHand, &l.Reserved, &updatedAt); err != nil {
+		return nil, err
+	}
+	l.Available = l.OnHand - l.Reserved
+	l.UpdatedAt = updatedAt.String
+	return l, nil
+}
+
+func scanMySQLStockMovement(row rowScanner) (*entity.StockMovement, error) {
+	m := &entity.StockMovement{}
+	var reference, changedBy, changeReason, requestID sql.NullString
+	err := row.Scan(&m.ID, &m.ProductID, &m.Kind, &m.Quantity, &reference, &m.CreatedAt, &changedBy, &changeReason, &requestID)
+	if err != nil {
+		return nil, err
+	}
+	m.Reference = reference.String
+	m.ChangedBy = changedBy.String
+	m.ChangeReason = changeReason.String
+	m.RequestID = requestID.String
+	return m, nil
+}
@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/pkg/postgres"
+)
+
+// _available is the available stock of the stock_levels row s, empty when there is none.
+const _available = "COALESCE(s.on_hand, 0) - COALESCE(s.reserved, 0)"
+
+// InventoryPostgresRepo -.
+type InventoryPostgresRepo struct {
+	*postgres.Postgres
+	tx pgx.Tx
+}
+
+// NewInventoryPostgresRepository -.
+func NewInventoryPostgresRepository(pg *postgres.Postgres) InventoryRepository {
+	return &InventoryPostgresRepo{Postgres: pg}
+}
+
+// InTx -.
+func (r *InventoryPostgresRepo) InTx(ctx context.Context, fn func(repo InventoryRepository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("InventoryPostgresRepo - InTx - r.Pool.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	err = fn(&InventoryPostgresRepo{Postgres: r.Postgres, tx: tx})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("InventoryPostgresRepo - InTx - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// conn is the transaction of InTx, if any, else the pool.
+func (r *InventoryPostgresRepo) conn() pgConn {
+	if r.tx != nil {
+		return r.tx
+	}
+
+	return r.Pool
+}
+
+// LockLevel -.
+func (r *InventoryPostgresRepo) LockLevel(ctx context.Context, productID uint64) (*entity.StockLevel, error) {
+	sql, args, err := r.Builder.
+		Insert("stock_levels").
+		Columns("product_id").
+		Values(productID).
+		Suffix("ON CONFLICT (product_id) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("InventoryPostgresRepo - LockLevel - r.Builder: %w", err)
+	}
+
+	_, err = r.conn().Exec(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("InventoryPostgresRepo - LockLevel - r.conn().Exec: %w", err)
+	}
+
+	l, err := r.getLevel(ctx, productID, "FOR UPDATE")
+	if err != nil {
+		return nil, fmt.Errorf("InventoryPostgresRepo - LockLevel - r.getLevel: %w", err)
+	}
+
+	return l, nil
+}
+
+// GetLevel -.
+func (r *InventoryPostgresRepo) GetLevel(ctx context.Context, productID uint64) (*entity.StockLevel, error) {
+	l, err := r.getLevel(ctx, productID, "")
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &entity.StockLevel{ProductID: productID}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("InventoryPostgresRepo - GetLevel - r.getLevel: %w", err)
+	}
+
+	return l, nil
+}
+
+// Reserved -.
+func (r *InventoryPostgresRepo) Reserved(ctx context.Context, productID uint64, reference string) (int64, error) {
+	sql, args, err := r.Builder.
+		Select(_reservedSum + "::bigint"). // SUM of BIGINT is NUMERIC
+		From("stock_movements").
+		Where(squirrel.Eq{"product_id": productID, "reference": reference}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("InventoryPostgresRepo - Reserved - r.Builder: %w", err)
+	}
+
+	var reserved int64
+
+	err = r.conn().QueryRow(ctx, sql, args...).Scan(&reserved)
+	if err != nil {
+		return 0, fmt.Errorf("InventoryPostgresRepo - Reserved - row.Scan: %w", err)
+	}
+
+	return reserved, nil
+}
+
+// GetMovement -.
+func (r *InventoryPostgresRepo) GetMovement(ctx context.Context, productID uint64, reference, kind string) (*entity.StockMovement, error) {
+	sql, args, err := r.Builder.
+		Select(_stockMovementColumns).
+		From("stock_movements").
+		Where(squirrel.Eq{"product_id": productID, "reference": reference, "kind": kind}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("InventoryPostgresRepo - GetMovement - r.Builder: %w", err)
+	}
+
+	m, err := scanStockMovement(r.conn().QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("InventoryPostgresRepo - GetMovement - scanStockMovement: %w", err)
+	}
+
+	return m, nil
+}
+
+// Record -.
+func (r *InventoryPostgresRepo) Record(ctx context.Context, m *entity.StockMovement, level *entity.StockLevel) (uint64, error) {
+	sql, args, err := r.Builder.
+		Insert("stock_movements").
+		Columns("product_id, kind, quantity, reference, created_at, changed_by, change_reason, request_id").
+		Values(append([]interface{}{m.ProductID, m.Kind, m.Quantity, nullIfEmpty(m.Reference), squirrel.Expr("NOW()")}, changeArgs(ctx)...)...).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("InventoryPostgresRepo - Record - r.Builder: %w", err)
+	}
+
+	var id uint64
+
+	err = r.conn().QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("InventoryPostgresRepo - Record - insert: %w", duplicateStockMovement(err))
+	}
+
+	sql, args, err = r.Builder.
+		Update("stock_levels").
+		Set("on_hand", level.OnHand).
+		Set("reserved", level.Reserved).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"product_id": m.ProductID}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("InventoryPostgresRepo - Record - r.Builder: %w", err)
+	}
+
+	_, err = r.conn().Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("InventoryPostgresRepo - Record - update: %w", err)
+	}
+
+	return id, nil
+}
+
+// ListMovements -.
+func (r *InventoryPostgresRepo) ListMovements(ctx context.Context, productID uint64) ([]*entity.StockMovement, error) {
+	sql, args, err := r.Builder.
+		Select(_stockMovementColumns).
+		From("stock_movements").
+		Where(squirrel.Eq{"product_id": productID}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("InventoryPostgresRepo - ListMovements - r.Builder: %w", err)
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("InventoryPostgresRepo - ListMovements - r.conn().Query: %w", err)
+	}
+	defer rows.Close()
+
+	movements := []*entity.StockMovement{}
+
+	for rows.Next() {
+		m, err := scanStockMovement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("InventoryPostgresRepo - ListMovements - scanStockMovement: %w", err)
+		}
+
+		movements = append(movements, m)
+	}
+
+	return movements, rows.Err()
+}
+
+// LowStock -.
+func (r *InventoryPostgresRepo) LowStock(ctx context.Context, threshold int64, limit uint64) ([]*entity.StockLevel, error) {
+	sql, args, err := r.Builder.
+		Select("p.id, COALESCE(s.on_hand, 0), COALESCE(s.reserved, 0), s.updated_at").
+		From("products p").
+		LeftJoin("stock_levels s ON s.product_id = p.id").
+		Where(squirrel.Eq{"p.deleted_at": nil}).
+		Where(_available+" <= ?", threshold).
+		OrderBy(_available, "p.id").
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("InventoryPostgresRepo - LowStock - r.Builder: %w", err)
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("InventoryPostgresRepo - LowStock - r.conn().Query: %w", err)
+	}
+	defer rows.Close()
+
+	levels := []*entity.StockLevel{}
+
+	for rows.Next() {
+		l, err := scanStockLevel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("InventoryPostgresRepo - LowStock - scanStockLevel: %w", err)
+		}
+
+		levels = append(levels, l)
+	}
+
+	return levels, rows.Err()
+}
+
+// getLevel reads the stock_levels row of a product; lock is an optional locking clause.
+func (r *InventoryPostgresRepo) getLevel(ctx context.Context, productID uint64, lock string) (*entity.StockLevel, error) {
+	sql, args, err := r.Builder.
+		Select(_stockLevelColumns).
+		From("stock_levels").
+		Where(squirrel.Eq{"product_id": productID}).
+		Suffix(lock).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return scanStockLevel(r.conn().QueryRow(ctx, sql, args...))
+}
+
+func scanStockLevel(row pgx.Row) (*entity.StockLevel, error) {
+	var (
+		l         = &entity.StockLevel{}
+		updatedAt *time.Time
+	)
+
+	err := row.Scan(&l.ProductID, &l.OnHand, &l.Reserved, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Available = l.OnHand - l.Reserved
+	l.UpdatedAt = formatTimePtr(updatedAt)
+
+	return l, nil
+}
+
+func scanStockMovement(row pgx.Row) (*entity.StockMovement, error) {
+	var (
+		m                                             = &entity.StockMovement{}
+		createdAt                                     time.Time
+		reference, changedBy, changeReason, requestID *string
+	)
+
+	err := row.Scan(&m.ID, &m.ProductID, &m.Kind, &m.Quantity, &reference, &createdAt, &changedBy, &changeReason, &requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Reference = stringOrEmpty(reference)
+	m.CreatedAt = createdAt.Format(_timeLayout)
+	m.ChangedBy = stringOrEmpty(changedBy)
+	m.ChangeReason = stringOrEmpty(changeReason)
+	m.RequestID = stringOrEmpty(requestID)
+
+	return m, nil
+}
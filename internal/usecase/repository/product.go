@@ -3,115 +3,381 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"github.com/Masterminds/squirrel"
 	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"strconv"
+	"time"
 )
 
+//go:generate mockgen -source=product.go -destination=../mocks_product_test.go -package=usecase_test
+
 type ProductRepository interface {
+	// InTx runs fn with a repository whose reads and writes share one transaction, committed when fn
+	// returns nil. Each write through it is undone on its own when it fails, so fn may carry on after errors.
+	InTx(ctx context.Context, fn func(repo ProductRepository) error) error
 	Create(ctx context.Context, p *entity.Product) (uint64, error)
-	GetByID(ctx context.Context, id uint64) (*entity.Product, error)
+	// GetByID returns nil for unknown products, and for soft-deleted ones unless includeDeleted is set.
+	GetByID(ctx context.Context, id uint64, includeDeleted bool) (*entity.Product, error)
+	// GetBySKU returns the product with a SKU, soft-deleted or not, or nil when there is none.
+	GetBySKU(ctx context.Context, sku string) (*entity.Product, error)
+	// Update stores p as the next version. When p.Version is non-zero it must be the stored
+	// version, otherwise a *ConflictError is returned and nothing is changed.
+	// Unknown and soft-deleted products give ErrProductNotFound.
 	Update(ctx context.Context, p *entity.Product) error
-	Delete(ctx context.Context, id uint64) error
-	List(ctx context.Context) ([]*entity.Product, error)
+	// Delete soft-deletes a product, recording the deletion as its last version;
+	// a non-zero version is checked like in Update.
+	Delete(ctx context.Context, id, version uint64) error
+	// Restore undoes Delete as a new version; products that are not deleted give ErrProductNotFound.
+	Restore(ctx context.Context, id, version uint64) error
+	List(ctx context.Context, f *entity.ProductFilter) ([]*entity.Product, uint64, error)
+	// Search returns a page of the products matching s, most relevant first, and how many match in all.
+	// A search cursor is s.Filter.After with SortBy SearchCursorSort.
+	Search(ctx context.Context, s *entity.ProductSearch) ([]*entity.ProductSearchHit, uint64, error)
+	// SearchWords returns, sorted, the words of the names and descriptions of products, deleted or not,
+	// that may be one typo away from term: all that are, and some that are not.
+	SearchWords(ctx context.Context, term string) ([]string, error)
+	// GetProductHistory, GetHighestPrice and GetByDate return nil when there is nothing to return, like GetByID.
 	GetProductHistory(ctx context.Context, id uint64) (*entity.Product, []*entity.ProductHistory, error)
 	GetHighestPrice(ctx context.Context, id uint64) (*entity.ProductMaxValue, error)
 	GetTimeDiff(ctx context.Context, id uint64) ([]*entity.TimeDiff, error)
 	GetByDate(ctx context.Context, id uint64, rd *entity.ReferenceDate) (*entity.ProductHistory, error)
+	// GetPriceIntervals returns the price intervals of the live versions of products that overlap
+	// [from, to), by product and oldest first; the intervals are not clipped to the window.
+	GetPriceIntervals(ctx context.Context, ids []uint64, from, to time.Time) ([]*entity.PriceInterval, error)
+	// ExportProducts and ExportHistory call fn with each row f selects as it is read; an error of fn stops them.
+	ExportProducts(ctx context.Context, f *entity.ExportFilter, fn func(p *entity.Product) error) error
+	ExportHistory(ctx context.Context, f *entity.ExportFilter, fn func(h *entity.ProductHistory) error) error
+
+	CreateScheduledChange(ctx context.Context, c *entity.ScheduledChange) (uint64, error)
+	GetScheduledChange(ctx context.Context, id uint64) (*entity.ScheduledChange, error)
+	ListScheduledChanges(ctx context.Context, productID uint64, status string) ([]*entity.ScheduledChange, error)
+	// DueScheduledChanges returns up to limit pending changes whose effective_at has passed, oldest first.
+	DueScheduledChanges(ctx context.Context, limit uint64) ([]*entity.ScheduledChange, error)
+	// TransitionScheduledChange moves a change from one status to another and reports
+	// whether it was in the from status; it is the compare-and-set used to claim changes.
+	TransitionScheduledChange(ctx context.Context, id uint64, from, to string) (bool, error)
 }
 
 type productRepo struct {
-	db *sql.DB
+	db      *sql.DB
+	tx      *sql.Tx
+	builder squirrel.StatementBuilderType
+}
+
+// mysqlConn is implemented by *sql.DB and *sql.Tx.
+type mysqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
+const (
+	// _mysqlOpenVersionQuery records the stored state of a product as its current, open-ended version; its arguments are changeArgs and the id.
+	_mysqlOpenVersionQuery = `INSERT INTO product_history (product_id, sku, name, description, price, currency, effective_from, valid_from, valid_to, created_at, version, deleted_at, changed_by, change_reason, request_id) SELECT id, sku, name, description, price, currency, effective_from, updated_at, NULL, created_at, version, deleted_at, ?, ?, ? FROM products WHERE id = ?`
+	_mysqlLive             = `deleted_at IS NULL`
+	_mysqlSavepoint        = `product_write`
+)
+
 func NewProductRepository(db *sql.DB) ProductRepository {
-	return &productRepo{db: db}
+	return &productRepo{db: db, builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question)}
 }
 
-func (r *productRepo) Create(ctx context.Context, p *entity.Product) (uint64, error) {
-	query := `INSERT INTO products (name, description, price, created_at, updated_at) VALUES (?, ?, ?, NOW(), NOW())`
+func (r *productRepo) InTx(ctx context.Context, fn func(repo ProductRepository) error) (err error) {
+	if r.tx != nil {
+		return fn(r)
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+	return fn(&productRepo{db: r.db, tx: tx, builder: r.builder})
+}
+
+// conn is the transaction of InTx, if any, else the pool.
+func (r *productRepo) conn() mysqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// begin starts the transaction of one write; within InTx it is a savepoint of that transaction.
+// end commits or rolls it back depending on the error of the write and returns the final error.
+func (r *productRepo) begin(ctx context.Context) (tx *sql.Tx, end func(error) error, err error) {
+	if r.tx == nil {
+		if tx, err = r.db.BeginTx(ctx, nil); err != nil {
+			return nil, nil, err
+		}
+		return tx, func(err error) error {
+			if err != nil {
+				_ = tx.Rollback()
+				return err
+			}
+			return tx.Commit()
+		}, nil
+	}
+	if _, err = r.tx.ExecContext(ctx, `SAVEPOINT `+_mysqlSavepoint); err != nil {
+		return nil, nil, err
+	}
+	return r.tx, func(err error) error {
+		if err != nil {
+			_, _ = r.tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT `+_mysqlSavepoint)
+			return err
+		}
+		_, err = r.tx.ExecContext(ctx, `RELEASE SAVEPOINT `+_mysqlSavepoint)
+		return err
+	}, nil
+}
 
-	var id int64
-	result, err := r.db.ExecContext(ctx, query, p.Name, p.Description, p.Price)
+func (r *productRepo) Create(ctx context.Context, p *entity.Product) (id uint64, err error) {
+	tx, end, err := r.begin(ctx)
 	if err != nil {
 		return 0, err
 	}
-	id, err = result.LastInsertId()
+	defer func() { err = end(err) }()
+
+	query := `INSERT INTO products (sku, name, description, price, currency, effective_from, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, NOW(), NOW())`
+	result, err := tx.ExecContext(ctx, query, nullIfEmpty(p.SKU), p.Name, p.Description, p.Price.Amount, p.Price.Currency, nullIfEmpty(p.EffectiveFrom))
+	if err != nil {
+		return 0, duplicateSKU(err)
+	}
+	lastID, err := result.LastInsertId()
 	if err != nil {
 		return 0, err
 	}
-	return uint64(id), err
+
+	// First version, open-ended
+	if _, err = tx.ExecContext(ctx, _mysqlOpenVersionQuery, append(changeArgs(ctx), lastID)...); err != nil {
+		return 0, err
+	}
+	if err = mysqlAddSearchWords(ctx, tx, uint64(lastID)); err != nil {
+		return 0, err
+	}
+	if err = mysqlRecordEvent(ctx, tx, entity.ProductCreated, nil, uint64(lastID)); err != nil {
+		return 0, err
+	}
+	return uint64(lastID), nil
 }
-func (r *productRepo) GetByID(ctx context.Context, id uint64) (*entity.Product, error) {
-	query := `SELECT id, name, description, price, created_at, updated_at FROM products WHERE id = ?`
-	p := &entity.Product{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&p.ID, &p.Name, &p.Description, &p.Price, &p.CreatedAt, &p.UpdatedAt)
+func (r *productRepo) GetByID(ctx context.Context, id uint64, includeDeleted bool) (*entity.Product, error) {
+	query := `SELECT ` + _productColumns + ` FROM products WHERE id = ?`
+	if !includeDeleted {
+		query += ` AND ` + _mysqlLive
+	}
+	p, err := scanMySQLProduct(r.conn().QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return p, err
+}
+func (r *productRepo) GetBySKU(ctx context.Context, sku string) (*entity.Product, error) {
+	query := `SELECT ` + _productColumns + ` FROM products WHERE sku = ?`
+	p, err := scanMySQLProduct(r.conn().QueryRowContext(ctx, query, sku))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return p, err
 }
 func (r *productRepo) Update(ctx context.Context, p *entity.Product) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+	id, err := strconv.ParseUint(p.ID, 10, 64)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err != nil {
-			_ = tx.Rollback()
-		} else {
-			err = tx.Commit()
-		}
-	}()
-	// Initial Product state to history
-	queryHistoryInsert := `INSERT into product_history (product_id, name, description, price, created_at, valid_from, valid_to) SELECT id, name, description, price, created_at, NOW(), NOW() + INTERVAL 7 DAY FROM products WHERE id = ?`
+	return r.writeVersion(ctx, entity.ProductUpdated, id, p.Version, `sku = ?, name = ?, description = ?, price = ?, currency = ?, effective_from = ?`, _mysqlLive,
+		nullIfEmpty(p.SKU), p.Name, p.Description, p.Price.Amount, p.Price.Currency, nullIfEmpty(p.EffectiveFrom))
+}
+func (r *productRepo) Delete(ctx context.Context, id, version uint64) error {
+	return r.writeVersion(ctx, entity.ProductDeleted, id, version, `deleted_at = NOW(), effective_from = NULL`, _mysqlLive)
+}
+func (r *productRepo) Restore(ctx context.Context, id, version uint64) error {
+	return r.writeVersion(ctx, entity.ProductRestored, id, version, `deleted_at = NULL, effective_from = NULL`, `deleted_at IS NOT NULL`)
+}
 
-	_, err = tx.ExecContext(ctx, queryHistoryInsert, p.ID)
+// writeVersion applies set to a product matching cond and records the result as its new version and
+// as an event of eventType. Products not matching cond count as missing.
+func (r *productRepo) writeVersion(ctx context.Context, eventType string, id, version uint64, set, cond string, setArgs ...interface{}) (err error) {
+	tx, end, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { err = end(err) }()
+
+	before, err := mysqlLockProduct(ctx, tx, id)
 	if err != nil {
 		return err
 	}
 
 	// Update Product
-	queryUpdateProduct := ` UPDATE products SET name = ?, description = ?, price = ?, updated_at = NOW() WHERE id = ?`
-	_, err = tx.ExecContext(ctx, queryUpdateProduct, p.Name, p.Description, p.Price, p.ID)
+	queryUpdateProduct := `UPDATE products SET ` + set + `, version = version + 1, updated_at = NOW() WHERE id = ? AND ` + cond
+	args := append(append([]interface{}{}, setArgs...), id)
+	if version != 0 {
+		queryUpdateProduct += ` AND version = ?`
+		args = append(args, version)
+	}
+	result, err := tx.ExecContext(ctx, queryUpdateProduct, args...)
+	if err != nil {
+		return duplicateSKU(err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return mysqlNotWritten(ctx, tx, id, version, cond)
+	}
+
+	// Close the previous version at the moment the new one became current
+	queryCloseVersion := `UPDATE product_history h JOIN products p ON p.id = h.product_id SET h.valid_to = p.updated_at WHERE h.product_id = ? AND h.valid_to IS NULL`
+	_, err = tx.ExecContext(ctx, queryCloseVersion, id)
 	if err != nil {
 		return err
 	}
-	return nil
+
+	if _, err = tx.ExecContext(ctx, _mysqlOpenVersionQuery, append(changeArgs(ctx), id)...); err != nil {
+		return err
+	}
+	// Only updates change the words of a product
+	if eventType == entity.ProductUpdated {
+		if err = mysqlAddSearchWords(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return mysqlRecordEvent(ctx, tx, eventType, before, id)
 }
-func (r *productRepo) Delete(ctx context.Context, id uint64) error {
-	query := `DELETE FROM products WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, id)
+
+// mysqlAddSearchWords keeps the words of the name and description of a product for SearchWords. Words
+// are never removed: one no product has any more only makes its misspellings search for it in vain.
+func mysqlAddSearchWords(ctx context.Context, tx mysqlConn, id uint64) error {
+	var name, description string
+	if err := tx.QueryRowContext(ctx, `SELECT name, description FROM products WHERE id = ?`, id).Scan(&name, &description); err != nil {
+		return err
+	}
+	rows := searchWordRows(name, description)
+	if len(rows) == 0 {
+		return nil
+	}
+	insert := squirrel.Insert("product_search_words").Columns("search_key", "word")
+	for _, row := range rows {
+		insert = insert.Values(row[0], row[1])
+	}
+	query, args, err := insert.Suffix(`ON DUPLICATE KEY UPDATE word = word`).ToSql()
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, query, args...)
 	return err
 }
-func (r *productRepo) List(ctx context.Context) ([]*entity.Product, error) {
-	query := `SELECT * FROM products`
-	rows, err := r.db.QueryContext(ctx, query)
+func (r *productRepo) List(ctx context.Context, f *entity.ProductFilter) ([]*entity.Product, uint64, error) {
+	filtered := productFilterWhere(r.builder.Select().From("products"), f, false)
+
+	countQuery, countArgs, err := filtered.Column("COUNT(*)").ToSql()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	var total uint64
+	if err := r.conn().QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args, err := productPageQuery(filtered.Column(_productColumns), f).ToSql()
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
 	}
 	defer rows.Close()
 	var products []*entity.Product
 	for rows.Next() {
-		p := &entity.Product{}
-		err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Price, &p.CreatedAt, &p.UpdatedAt)
+		p, err := scanMySQLProduct(rows)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		products = append(products, p)
 	}
-	return products, nil
+	return products, total, rows.Err()
+}
+
+// Search ranks name matches twice as high as description matches.
+func (r *productRepo) Search(ctx context.Context, s *entity.ProductSearch) ([]*entity.ProductSearchHit, uint64, error) {
+	against := mysqlBooleanQuery(s.Terms)
+	match := squirrel.Expr(`MATCH(name, description) AGAINST (? IN BOOLEAN MODE)`, against)
+	score := squirrel.Expr(`MATCH(name) AGAINST (? IN BOOLEAN MODE) * 2 + MATCH(name, description) AGAINST (? IN BOOLEAN MODE)`, against, against)
+	count, page, err := productSearchQueries(r.builder, s, match, score, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	countQuery, countArgs, err := count.ToSql()
+	if err != nil {
+		return nil, 0, err
+	}
+	var total uint64
+	if err := r.conn().QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query, args, err := page.ToSql()
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var hits []*entity.ProductSearchHit
+	for rows.Next() {
+		hit := &entity.ProductSearchHit{}
+		if hit.Product, err = scanMySQLProduct(scoredRow{row: rows, score: &hit.Score}); err != nil {
+			return nil, 0, err
+		}
+		hits = append(hits, hit)
+	}
+	return hits, total, rows.Err()
+}
+func (r *productRepo) SearchWords(ctx context.Context, term string) ([]string, error) {
+	query, args, err := r.builder.Select("DISTINCT word").From("product_search_words").
+		Where(squirrel.Eq{"search_key": searchWordKeys(term)}).OrderBy("word").ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var words []string
+	for rows.Next() {
+		var word string
+		if err := rows.Scan(&word); err != nil {
+			return nil, err
+		}
+		words = append(words, word)
+	}
+	return words, rows.Err()
 }
 
 func (r *productRepo) GetProductHistory(ctx context.Context, id uint64) (*entity.Product, []*entity.ProductHistory, error) {
-	productQuery := `SELECT id, name, description, price, created_at, updated_at FROM products WHERE id = ?`
-	historyQuery := `SELECT name, description, price, valid_from, valid_to FROM product_history WHERE product_id = ?`
+	productQuery := `SELECT ` + _productColumns + ` FROM products WHERE id = ?`
+	historyQuery := `SELECT ` + _historyColumns + ` FROM product_history WHERE product_id = ? ORDER BY valid_from, id`
 
-	p := &entity.Product{}
-	err := r.db.QueryRowContext(ctx, productQuery, id).Scan(&p.ID, &p.Name, &p.Description, &p.Price, &p.CreatedAt, &p.UpdatedAt)
+	p, err := scanMySQLProduct(r.conn().QueryRowContext(ctx, productQuery, id))
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
 	if err != nil {
 		return nil, nil, err
 	}
 
-	rows, err := r.db.QueryContext(ctx, historyQuery, id)
+	rows, err := r.conn().QueryContext(ctx, historyQuery, id)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -119,50 +385,191 @@ func (r *productRepo) GetProductHistory(ctx context.Context, id uint64) (*entity
 
 	var history []*entity.ProductHistory
 	for rows.Next() {
-		h := &entity.ProductHistory{}
-		err := rows.Scan(&h.Name, &h.Description, &h.Price, &h.ValidFrom, &h.ValidTo)
+		h, err := scanMySQLHistory(rows)
 		if err != nil {
 			return nil, nil, err
 		}
 		history = append(history, h)
 	}
-	return p, history, nil
+	return p, history, rows.Err()
 }
 
 func (r *productRepo) GetHighestPrice(ctx context.Context, id uint64) (*entity.ProductMaxValue, error) {
-	maxPriceQuery := `SELECT price, TIMEDIFF(valid_to, valid_from) AS duration FROM test.product_history WHERE product_id = ? ORDER BY duration DESC, price DESC LIMIT 1; `
+	maxPriceQuery := `SELECT price, currency, TIMEDIFF(COALESCE(valid_to, NOW()), valid_from) AS duration FROM product_history WHERE product_id = ? AND deleted_at IS NULL ORDER BY duration DESC, price DESC LIMIT 1; `
 	pMax := &entity.ProductMaxValue{}
-	err := r.db.QueryRowContext(ctx, maxPriceQuery, id).Scan(&pMax.Price, &pMax.Duration)
+	err := r.conn().QueryRowContext(ctx, maxPriceQuery, id).Scan(&pMax.Price.Amount, &pMax.Price.Currency, &pMax.Duration)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 	return pMax, nil
 }
 func (r *productRepo) GetTimeDiff(ctx context.Context, id uint64) ([]*entity.TimeDiff, error) {
-	timeDiffQuery := `SELECT valid_from, valid_to, price FROM test.product_history WHERE product_id = ? ORDER BY valid_from, valid_to;`
+	timeDiffQuery := `SELECT valid_from, valid_to, price, currency FROM product_history WHERE product_id = ? ORDER BY valid_from, id;`
 	var tDiffs []*entity.TimeDiff
-	rows, err := r.db.QueryContext(ctx, timeDiffQuery, id)
+	rows, err := r.conn().QueryContext(ctx, timeDiffQuery, id)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	for rows.Next() {
 		t := &entity.TimeDiff{}
-		err := rows.Scan(&t.ValidFrom, &t.ValidTo, &t.Price)
+		var validTo sql.NullString
+		err := rows.Scan(&t.ValidFrom, &validTo, &t.Price.Amount, &t.Price.Currency)
 		if err != nil {
 			return nil, err
 		}
+		t.ValidTo = validTo.String
 		tDiffs = append(tDiffs, t)
 	}
-	return tDiffs, nil
+	return tDiffs, rows.Err()
 }
+
+// GetByDate returns the version that was stored at rd.DateTime; intervals are half-open.
 func (r *productRepo) GetByDate(ctx context.Context, id uint64, rd *entity.ReferenceDate) (*entity.ProductHistory, error) {
-	byDateQuery := `SELECT * FROM test.product_history WHERE product_id = ? AND ? BETWEEN valid_from AND valid_to ORDER BY valid_from DESC LIMIT 1`
-	ph := &entity.ProductHistory{}
-	err := r.db.QueryRowContext(ctx, byDateQuery, id, rd.DateTime).
-		Scan(&ph.ID, &ph.ProductID, &ph.Name, &ph.Description, &ph.Price, &ph.ValidFrom, &ph.ValidTo)
+	byDateQuery := `SELECT ` + _historyColumns + ` FROM product_history WHERE product_id = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?) ORDER BY valid_from DESC, id DESC LIMIT 1`
+	h, err := scanMySQLHistory(r.conn().QueryRowContext(ctx, byDateQuery, id, rd.DateTime, rd.DateTime))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return h, err
+}
+
+func (r *productRepo) GetPriceIntervals(ctx context.Context, ids []uint64, from, to time.Time) ([]*entity.PriceInterval, error) {
+	query, args, err := priceIntervalsQuery(r.builder, ids, from, to).ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.conn().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	return ph, nil
+	defer rows.Close()
+	var intervals []*entity.PriceInterval
+	for rows.Next() {
+		i := &entity.PriceInterval{}
+		var validFrom string
+		var validTo sql.NullString
+		if err := rows.Scan(&i.ProductID, &i.Price.Amount, &i.Price.Currency, &validFrom, &validTo); err != nil {
+			return nil, err
+		}
+		if i.ValidFrom, err = time.Parse(entity.DateTimeLayout, validFrom); err != nil {
+			return nil, err
+		}
+		if validTo.Valid {
+			if i.ValidTo, err = time.Parse(entity.DateTimeLayout, validTo.String); err != nil {
+				return nil, err
+			}
+		}
+		intervals = append(intervals, i)
+	}
+	return intervals, rows.Err()
+}
+func (r *productRepo) ExportProducts(ctx context.Context, f *entity.ExportFilter, fn func(p *entity.Product) error) error {
+	query, args, err := productExportQuery(r.builder, f).ToSql()
+	if err != nil {
+		return err
+	}
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		p, err := scanMySQLProduct(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+func (r *productRepo) ExportHistory(ctx context.Context, f *entity.ExportFilter, fn func(h *entity.ProductHistory) error) error {
+	query, args, err := historyExportQuery(r.builder, f).ToSql()
+	if err != nil {
+		return err
+	}
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		h, err := scanMySQLHistory(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(h); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// mysqlNotWritten tells why a write matched no row: the product is missing or expected was stale.
+func mysqlNotWritten(ctx context.Context, q rowQueryer, id, expected uint64, cond string) error {
+	var actual uint64
+	err := q.QueryRowContext(ctx, `SELECT version FROM products WHERE id = ? AND `+cond, id).Scan(&actual)
+	if err == sql.ErrNoRows {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return &ConflictError{ID: id, Expected: expected, Actual: actual}
+}
+
+func scanMySQLProduct(row rowScanner) (*entity.Product, error) {
+	p := &entity.Product{}
+	var sku, effectiveFrom, deletedAt sql.NullString
+	err := row.Scan(&p.ID, &sku, &p.Name, &p.Description, &p.Price.Amount, &p.Price.Currency, &effectiveFrom, &p.CreatedAt, &p.UpdatedAt, &p.Version, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	p.SKU = sku.String
+	p.EffectiveFrom = effectiveFrom.String
+	p.DeletedAt = deletedAt.String
+	return p, nil
+}
+
+func scanMySQLHistory(row rowScanner) (*entity.ProductHistory, error) {
+	h := &entity.ProductHistory{}
+	var sku, effectiveFrom, validTo, deletedAt, changedBy, changeReason, requestID sql.NullString
+	err := row.Scan(&h.ID, &h.ProductID, &sku, &h.Name, &h.Description, &h.Price.Amount, &h.Price.Currency, &effectiveFrom, &h.ValidFrom, &validTo, &h.CreatedAt, &h.Version, &deletedAt, &changedBy, &changeReason, &requestID)
+	if err != nil {
+		return nil, err
+	}
+	h.SKU = sku.String
+	h.ChangedBy = changedBy.String
+	h.ChangeReason = changeReason.String
+	h.RequestID = requestID.String
+	h.EffectiveFrom = effectiveFrom.String
+	h.ValidTo = validTo.String
+	h.DeletedAt = deletedAt.String
+	return h, nil
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// changeArgs are the changed_by, change_reason and request_id of the version a write in ctx creates.
+func changeArgs(ctx context.Context) []interface{} {
+	c := entity.ChangeFromContext(ctx)
+	return []interface{}{nullIfEmpty(c.Actor), nullIfEmpty(c.Reason), nullIfEmpty(c.RequestID)}
 }
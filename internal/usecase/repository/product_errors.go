@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
+)
+
+var (
+	// ErrProductNotFound is returned by writes to a product that does not exist or is soft-deleted.
+	ErrProductNotFound = errors.New("product not found")
+	// ErrVersionConflict matches every *ConflictError.
+	ErrVersionConflict = errors.New("product version conflict")
+	// ErrDuplicateSKU is returned by Create and Update when another product already has the SKU.
+	ErrDuplicateSKU = errors.New("product sku already exists")
+)
+
+// ConflictError is returned by Update, Delete and Restore when the expected version of a product
+// or variant is no longer the stored one.
+type ConflictError struct {
+	// Entity names what was written; it is "product" when empty.
+	Entity   string
+	ID       uint64
+	Expected uint64
+	Actual   uint64
+}
+
+func (e *ConflictError) Error() string {
+	entity := e.Entity
+	if entity == "" {
+		entity = "product"
+	}
+
+	return fmt.Sprintf("%s: %s %d is at version %d, expected %d", ErrVersionConflict, entity, e.ID, e.Actual, e.Expected)
+}
+
+// Is makes errors.Is(err, ErrVersionConflict) hold for any conflict.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// Codes of unique and foreign key violations.
+const (
+	_mysqlDuplicateEntry       = 1062
+	_mysqlRowIsReferenced      = 1451
+	_postgresUniqueViolate     = "23505"
+	_postgresForeignKeyViolate = "23503"
+)
+
+// duplicateSKU turns the unique key violation of a product write into ErrDuplicateSKU;
+// sku is the only unique column a client sets.
+func duplicateSKU(err error) error {
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: %v", ErrDuplicateSKU, err)
+	}
+
+	return err
+}
+
+// isUniqueViolation reports whether err is a unique key violation of MySQL or Postgres.
+func isUniqueViolation(err error) bool {
+	var (
+		myErr *mysql.MySQLError
+		pgErr *pgconn.PgError
+	)
+
+	return errors.As(err, &myErr) && myErr.Number == _mysqlDuplicateEntry ||
+		errors.As(err, &pgErr) && pgErr.Code == _postgresUniqueViolate
+}
+
+// isUniqueViolationOf reports whether err is a violation of the unique index named index. MySQL names the
+// index at the end of its message, prefixed by the table since 8.0.
+func isUniqueViolationOf(err error, index string) bool {
+	var (
+		myErr *mysql.MySQLError
+		pgErr *pgconn.PgError
+	)
+
+	return errors.As(err, &myErr) && myErr.Number == _mysqlDuplicateEntry &&
+		(strings.HasSuffix(myErr.Message, "'"+index+"'") || strings.HasSuffix(myErr.Message, "."+index+"'")) ||
+		errors.As(err, &pgErr) && pgErr.Code == _postgresUniqueViolate && pgErr.ConstraintName == index
+}
+
+// isReferenced reports whether err is MySQL or Postgres refusing to delete a row that a foreign key restricts.
+func isReferenced(err error) bool {
+	var (
+		myErr *mysql.MySQLError
+		pgErr *pgconn.PgError
+	)
+
+	return errors.As(err, &myErr) && myErr.Number == _mysqlRowIsReferenced ||
+		errors.As(err, &pgErr) && pgErr.Code == _postgresForeignKeyViolate
+}
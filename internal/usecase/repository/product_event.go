@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"strconv"
+)
+
+//go:generate mockgen -source=product_event.go -destination=../mocks_product_event_test.go -package=usecase_test
+
+// ProductEventRepository reads the outbox the product repositories write an event to with every
+// change of a product, in the transaction of the change.
+type ProductEventRepository interface {
+	// InTx runs fn with a repository whose reads and writes share one transaction, committed when fn returns nil.
+	InTx(ctx context.Context, fn func(repo ProductEventRepository) error) error
+	// Pending returns up to limit events that were not published yet, oldest first. Within InTx they are
+	// claimed until the transaction ends: a concurrent Pending waits for them and then skips those deleted,
+	// so no two relays publish an event at once and a later event is never claimed before an earlier one is done.
+	Pending(ctx context.Context, limit uint64) ([]*entity.OutboxEvent, error)
+	// Delete removes a published event from the outbox.
+	Delete(ctx context.Context, id uint64) error
+}
+
+type productEventRepo struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+const (
+	_mysqlLockProductQuery = `SELECT ` + _productColumns + ` FROM products WHERE id = ? FOR UPDATE`
+	_mysqlInsertEventQuery = `INSERT INTO product_events (event_type, product_id, payload, created_at) VALUES (?, ?, ?, NOW())`
+)
+
+func NewProductEventRepository(db *sql.DB) ProductEventRepository {
+	return &productEventRepo{db: db}
+}
+
+func (r *productEventRepo) InTx(ctx context.Context, fn func(repo ProductEventRepository) error) (err error) {
+	if r.tx != nil {
+		return fn(r)
+	}
+	// At REPEATABLE READ the claim would also lock the gap after the last event, stalling every product
+	// write, as each adds an event, for as long as a batch takes to publish.
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+	return fn(&productEventRepo{db: r.db, tx: tx})
+}
+
+// conn is the transaction of InTx, if any, else the pool.
+func (r *productEventRepo) conn() mysqlConn {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+func (r *productEventRepo) Pending(ctx context.Context, limit uint64) ([]*entity.OutboxEvent, error) {
+	query := `SELECT id, event_type, product_id, payload, created_at FROM product_events ORDER BY id LIMIT ?`
+	if r.tx != nil {
+		query += ` FOR UPDATE`
+	}
+	rows, err := r.conn().QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := []*entity.OutboxEvent{}
+	for rows.Next() {
+		e := &entity.OutboxEvent{}
+		if err := rows.Scan(&e.ID, &e.Type, &e.ProductID, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+func (r *productEventRepo) Delete(ctx context.Context, id uint64) error {
+	_, err := r.conn().ExecContext(ctx, `DELETE FROM product_events WHERE id = ?`, id)
+	return err
+}
+
+// mysqlLockProduct returns the stored state of a product, locked until the end of the transaction of tx,
+// or nil when there is no such product.
+func mysqlLockProduct(ctx context.Context, tx mysqlConn, id uint64) (*entity.Product, error) {
+	p, err := scanMySQLProduct(tx.QueryRowContext(ctx, _mysqlLockProductQuery, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return p, err
+}
+
+// mysqlRecordEvent writes the event of a change of product id to the outbox; before is the product as
+// it was before the change, read with mysqlLockProduct.
+func mysqlRecordEvent(ctx context.Context, tx mysqlConn, eventType string, before *entity.Product, id uint64) error {
+	after, err := mysqlLockProduct(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	payload, err := productEventPayload(ctx, eventType, before, after)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, _mysqlInsertEventQuery, eventType, id, payload)
+	return err
+}
+
+// productEventPayload encodes the event of a change from before to after with the entity.Change in ctx.
+func productEventPayload(ctx context.Context, eventType string, before, after *entity.Product) ([]byte, error) {
+	c := entity.ChangeFromContext(ctx)
+	e := entity.ProductEvent{
+		Type:         eventType,
+		Version:      after.Version,
+		OccurredAt:   after.UpdatedAt,
+		Before:       before,
+		After:        after,
+		ChangedBy:    c.Actor,
+		ChangeReason: c.Reason,
+		RequestID:    c.RequestID,
+	}
+	e.ProductID, _ = strconv.ParseUint(after.ID, 10, 64)
+	return json.Marshal(e)
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/pkg/postgres"
+)
+
+// ProductEventPostgresRepo -.
+type ProductEventPostgresRepo struct {
+	*postgres.Postgres
+	tx pgx.Tx
+}
+
+// NewProductEventPostgresRepository -.
+func NewProductEventPostgresRepository(pg *postgres.Postgres) ProductEventRepository {
+	return &ProductEventPostgresRepo{Postgres: pg}
+}
+
+// InTx -.
+func (r *ProductEventPostgresRepo) InTx(ctx context.Context, fn func(repo ProductEventRepository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ProductEventPostgresRepo - InTx - r.Pool.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	err = fn(&ProductEventPostgresRepo{Postgres: r.Postgres, tx: tx})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("ProductEventPostgresRepo - InTx - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// conn is the transaction of InTx, if any, else the pool.
+func (r *ProductEventPostgresRepo) conn() pgConn {
+	if r.tx != nil {
+		return r.tx
+	}
+
+	return r.Pool
+}
+
+// Pending -.
+func (r *ProductEventPostgresRepo) Pending(ctx context.Context, limit uint64) ([]*entity.OutboxEvent, error) {
+	q := r.Builder.
+		Select("id, event_type, product_id, payload, created_at").
+		From("product_events").
+		OrderBy("id").
+		Limit(limit)
+	if r.tx != nil {
+		// Not SKIP LOCKED: skipping a claimed batch could publish a later event of a product before an earlier one.
+		q = q.Suffix("FOR UPDATE")
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProductEventPostgresRepo - Pending - r.Builder: %w", err)
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ProductEventPostgresRepo - Pending - r.conn().Query: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*entity.OutboxEvent{}
+
+	for rows.Next() {
+		var (
+			e         = &entity.OutboxEvent{}
+			payload   string
+			createdAt time.Time
+		)
+
+		err = rows.Scan(&e.ID, &e.Type, &e.ProductID, &payload, &createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("ProductEventPostgresRepo - Pending - rows.Scan: %w", err)
+		}
+
+		e.Payload = []byte(payload)
+		e.CreatedAt = createdAt.Format(_timeLayout)
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// Delete -.
+func (r *ProductEventPostgresRepo) Delete(ctx context.Context, id uint64) error {
+	sql, args, err := r.Builder.
+		Delete("product_events").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ProductEventPostgresRepo - Delete - r.Builder: %w", err)
+	}
+
+	_, err = r.conn().Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ProductEventPostgresRepo - Delete - r.conn().Exec: %w", err)
+	}
+
+	return nil
+}
+
+// lockProduct returns the stored state of a product, locked until the end of tx, or nil when there is no such product.
+func (r *ProductPostgresRepo) lockProduct(ctx context.Context, tx pgx.Tx, id uint64) (*entity.Product, error) {
+	sql, args, err := r.Builder.
+		Select(_productColumns).
+		From("products").
+		Where(squirrel.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("r.Builder: %w", err)
+	}
+
+	p, err := scanProduct(tx.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("scanProduct: %w", err)
+	}
+
+	return p, nil
+}
+
+// recordEvent writes the event of a change of product id to the outbox; before is the product as it
+// was before the change, read with lockProduct.
+func (r *ProductPostgresRepo) recordEvent(ctx context.Context, tx pgx.Tx, eventType string, before *entity.Product, id uint64) error {
+	after, err := r.lockProduct(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	payload, err := productEventPayload(ctx, eventType, before, after)
+	if err != nil {
+		return fmt.Errorf("productEventPayload: %w", err)
+	}
+
+	sql, args, err := r.Builder.
+		Insert("product_events").
+		Columns("event_type, product_id, payload").
+		Values(eventType, id, string(payload)).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("r.Builder: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"github.com/Masterminds/squirrel"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+// _productAsOfColumns read a version like _productColumns read a product; it was last updated when it became current.
+const _productAsOfColumns = "product_id AS id, sku, name, description, price, currency, effective_from, created_at, valid_from AS updated_at, version, deleted_at"
+
+// productExportQuery selects the products f exports, ordered by id: the stored products, or with
+// f.AsOf the versions that were current at that time.
+func productExportQuery(b squirrel.StatementBuilderType, f *entity.ExportFilter) squirrel.SelectBuilder {
+	updatedAt := "updated_at"
+
+	q := b.Select(_productColumns).From("products").OrderBy("id")
+	if !f.AsOf.IsZero() {
+		updatedAt = "valid_from"
+		q = b.Select(_productAsOfColumns).
+			From("product_history").
+			Where(squirrel.LtOrEq{"valid_from": f.AsOf}).
+			Where(squirrel.Or{squirrel.Eq{"valid_to": nil}, squirrel.Gt{"valid_to": f.AsOf}}).
+			OrderBy("product_id")
+	}
+
+	if !f.IncludeDeleted {
+		q = q.Where(squirrel.Eq{"deleted_at": nil})
+	}
+
+	if !f.UpdatedSince.IsZero() {
+		q = q.Where(squirrel.GtOrEq{updatedAt: f.UpdatedSince})
+	}
+
+	return q
+}
+
+// historyExportQuery selects the versions f exports, ordered by product and time. With f.AsOf only
+// versions that had become current by then are selected; closing them later is left to the caller to hide.
+func historyExportQuery(b squirrel.StatementBuilderType, f *entity.ExportFilter) squirrel.SelectBuilder {
+	q := b.Select(_historyColumns).From("product_history").OrderBy("product_id", "valid_from", "id")
+
+	if !f.AsOf.IsZero() {
+		q = q.Where(squirrel.LtOrEq{"valid_from": f.AsOf})
+	}
+
+	if !f.UpdatedSince.IsZero() {
+		q = q.Where(squirrel.Or{
+			squirrel.GtOrEq{"valid_from": f.UpdatedSince},
+			squirrel.GtOrEq{"valid_to": f.UpdatedSince},
+		})
+	}
+
+	return q
+}
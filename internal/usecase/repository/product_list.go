@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+// Subqueries of the category and tag filters; the category one walks down the tree from the category.
+const (
+	_inCategoryTree = `id IN (SELECT product_id FROM product_categories WHERE category_id IN (
+WITH RECURSIVE tree (id) AS (SELECT id FROM categories WHERE id = ? UNION SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id)
+SELECT id FROM tree))`
+	_hasTag = `id IN (SELECT pt.product_id FROM product_tags pt JOIN tags t ON t.id = pt.tag_id WHERE t.name = ?)`
+)
+
+const (
+	_productColumns = "id, sku, name, description, price, currency, effective_from, created_at, updated_at, version, deleted_at"
+	_historyColumns = "id, product_id, sku, name, description, price, currency, effective_from, valid_from, valid_to, created_at, version, deleted_at, changed_by, change_reason, request_id"
+)
+
+// likeEscaper escapes LIKE wildcards in user input; backslash is the default escape in MySQL and Postgres.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`) //nolint:gochecknoglobals // immutable
+
+// IsProductSortColumn reports whether products may be ordered by column.
+func IsProductSortColumn(column string) bool {
+	switch column {
+	case "id", "name", "description", "price", "created_at", "updated_at":
+		return true
+	default:
+		return false
+	}
+}
+
+// productFilterWhere applies every filter in f except paging to q.
+// ilike selects ILIKE for Postgres; MySQL's LIKE is already case-insensitive.
+func productFilterWhere(q squirrel.SelectBuilder, f *entity.ProductFilter, ilike bool) squirrel.SelectBuilder {
+	if !f.IncludeDeleted {
+		q = q.Where(squirrel.Eq{"deleted_at": nil})
+	}
+
+	if f.Name != "" {
+		pattern := "%" + likeEscaper.Replace(f.Name) + "%"
+		if ilike {
+			q = q.Where(squirrel.ILike{"name": pattern})
+		} else {
+			q = q.Where(squirrel.Like{"name": pattern})
+		}
+	}
+
+	if f.Currency != "" {
+		q = q.Where(squirrel.Eq{"currency": f.Currency})
+	}
+
+	if f.MinPrice != nil {
+		q = q.Where(squirrel.GtOrEq{"price": *f.MinPrice})
+	}
+
+	if f.MaxPrice != nil {
+		q = q.Where(squirrel.LtOrEq{"price": *f.MaxPrice})
+	}
+
+	if !f.CreatedAfter.IsZero() {
+		q = q.Where(squirrel.GtOrEq{"created_at": f.CreatedAfter})
+	}
+
+	if !f.CreatedBefore.IsZero() {
+		q = q.Where(squirrel.Lt{"created_at": f.CreatedBefore})
+	}
+
+	if !f.UpdatedAfter.IsZero() {
+		q = q.Where(squirrel.GtOrEq{"updated_at": f.UpdatedAfter})
+	}
+
+	if !f.UpdatedBefore.IsZero() {
+		q = q.Where(squirrel.Lt{"updated_at": f.UpdatedBefore})
+	}
+
+	if f.CategoryID != 0 {
+		q = q.Where(_inCategoryTree, f.CategoryID)
+	}
+
+	if f.Tag != "" {
+		q = q.Where(_hasTag, f.Tag)
+	}
+
+	return q
+}
+
+// productPageQuery adds the keyset or offset position, ordering and limit to q.
+// Ties on the sort column are broken by id so the order is total.
+func productPageQuery(q squirrel.SelectBuilder, f *entity.ProductFilter) squirrel.SelectBuilder {
+	sortBy := f.SortBy
+	if !IsProductSortColumn(sortBy) {
+		sortBy = "id"
+	}
+
+	dir := " ASC"
+	if f.Desc {
+		dir = " DESC"
+	}
+
+	if f.After != nil {
+		q = q.Where(productKeyset(sortBy, f.Desc, f.After))
+	} else if f.Offset > 0 {
+		q = q.Offset(f.Offset)
+	}
+
+	if sortBy == "id" {
+		q = q.OrderBy("id" + dir)
+	} else {
+		q = q.OrderBy(sortBy+dir, "id"+dir)
+	}
+
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+
+	return q
+}
+
+func productKeyset(sortBy string, desc bool, after *entity.ProductCursor) squirrel.Sqlizer {
+	if sortBy == "id" {
+		if desc {
+			return squirrel.Lt{"id": after.ID}
+		}
+
+		return squirrel.Gt{"id": after.ID}
+	}
+
+	if desc {
+		return squirrel.Or{
+			squirrel.Lt{sortBy: after.Value},
+			squirrel.And{squirrel.Eq{sortBy: after.Value}, squirrel.Lt{"id": after.ID}},
+		}
+	}
+
+	return squirrel.Or{
+		squirrel.Gt{sortBy: after.Value},
+		squirrel.And{squirrel.Eq{sortBy: after.Value}, squirrel.Gt{"id": after.ID}},
+	}
+}
@@ -0,0 +1,863 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/pkg/postgres"
+)
+
+// _timeLayout matches the DATETIME text returned by the MySQL driver,
+// so both implementations serialise timestamps the same way.
+const _timeLayout = entity.DateTimeLayout
+
+// _live matches products that are not soft-deleted.
+var _live = squirrel.Eq{"deleted_at": nil} //nolint:gochecknoglobals // immutable
+
+// ProductPostgresRepo -.
+type ProductPostgresRepo struct {
+	*postgres.Postgres
+	tx pgx.Tx
+}
+
+// pgConn is implemented by the pool and by pgx.Tx, whose Begin starts a savepoint.
+type pgConn interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// NewProductPostgresRepository -.
+func NewProductPostgresRepository(pg *postgres.Postgres) ProductRepository {
+	return &ProductPostgresRepo{Postgres: pg}
+}
+
+// InTx -. Writes within fn begin savepoints of the shared transaction instead of transactions.
+func (r *ProductPostgresRepo) InTx(ctx context.Context, fn func(repo ProductRepository) error) error {
+	tx, err := r.conn().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ProductPostgresRepo - InTx - r.conn().Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	err = fn(&ProductPostgresRepo{Postgres: r.Postgres, tx: tx})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("ProductPostgresRepo - InTx - tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// conn is the transaction of InTx, if any, else the pool.
+func (r *ProductPostgresRepo) conn() pgConn {
+	if r.tx != nil {
+		return r.tx
+	}
+
+	return r.Pool
+}
+
+// Create -.
+func (r *ProductPostgresRepo) Create(ctx context.Context, p *entity.Product) (uint64, error) {
+	tx, err := r.conn().Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ProductPostgresRepo - Create - r.conn().Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	sql, args, err := r.Builder.
+		Insert("products").
+		Columns("sku, name, description, price, currency, effective_from").
+		Values(nullIfEmpty(p.SKU), p.Name, p.Description, p.Price.Amount, p.Price.Currency, nullIfEmpty(p.EffectiveFrom)).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ProductPostgresRepo - Create - r.Builder: %w", err)
+	}
+
+	var id uint64
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ProductPostgresRepo - Create - tx.QueryRow: %w", duplicateSKU(err))
+	}
+
+	// First version, open-ended
+	err = r.openVersion(ctx, tx, id)
+	if err != nil {
+		return 0, fmt.Errorf("ProductPostgresRepo - Create - r.openVersion: %w", err)
+	}
+
+	err = r.addSearchWords(ctx, tx, id)
+	if err != nil {
+		return 0, fmt.Errorf("ProductPostgresRepo - Create - r.addSearchWords: %w", err)
+	}
+
+	err = r.recordEvent(ctx, tx, entity.ProductCreated, nil, id)
+	if err != nil {
+		return 0, fmt.Errorf("ProductPostgresRepo - Create - r.recordEvent: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ProductPostgresRepo - Create - tx.Commit: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetByID -.
+func (r *ProductPostgresRepo) GetByID(ctx context.Context, id uint64, includeDeleted bool) (*entity.Product, error) {
+	q := r.Builder.
+		Select(_productColumns).
+		From("products").
+		Where(squirrel.Eq{"id": id})
+	if !includeDeleted {
+		q = q.Where(_live)
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetByID - r.Builder: %w", err)
+	}
+
+	p, err := scanProduct(r.conn().QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetByID - scanProduct: %w", err)
+	}
+
+	return p, nil
+}
+
+// GetBySKU -.
+func (r *ProductPostgresRepo) GetBySKU(ctx context.Context, sku string) (*entity.Product, error) {
+	sql, args, err := r.Builder.
+		Select(_productColumns).
+		From("products").
+		Where(squirrel.Eq{"sku": sku}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetBySKU - r.Builder: %w", err)
+	}
+
+	p, err := scanProduct(r.conn().QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetBySKU - scanProduct: %w", err)
+	}
+
+	return p, nil
+}
+
+// Update -.
+func (r *ProductPostgresRepo) Update(ctx context.Context, p *entity.Product) error {
+	id, err := strconv.ParseUint(p.ID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("ProductPostgresRepo - Update - strconv.ParseUint: %w", err)
+	}
+
+	err = r.writeVersion(ctx, entity.ProductUpdated, id, p.Version, map[string]interface{}{
+		"sku":            nullIfEmpty(p.SKU),
+		"name":           p.Name,
+		"description":    p.Description,
+		"price":          p.Price.Amount,
+		"currency":       p.Price.Currency,
+		"effective_from": nullIfEmpty(p.EffectiveFrom),
+	}, _live)
+	if err != nil {
+		return fmt.Errorf("ProductPostgresRepo - Update - r.writeVersion: %w", err)
+	}
+
+	return nil
+}
+
+// Delete -.
+func (r *ProductPostgresRepo) Delete(ctx context.Context, id, version uint64) error {
+	err := r.writeVersion(ctx, entity.ProductDeleted, id, version, map[string]interface{}{
+		"deleted_at":     squirrel.Expr("NOW()"),
+		"effective_from": nil,
+	}, _live)
+	if err != nil {
+		return fmt.Errorf("ProductPostgresRepo - Delete - r.writeVersion: %w", err)
+	}
+
+	return nil
+}
+
+// Restore -.
+func (r *ProductPostgresRepo) Restore(ctx context.Context, id, version uint64) error {
+	err := r.writeVersion(ctx, entity.ProductRestored, id, version, map[string]interface{}{
+		"deleted_at":     nil,
+		"effective_from": nil,
+	}, squirrel.NotEq{"deleted_at": nil})
+	if err != nil {
+		return fmt.Errorf("ProductPostgresRepo - Restore - r.writeVersion: %w", err)
+	}
+
+	return nil
+}
+
+// writeVersion applies set to a product matching cond and records the result as its new version and
+// as an event of eventType. Products not matching cond count as missing.
+func (r *ProductPostgresRepo) writeVersion(ctx context.Context, eventType string, id, version uint64,
+	set map[string]interface{}, cond squirrel.Sqlizer,
+) error {
+	tx, err := r.conn().Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("r.conn().Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	before, err := r.lockProduct(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("r.lockProduct: %w", err)
+	}
+
+	// NOW() is the transaction start time, so the closed and the new version share one boundary.
+	sql, args, err := r.Builder.
+		Update("products").
+		SetMap(set).
+		Set("version", squirrel.Expr("version + 1")).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(versionEq(id, version)).
+		Where(cond).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("r.Builder product: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("tx.Exec product: %w", duplicateSKU(err))
+	}
+
+	if tag.RowsAffected() == 0 {
+		return r.notWritten(ctx, tx, id, version, cond)
+	}
+
+	err = r.closeVersion(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("r.closeVersion: %w", err)
+	}
+
+	err = r.openVersion(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("r.openVersion: %w", err)
+	}
+
+	// Only updates change the words of a product.
+	if eventType == entity.ProductUpdated {
+		err = r.addSearchWords(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("r.addSearchWords: %w", err)
+		}
+	}
+
+	err = r.recordEvent(ctx, tx, eventType, before, id)
+	if err != nil {
+		return fmt.Errorf("r.recordEvent: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// List -.
+func (r *ProductPostgresRepo) List(ctx context.Context, f *entity.ProductFilter) ([]*entity.Product, uint64, error) {
+	filtered := productFilterWhere(r.Builder.Select().From("products"), f, true)
+
+	sql, args, err := filtered.Column("COUNT(*)").ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("ProductPostgresRepo - List - r.Builder count: %w", err)
+	}
+
+	var total uint64
+
+	err = r.conn().QueryRow(ctx, sql, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ProductPostgresRepo - List - r.conn().QueryRow: %w", err)
+	}
+
+	sql, args, err = productPageQuery(filtered.Column(_productColumns), f).ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("ProductPostgresRepo - List - r.Builder: %w", err)
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ProductPostgresRepo - List - r.conn().Query: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]*entity.Product, 0, _defaultEntityCap)
+
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("ProductPostgresRepo - List - scanProduct: %w", err)
+		}
+
+		products = append(products, p)
+	}
+
+	return products, total, rows.Err()
+}
+
+// Search -. The weights of search_vector rank name matches above description matches.
+func (r *ProductPostgresRepo) Search(ctx context.Context, s *entity.ProductSearch) ([]*entity.ProductSearchHit, uint64, error) {
+	query := postgresTSQuery(s.Terms)
+	match := squirrel.Expr("search_vector @@ to_tsquery('simple', ?)", query)
+	score := squirrel.Expr("ts_rank(search_vector, to_tsquery('simple', ?))::float8", query)
+
+	count, page, err := productSearchQueries(r.Builder, s, match, score, true)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ProductPostgresRepo - Search - productSearchQueries: %w", err)
+	}
+
+	sql, args, err := count.ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("ProductPostgresRepo - Search - r.Builder count: %w", err)
+	}
+
+	var total uint64
+
+	err = r.conn().QueryRow(ctx, sql, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ProductPostgresRepo - Search - r.conn().QueryRow: %w", err)
+	}
+
+	sql, args, err = page.ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("ProductPostgresRepo - Search - r.Builder: %w", err)
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ProductPostgresRepo - Search - r.conn().Query: %w", err)
+	}
+	defer rows.Close()
+
+	hits := make([]*entity.ProductSearchHit, 0, _defaultEntityCap)
+
+	for rows.Next() {
+		hit := &entity.ProductSearchHit{}
+
+		hit.Product, err = scanProduct(scoredRow{row: rows, score: &hit.Score})
+		if err != nil {
+			return nil, 0, fmt.Errorf("ProductPostgresRepo - Search - scanProduct: %w", err)
+		}
+
+		hits = append(hits, hit)
+	}
+
+	return hits, total, rows.Err()
+}
+
+// SearchWords -.
+func (r *ProductPostgresRepo) SearchWords(ctx context.Context, term string) ([]string, error) {
+	sql, args, err := r.Builder.
+		Select("DISTINCT word").
+		From("product_search_words").
+		Where(squirrel.Eq{"search_key": searchWordKeys(term)}).
+		OrderBy("word").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - SearchWords - r.Builder: %w", err)
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - SearchWords - r.conn().Query: %w", err)
+	}
+	defer rows.Close()
+
+	words := make([]string, 0, _defaultEntityCap)
+
+	for rows.Next() {
+		var word string
+
+		err = rows.Scan(&word)
+		if err != nil {
+			return nil, fmt.Errorf("ProductPostgresRepo - SearchWords - rows.Scan: %w", err)
+		}
+
+		words = append(words, word)
+	}
+
+	return words, rows.Err()
+}
+
+// GetProductHistory -.
+func (r *ProductPostgresRepo) GetProductHistory(ctx context.Context, id uint64) (*entity.Product, []*entity.ProductHistory, error) {
+	sql, args, err := r.Builder.
+		Select(_productColumns).
+		From("products").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, nil, fmt.Errorf("ProductPostgresRepo - GetProductHistory - r.Builder product: %w", err)
+	}
+
+	p, err := scanProduct(r.conn().QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, nil
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("ProductPostgresRepo - GetProductHistory - scanProduct: %w", err)
+	}
+
+	sql, args, err = r.Builder.
+		Select(_historyColumns).
+		From("product_history").
+		Where(squirrel.Eq{"product_id": id}).
+		OrderBy("valid_from", "id").
+		ToSql()
+	if err != nil {
+		return nil, nil, fmt.Errorf("ProductPostgresRepo - GetProductHistory - r.Builder history: %w", err)
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ProductPostgresRepo - GetProductHistory - r.conn().Query: %w", err)
+	}
+	defer rows.Close()
+
+	var history []*entity.ProductHistory
+
+	for rows.Next() {
+		h, err := scanHistory(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ProductPostgresRepo - GetProductHistory - scanHistory: %w", err)
+		}
+
+		history = append(history, h)
+	}
+
+	return p, history, rows.Err()
+}
+
+// GetHighestPrice -.
+func (r *ProductPostgresRepo) GetHighestPrice(ctx context.Context, id uint64) (*entity.ProductMaxValue, error) {
+	sql, args, err := r.Builder.
+		Select("price, currency, valid_from, COALESCE(valid_to, NOW()::timestamp(0))").
+		From("product_history").
+		Where(squirrel.Eq{"product_id": id}).
+		Where(_live).
+		OrderBy("COALESCE(valid_to, NOW()::timestamp(0)) - valid_from DESC", "price DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetHighestPrice - r.Builder: %w", err)
+	}
+
+	var (
+		pMax               = &entity.ProductMaxValue{}
+		validFrom, validTo time.Time
+	)
+
+	err = r.conn().QueryRow(ctx, sql, args...).Scan(&pMax.Price.Amount, &pMax.Price.Currency, &validFrom, &validTo)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetHighestPrice - r.conn().QueryRow: %w", err)
+	}
+
+	pMax.Duration = formatDuration(validTo.Sub(validFrom))
+
+	return pMax, nil
+}
+
+// GetTimeDiff -.
+func (r *ProductPostgresRepo) GetTimeDiff(ctx context.Context, id uint64) ([]*entity.TimeDiff, error) {
+	sql, args, err := r.Builder.
+		Select("valid_from, valid_to, price, currency").
+		From("product_history").
+		Where(squirrel.Eq{"product_id": id}).
+		OrderBy("valid_from", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetTimeDiff - r.Builder: %w", err)
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetTimeDiff - r.conn().Query: %w", err)
+	}
+	defer rows.Close()
+
+	var tDiffs []*entity.TimeDiff
+
+	for rows.Next() {
+		var (
+			t         = &entity.TimeDiff{}
+			validFrom time.Time
+			validTo   *time.Time
+		)
+
+		err = rows.Scan(&validFrom, &validTo, &t.Price.Amount, &t.Price.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("ProductPostgresRepo - GetTimeDiff - rows.Scan: %w", err)
+		}
+
+		t.ValidFrom = validFrom.Format(_timeLayout)
+		t.ValidTo = formatTimePtr(validTo)
+		tDiffs = append(tDiffs, t)
+	}
+
+	return tDiffs, rows.Err()
+}
+
+// GetByDate returns the version that was stored at rd.DateTime; intervals are half-open.
+func (r *ProductPostgresRepo) GetByDate(ctx context.Context, id uint64, rd *entity.ReferenceDate) (*entity.ProductHistory, error) {
+	sql, args, err := r.Builder.
+		Select(_historyColumns).
+		From("product_history").
+		Where(squirrel.Eq{"product_id": id}).
+		Where("valid_from <= ?::timestamp", rd.DateTime).
+		Where("(valid_to IS NULL OR valid_to > ?::timestamp)", rd.DateTime).
+		OrderBy("valid_from DESC", "id DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetByDate - r.Builder: %w", err)
+	}
+
+	ph, err := scanHistory(r.conn().QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetByDate - scanHistory: %w", err)
+	}
+
+	return ph, nil
+}
+
+// GetPriceIntervals -.
+func (r *ProductPostgresRepo) GetPriceIntervals(ctx context.Context, ids []uint64, from, to time.Time) ([]*entity.PriceInterval, error) {
+	sql, args, err := priceIntervalsQuery(r.Builder, ids, from, to).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetPriceIntervals - r.Builder: %w", err)
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetPriceIntervals - r.conn().Query: %w", err)
+	}
+	defer rows.Close()
+
+	var intervals []*entity.PriceInterval
+
+	for rows.Next() {
+		var (
+			i       = &entity.PriceInterval{}
+			validTo *time.Time
+		)
+
+		err = rows.Scan(&i.ProductID, &i.Price.Amount, &i.Price.Currency, &i.ValidFrom, &validTo)
+		if err != nil {
+			return nil, fmt.Errorf("ProductPostgresRepo - GetPriceIntervals - rows.Scan: %w", err)
+		}
+
+		if validTo != nil {
+			i.ValidTo = *validTo
+		}
+
+		intervals = append(intervals, i)
+	}
+
+	return intervals, rows.Err()
+}
+
+// openVersion records the stored state of a product as its current, open-ended version,
+// with the entity.Change in ctx.
+func (r *ProductPostgresRepo) openVersion(ctx context.Context, tx pgx.Tx, id uint64) error {
+	sql, args, err := r.Builder.
+		Insert("product_history").
+		Columns("product_id, sku, name, description, price, currency, effective_from, valid_from, valid_to, created_at, version, deleted_at, changed_by, change_reason, request_id").
+		Select(r.Builder.
+			Select("id, sku, name, description, price, currency, effective_from, updated_at, NULL, created_at, version, deleted_at").
+			Column("?::varchar, ?::varchar, ?::varchar", changeArgs(ctx)...).
+			From("products").
+			Where(squirrel.Eq{"id": id})).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("r.Builder: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// addSearchWords keeps the words of the name and description of a product for SearchWords. Words are
+// never removed: one no product has any more only makes its misspellings search for it in vain.
+func (r *ProductPostgresRepo) addSearchWords(ctx context.Context, tx pgx.Tx, id uint64) error {
+	sql, args, err := r.Builder.
+		Select("name, description").
+		From("products").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("r.Builder product: %w", err)
+	}
+
+	var name, description string
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&name, &description)
+	if err != nil {
+		return fmt.Errorf("tx.QueryRow: %w", err)
+	}
+
+	rows := searchWordRows(name, description)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	insert := r.Builder.
+		Insert("product_search_words").
+		Columns("search_key, word").
+		Suffix("ON CONFLICT DO NOTHING")
+	for _, row := range rows {
+		insert = insert.Values(row[0], row[1])
+	}
+
+	sql, args, err = insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("r.Builder words: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// closeVersion ends the current version of a product at NOW().
+func (r *ProductPostgresRepo) closeVersion(ctx context.Context, tx pgx.Tx, id uint64) error {
+	sql, args, err := r.Builder.
+		Update("product_history").
+		Set("valid_to", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"product_id": id, "valid_to": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("r.Builder: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// versionEq matches product id, and its version unless version is 0.
+func versionEq(id, version uint64) squirrel.Eq {
+	eq := squirrel.Eq{"id": id}
+	if version != 0 {
+		eq["version"] = version
+	}
+
+	return eq
+}
+
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// notWritten tells why a write matched no row: the product is missing or expected was stale.
+func (r *ProductPostgresRepo) notWritten(ctx context.Context, q rowQuerier, id, expected uint64,
+	cond squirrel.Sqlizer,
+) error {
+	sql, args, err := r.Builder.
+		Select("version").
+		From("products").
+		Where(squirrel.Eq{"id": id}).
+		Where(cond).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("r.Builder: %w", err)
+	}
+
+	var actual uint64
+
+	err = q.QueryRow(ctx, sql, args...).Scan(&actual)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrProductNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("q.QueryRow: %w", err)
+	}
+
+	return &ConflictError{ID: id, Expected: expected, Actual: actual}
+}
+
+// ExportProducts -.
+func (r *ProductPostgresRepo) ExportProducts(ctx context.Context, f *entity.ExportFilter, fn func(p *entity.Product) error) error {
+	sql, args, err := productExportQuery(r.Builder, f).ToSql()
+	if err != nil {
+		return fmt.Errorf("ProductPostgresRepo - ExportProducts - r.Builder: %w", err)
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ProductPostgresRepo - ExportProducts - r.conn().Query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return fmt.Errorf("ProductPostgresRepo - ExportProducts - scanProduct: %w", err)
+		}
+
+		err = fn(p)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// ExportHistory -.
+func (r *ProductPostgresRepo) ExportHistory(ctx context.Context, f *entity.ExportFilter, fn func(h *entity.ProductHistory) error) error {
+	sql, args, err := historyExportQuery(r.Builder, f).ToSql()
+	if err != nil {
+		return fmt.Errorf("ProductPostgresRepo - ExportHistory - r.Builder: %w", err)
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ProductPostgresRepo - ExportHistory - r.conn().Query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		h, err := scanHistory(rows)
+		if err != nil {
+			return fmt.Errorf("ProductPostgresRepo - ExportHistory - scanHistory: %w", err)
+		}
+
+		err = fn(h)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func scanProduct(row pgx.Row) (*entity.Product, error) {
+	var (
+		p                    = &entity.Product{}
+		id                   uint64
+		sku                  *string
+		effectiveFrom        *time.Time
+		createdAt, updatedAt time.Time
+		deletedAt            *time.Time
+	)
+
+	err := row.Scan(&id, &sku, &p.Name, &p.Description, &p.Price.Amount, &p.Price.Currency, &effectiveFrom, &createdAt, &updatedAt, &p.Version, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ID = strconv.FormatUint(id, 10)
+	p.SKU = stringOrEmpty(sku)
+	p.EffectiveFrom = formatTimePtr(effectiveFrom)
+	p.CreatedAt = createdAt.Format(_timeLayout)
+	p.UpdatedAt = updatedAt.Format(_timeLayout)
+	p.DeletedAt = formatTimePtr(deletedAt)
+
+	return p, nil
+}
+
+func scanHistory(row pgx.Row) (*entity.ProductHistory, error) {
+	var (
+		h                    = &entity.ProductHistory{}
+		sku, changedBy       *string
+		changeReason         *string
+		requestID            *string
+		effectiveFrom        *time.Time
+		validFrom, createdAt time.Time
+		validTo, deletedAt   *time.Time
+	)
+
+	err := row.Scan(&h.ID, &h.ProductID, &sku, &h.Name, &h.Description, &h.Price.Amount, &h.Price.Currency,
+		&effectiveFrom, &validFrom, &validTo, &createdAt, &h.Version, &deletedAt, &changedBy, &changeReason, &requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	h.SKU = stringOrEmpty(sku)
+	h.ChangedBy = stringOrEmpty(changedBy)
+	h.ChangeReason = stringOrEmpty(changeReason)
+	h.RequestID = stringOrEmpty(requestID)
+	h.EffectiveFrom = formatTimePtr(effectiveFrom)
+	h.ValidFrom = validFrom.Format(_timeLayout)
+	h.ValidTo = formatTimePtr(validTo)
+	h.CreatedAt = createdAt.Format(_timeLayout)
+	h.DeletedAt = formatTimePtr(deletedAt)
+
+	return h, nil
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(_timeLayout)
+}
+
+// formatDuration renders d like MySQL TIMEDIFF does, e.g. "168:00:00".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// priceIntervalsQuery selects the price intervals of the live versions of products that overlap
+// [from, to), by product and oldest first. A zero from leaves the window open at the start.
+func priceIntervalsQuery(b squirrel.StatementBuilderType, ids []uint64, from, to time.Time) squirrel.SelectBuilder {
+	q := b.Select("product_id, price, currency, valid_from, valid_to").
+		From("product_history").
+		Where(squirrel.Eq{"product_id": ids}).
+		Where(_live).
+		Where(squirrel.Lt{"valid_from": to}).
+		OrderBy("product_id", "valid_from", "id")
+
+	if !from.IsZero() {
+		q = q.Where(squirrel.Or{squirrel.Eq{"valid_to": nil}, squirrel.Gt{"valid_to": from}})
+	}
+
+	return q
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+const _scheduledChangeColumns = "id, product_id, name, description, price, currency, effective_at, status, created_at, status_changed_at"
+
+func (r *productRepo) CreateScheduledChange(ctx context.Context, c *entity.ScheduledChange) (uint64, error) {
+	price, currency := scheduledPrice(c)
+	query := `INSERT INTO product_scheduled_changes (product_id, name, description, price, currency, effective_at, status) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	result, err := r.conn().ExecContext(ctx, query, c.ProductID, c.Name, c.Description, price, currency, c.EffectiveAt, entity.ScheduledChangePending)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+
+func (r *productRepo) GetScheduledChange(ctx context.Context, id uint64) (*entity.ScheduledChange, error) {
+	query := `SELECT ` + _scheduledChangeColumns + ` FROM product_scheduled_changes WHERE id = ?`
+	c, err := scanMySQLScheduledChange(r.conn().QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+func (r *productRepo) ListScheduledChanges(ctx context.Context, productID uint64, status string) ([]*entity.ScheduledChange, error) {
+	q := r.builder.Select(_scheduledChangeColumns).
+		From("product_scheduled_changes").
+		Where("product_id = ?", productID).
+		OrderBy("effective_at", "id")
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return r.queryScheduledChanges(ctx, query, args...)
+}
+
+func (r *productRepo) DueScheduledChanges(ctx context.Context, limit uint64) ([]*entity.ScheduledChange, error) {
+	query := `SELECT ` + _scheduledChangeColumns + ` FROM product_scheduled_changes WHERE status = ? AND effective_at <= ? ORDER BY effective_at, id LIMIT ?`
+	return r.queryScheduledChanges(ctx, query, entity.ScheduledChangePending, time.Now().UTC().Format(entity.DateTimeLayout), limit)
+}
+
+func (r *productRepo) TransitionScheduledChange(ctx context.Context, id uint64, from, to string) (bool, error) {
+	query := `UPDATE product_scheduled_changes SET status = ?, status_changed_at = NOW() WHERE id = ? AND status = ?`
+	result, err := r.conn().ExecContext(ctx, query, to, id, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+func (r *productRepo) queryScheduledChanges(ctx context.Context, query string, args ...interface{}) ([]*entity.ScheduledChange, error) {
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var changes []*entity.ScheduledChange
+	for rows.Next() {
+		c, err := scanMySQLScheduledChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+func scanMySQLScheduledChange(row rowScanner) (*entity.ScheduledChange, error) {
+	c := &entity.ScheduledChange{}
+	var (
+		name, description, currency sql.NullString
+		price                       sql.NullInt64
+	)
+	err := row.Scan(&c.ID, &c.ProductID, &name, &description, &price, &currency, &c.EffectiveAt, &c.Status, &c.CreatedAt, &c.StatusChangedAt)
+	if err != nil {
+		return nil, err
+	}
+	if name.Valid {
+		c.Name = &name.String
+	}
+	if description.Valid {
+		c.Description = &description.String
+	}
+	if price.Valid {
+		c.Price = &entity.Money{Amount: price.Int64, Currency: currency.String}
+	}
+	return c, nil
+}
+
+// scheduledPrice splits the price of a change into its nullable columns.
+func scheduledPrice(c *entity.ScheduledChange) (price *int64, currency *string) {
+	if c.Price == nil {
+		return nil, nil
+	}
+	return &c.Price.Amount, &c.Price.Currency
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+// CreateScheduledChange -.
+func (r *ProductPostgresRepo) CreateScheduledChange(ctx context.Context, c *entity.ScheduledChange) (uint64, error) {
+	price, currency := scheduledPrice(c)
+
+	sql, args, err := r.Builder.
+		Insert("product_scheduled_changes").
+		Columns("product_id, name, description, price, currency, effective_at, status").
+		Values(c.ProductID, c.Name, c.Description, price, currency, c.EffectiveAt, entity.ScheduledChangePending).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ProductPostgresRepo - CreateScheduledChange - r.Builder: %w", err)
+	}
+
+	var id uint64
+
+	err = r.conn().QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ProductPostgresRepo - CreateScheduledChange - r.conn().QueryRow: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetScheduledChange -.
+func (r *ProductPostgresRepo) GetScheduledChange(ctx context.Context, id uint64) (*entity.ScheduledChange, error) {
+	sql, args, err := r.Builder.
+		Select(_scheduledChangeColumns).
+		From("product_scheduled_changes").
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetScheduledChange - r.Builder: %w", err)
+	}
+
+	c, err := scanScheduledChange(r.conn().QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - GetScheduledChange - scanScheduledChange: %w", err)
+	}
+
+	return c, nil
+}
+
+// ListScheduledChanges -.
+func (r *ProductPostgresRepo) ListScheduledChanges(ctx context.Context, productID uint64, status string) ([]*entity.ScheduledChange, error) {
+	q := r.Builder.
+		Select(_scheduledChangeColumns).
+		From("product_scheduled_changes").
+		Where(squirrel.Eq{"product_id": productID}).
+		OrderBy("effective_at", "id")
+	if status != "" {
+		q = q.Where(squirrel.Eq{"status": status})
+	}
+
+	changes, err := r.queryScheduledChanges(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - ListScheduledChanges - r.queryScheduledChanges: %w", err)
+	}
+
+	return changes, nil
+}
+
+// DueScheduledChanges -.
+func (r *ProductPostgresRepo) DueScheduledChanges(ctx context.Context, limit uint64) ([]*entity.ScheduledChange, error) {
+	q := r.Builder.
+		Select(_scheduledChangeColumns).
+		From("product_scheduled_changes").
+		Where(squirrel.Eq{"status": entity.ScheduledChangePending}).
+		// effective_at is stored in UTC, whatever the time zone of the session.
+		Where(squirrel.LtOrEq{"effective_at": time.Now().UTC().Format(entity.DateTimeLayout)}).
+		OrderBy("effective_at", "id").
+		Limit(limit)
+
+	changes, err := r.queryScheduledChanges(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("ProductPostgresRepo - DueScheduledChanges - r.queryScheduledChanges: %w", err)
+	}
+
+	return changes, nil
+}
+
+// TransitionScheduledChange -.
+func (r *ProductPostgresRepo) TransitionScheduledChange(ctx context.Context, id uint64, from, to string) (bool, error) {
+	sql, args, err := r.Builder.
+		Update("product_scheduled_changes").
+		Set("status", to).
+		Set("status_changed_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"id": id, "status": from}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("ProductPostgresRepo - TransitionScheduledChange - r.Builder: %w", err)
+	}
+
+	tag, err := r.conn().Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("ProductPostgresRepo - TransitionScheduledChange - r.conn().Exec: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *ProductPostgresRepo) queryScheduledChanges(ctx context.Context, q squirrel.SelectBuilder) ([]*entity.ScheduledChange, error) {
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("r.Builder: %w", err)
+	}
+
+	rows, err := r.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("r.conn().Query: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*entity.ScheduledChange
+
+	for rows.Next() {
+		c, err := scanScheduledChange(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanScheduledChange: %w", err)
+		}
+
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
+
+func scanScheduledChange(row pgx.Row) (*entity.ScheduledChange, error) {
+	var (
+		c                                       = &entity.ScheduledChange{}
+		price                                   *int64
+		currency                                *string
+		effectiveAt, createdAt, statusChangedAt time.Time
+	)
+
+	err := row.Scan(&c.ID, &c.ProductID, &c.Name, &c.Description, &price, &currency,
+		&effectiveAt, &c.Status, &createdAt, &statusChangedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if price != nil && currency != nil {
+		c.Price = &entity.Money{Amount: *price, Currency: *currency}
+	}
+
+	c.EffectiveAt = effectiveAt.Format(_timeLayout)
+	c.CreatedAt = createdAt.Format(_timeLayout)
+	c.StatusChangedAt = statusChangedAt.Format(_timeLayout)
+
+	return c, nil
+}
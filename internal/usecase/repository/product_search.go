@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+// SearchCursorSort is the ProductCursor.SortBy of search results, whose Value is the relevance score.
+const SearchCursorSort = "relevance"
+
+// Lengths of the words of products kept for SearchWords, in characters; shorter words are too common
+// to tell apart by a typo, and longer ones are not words.
+const (
+	_minSearchWordLen = 3
+	_maxSearchWordLen = 64
+)
+
+// scoredRow scans a product row followed by its relevance score.
+type scoredRow struct {
+	row   rowScanner
+	score *float64
+}
+
+func (r scoredRow) Scan(dest ...interface{}) error {
+	return r.row.Scan(append(dest, r.score)...)
+}
+
+// productSearchQueries return the count of the products matching match and the filters of s, and
+// their page ordered by score, highest first, with ties broken by id.
+func productSearchQueries(b squirrel.StatementBuilderType, s *entity.ProductSearch, match, score squirrel.Sqlizer,
+	ilike bool,
+) (count, page squirrel.SelectBuilder, err error) {
+	filtered := productFilterWhere(b.Select().From("products").Where(match), &s.Filter, ilike)
+	matches := filtered.Column(_productColumns).Column(squirrel.Alias(score, "score"))
+
+	page = b.Select(_productColumns+", score").FromSelect(matches, "matches")
+
+	if after := s.Filter.After; after != nil {
+		v, err := strconv.ParseFloat(after.Value, 64)
+		if err != nil {
+			return count, page, fmt.Errorf("search cursor: %w", err)
+		}
+
+		page = page.Where(squirrel.Or{
+			squirrel.Lt{"score": v},
+			squirrel.And{squirrel.Eq{"score": v}, squirrel.Gt{"id": after.ID}},
+		})
+	} else if s.Filter.Offset > 0 {
+		page = page.Offset(s.Filter.Offset)
+	}
+
+	page = page.OrderBy("score DESC", "id ASC")
+	if s.Filter.Limit > 0 {
+		page = page.Limit(s.Filter.Limit)
+	}
+
+	return filtered.Column("COUNT(*)"), page, nil
+}
+
+// mysqlBooleanQuery is the IN BOOLEAN MODE query of terms: every term is required, as any of its prefixes.
+func mysqlBooleanQuery(terms [][]string) string {
+	groups := make([]string, len(terms))
+	for i, prefixes := range terms {
+		groups[i] = "+(" + strings.Join(prefixes, "* ") + "*)"
+	}
+
+	return strings.Join(groups, " ")
+}
+
+// postgresTSQuery is the to_tsquery text of terms, with the same meaning as mysqlBooleanQuery.
+func postgresTSQuery(terms [][]string) string {
+	groups := make([]string, len(terms))
+	for i, prefixes := range terms {
+		groups[i] = "(" + strings.Join(prefixes, ":* | ") + ":*)"
+	}
+
+	return strings.Join(groups, " & ")
+}
+
+// searchWordRows are the product_search_words rows of the words of texts: each distinct lower-case word
+// under each of its searchWordKeys, sorted so that concurrent writers take their locks in one order.
+func searchWordRows(texts ...string) [][2]string {
+	seen := map[[2]string]bool{}
+	var rows [][2]string
+	for _, text := range texts {
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), isNotSearchWordRune) {
+			if n := utf8.RuneCountInString(word); n < _minSearchWordLen || n > _maxSearchWordLen {
+				continue
+			}
+			for _, key := range searchWordKeys(word) {
+				row := [2]string{key, word}
+				if !seen[row] {
+					seen[row] = true
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i][0] < rows[j][0] || rows[i][0] == rows[j][0] && rows[i][1] < rows[j][1]
+	})
+	return rows
+}
+
+// searchWordKeys are the keys a word is stored and looked up under: the word itself and the word less
+// each one of its characters. Words one character replaced, added, removed or swapped with its neighbour
+// apart always share a key.
+func searchWordKeys(word string) []string {
+	runes := []rune(word)
+	keys := []string{word}
+	for i := range runes {
+		keys = append(keys, string(runes[:i])+string(runes[i+1:]))
+	}
+	return keys
+}
+
+func isNotSearchWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Masterminds/squirrel"
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"strings"
+	"time"
+)
+
+//go:generate mockgen -source=product_translation.go -destination=../mocks_product_translation_test.go -package=usecase_test
+
+// ProductTranslationRepository stores the translations of product names and descriptions, at most
+// one per product and locale.
+type ProductTranslationRepository interface {
+	// ListByProduct returns the translations of a product ordered by locale.
+	ListByProduct(ctx context.Context, productID uint64) ([]*entity.ProductTranslation, error)
+	// ListForProducts returns the translations of any of products into any of locales.
+	ListForProducts(ctx context.Context, productIDs []uint64, locales []string) ([]*entity.ProductTranslation, error)
+	// Get returns nil when the product has no translation into locale.
+	Get(ctx context.Context, productID uint64, locale string) (*entity.ProductTranslation, error)
+	// Save stores t over any translation of its product into its locale, except that a machine
+	// translation never replaces a human one. It reports whether t was stored.
+	Save(ctx context.Context, t *entity.ProductTranslation) (bool, error)
+	// Delete reports whether there was a translation to delete.
+	Delete(ctx context.Context, productID uint64, locale string) (bool, error)
+	// Missing returns up to limit live products, each with a locale of locales that it has no translation
+	// into or only a machine translation of an older version of the product. Pairs whose failed translation
+	// is not due for a retry yet are left out, and those that failed fewer times come first, then by product
+	// and locale.
+	Missing(ctx context.Context, locales []string, limit uint64) ([]*entity.ProductLocale, error)
+	// RecordFailure counts a failed machine translation of a product into locale, to be retried from retryAt.
+	RecordFailure(ctx context.Context, productID uint64, locale string, retryAt time.Time) error
+	// ClearFailures forgets the failed machine translations of a product into locale.
+	ClearFailures(ctx context.Context, productID uint64, locale string) error
+}
+
+type productTranslationRepo struct {
+	db      *sql.DB
+	builder squirrel.StatementBuilderType
+}
+
+const (
+	_productTranslationColumns = "product_id, locale, name, description, source, source_version, updated_at"
+	// _mysqlSaveTranslationQuery is completed by the ON DUPLICATE KEY UPDATE clause of the source of the translation.
+	_mysqlSaveTranslationQuery = `INSERT INTO product_translations (product_id, locale, name, description, source, source_version, updated_at) VALUES (?, ?, ?, ?, ?, ?, NOW()) ON DUPLICATE KEY UPDATE `
+	_mysqlSaveHumanTranslation = `name = VALUES(name), description = VALUES(description), source = VALUES(source), source_version = VALUES(source_version), updated_at = NOW()`
+	// _mysqlSaveMachineTranslation keeps human translations; source is assigned last because MySQL
+	// evaluates the assignments in order, so the conditions before it still see the stored source.
+	_mysqlSaveMachineTranslation = `name = IF(source = 'human', name, VALUES(name)), description = IF(source = 'human', description, VALUES(description)), source_version = IF(source = 'human', source_version, VALUES(source_version)), updated_at = IF(source = 'human', updated_at, NOW()), source = IF(source = 'human', source, VALUES(source))`
+)
+
+func NewProductTranslationRepository(db *sql.DB) ProductTranslationRepository {
+	return &productTranslationRepo{db: db, builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question)}
+}
+
+func (r *productTranslationRepo) ListByProduct(ctx context.Context, productID uint64) ([]*entity.ProductTranslation, error) {
+	return r.query(ctx, `SELECT `+_productTranslationColumns+` FROM product_translations WHERE product_id = ? ORDER BY locale`, productID)
+}
+func (r *productTranslationRepo) ListForProducts(ctx context.Context, productIDs []uint64, locales []string) ([]*entity.ProductTranslation, error) {
+	if len(productIDs) == 0 || len(locales) == 0 {
+		return []*entity.ProductTranslation{}, nil
+	}
+	query, args, err := r.builder.Select(_productTranslationColumns).From("product_translations").
+		Where(squirrel.Eq{"product_id": productIDs, "locale": locales}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return r.query(ctx, query, args...)
+}
+func (r *productTranslationRepo) Get(ctx context.Context, productID uint64, locale string) (*entity.ProductTranslation, error) {
+	query := `SELECT ` + _productTranslationColumns + ` FROM product_translations WHERE product_id = ? AND locale = ?`
+	t, err := scanMySQLProductTranslation(r.db.QueryRowContext(ctx, query, productID, locale))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+func (r *productTranslationRepo) Save(ctx context.Context, t *entity.ProductTranslation) (bool, error) {
+	query := _mysqlSaveTranslationQuery + _mysqlSaveHumanTranslation
+	if t.Source == entity.TranslationSourceMachine {
+		query = _mysqlSaveTranslationQuery + _mysqlSaveMachineTranslation
+	}
+	var sourceVersion interface{}
+	if t.SourceVersion != 0 {
+		sourceVersion = t.SourceVersion
+	}
+	result, err := r.db.ExecContext(ctx, query, t.ProductID, t.Locale, t.Name, t.Description, t.Source, sourceVersion)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+func (r *productTranslationRepo) Delete(ctx context.Context, productID uint64, locale string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM product_translations WHERE product_id = ? AND locale = ?`, productID, locale)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+func (r *productTranslationRepo) Missing(ctx context.Context, locales []string, limit uint64) ([]*entity.ProductLocale, error) {
+	if len(locales) == 0 {
+		return []*entity.ProductLocale{}, nil
+	}
+	// The locales are a derived table of one row each
+	selects := make([]string, len(locales))
+	args := make([]interface{}, 0, len(locales)+1)
+	for i, locale := range locales {
+		selects[i] = `SELECT ? AS locale`
+		args = append(args, locale)
+	}
+	// retry_at is stored in UTC, whatever the time zone of the session
+	query := `SELECT p.id, l.locale, COALESCE(f.attempts, 0) FROM products p CROSS JOIN (` + strings.Join(selects, ` UNION ALL `) + `) l LEFT JOIN product_translations t ON t.product_id = p.id AND t.locale = l.locale LEFT JOIN product_translation_failures f ON f.product_id = p.id AND f.locale = l.locale WHERE p.deleted_at IS NULL AND (t.product_id IS NULL OR (t.source = 'machine' AND t.source_version < p.version)) AND (f.retry_at IS NULL OR f.retry_at <= ?) ORDER BY COALESCE(f.attempts, 0), p.id, l.locale LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, append(args, time.Now().UTC().Format(entity.DateTimeLayout), limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	missing := []*entity.ProductLocale{}
+	for rows.Next() {
+		m := &entity.ProductLocale{}
+		if err := rows.Scan(&m.ProductID, &m.Locale, &m.Attempts); err != nil {
+			return nil, err
+		}
+		missing = append(missing, m)
+	}
+	return missing, rows.Err()
+}
+func (r *productTranslationRepo) RecordFailure(ctx context.Context, productID uint64, locale string, retryAt time.Time) error {
+	query := `INSERT INTO product_translation_failures (product_id, locale, attempts, last_attempt_at, retry_at) VALUES (?, ?, 1, ?, ?) ON DUPLICATE KEY UPDATE attempts = attempts + 1, last_attempt_at = VALUES(last_attempt_at), retry_at = VALUES(retry_at)`
+	_, err := r.db.ExecContext(ctx, query, productID, locale, time.Now().UTC().Format(entity.DateTimeLayout), retryAt.UTC().Format(entity.DateTimeLayout))
+	return err
+}
+func (r *productTranslationRepo) ClearFailures(ctx context.Context, productID uint64, locale string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM product_translation_failures WHERE product_id = ? AND locale = ?`, productID, locale)
+	return err
+}
+func (r *productTranslationRepo) query(ctx context.Context, query string, args ...interface{}) ([]*entity.ProductTranslation, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	translations := []*entity.ProductTranslation{}
+	for rows.Next() {
+		t, err := scanMySQLProductTranslation(rows)
+		if err != nil {
+			return nil, err
+		}
+		translations = append(translations, t)
+	}
+	return translations, rows.Err()
+}
+
+func scanMySQLProductTranslation(row rowScanner) (*entity.ProductTranslation, error) {
+	t := &entity.ProductTranslation{}
+	var sourceVersion sql.NullInt64
+	if err := row.Scan(&t.ProductID, &t.Locale, &t.Name, &t.Description, &t.Source, &sourceVersion, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	t.SourceVersion = uint64(sourceVersion.Int64)
+	return t, nil
+}
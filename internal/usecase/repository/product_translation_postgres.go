@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/pkg/postgres"
+)
+
+// ProductTranslationPostgresRepo -.
+type ProductTranslationPostgresRepo struct {
+	*postgres.Postgres
+}
+
+// NewProductTranslationPostgresRepository -.
+func NewProductTranslationPostgresRepository(pg *postgres.Postgres) ProductTranslationRepository {
+	return &ProductTranslationPostgresRepo{pg}
+}
+
+// ListByProduct -.
+func (r *ProductTranslationPostgresRepo) ListByProduct(ctx context.Context, productID uint64) ([]*entity.ProductTranslation, error) {
+	q := r.Builder.
+		Select(_productTranslationColumns).
+		From("product_translations").
+		Where(squirrel.Eq{"product_id": productID}).
+		OrderBy("locale")
+
+	translations, err := r.query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("ProductTranslationPostgresRepo - ListByProduct - r.query: %w", err)
+	}
+
+	return translations, nil
+}
+
+// ListForProducts -.
+func (r *ProductTranslationPostgresRepo) ListForProducts(ctx context.Context, productIDs []uint64, locales []string,
+) ([]*entity.ProductTranslation, error) {
+	if len(productIDs) == 0 || len(locales) == 0 {
+		return []*entity.ProductTranslation{}, nil
+	}
+
+	q := r.Builder.
+		Select(_productTranslationColumns).
+		From("product_translations").
+		Where(squirrel.Eq{"product_id": productIDs, "locale": locales})
+
+	translations, err := r.query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("ProductTranslationPostgresRepo - ListForProducts - r.query: %w", err)
+	}
+
+	return translations, nil
+}
+
+// Get -.
+func (r *ProductTranslationPostgresRepo) Get(ctx context.Context, productID uint64, locale string) (*entity.ProductTranslation, error) {
+	sql, args, err := r.Builder.
+		Select(_productTranslationColumns).
+		From("product_translations").
+		Where(squirrel.Eq{"product_id": productID, "locale": locale}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProductTranslationPostgresRepo - Get - r.Builder: %w", err)
+	}
+
+	t, err := scanProductTranslation(r.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ProductTranslationPostgresRepo - Get - scanProductTranslation: %w", err)
+	}
+
+	return t, nil
+}
+
+// Save -. A machine translation only updates a stored machine translation.
+func (r *ProductTranslationPostgresRepo) Save(ctx context.Context, t *entity.ProductTranslation) (bool, error) {
+	var sourceVersion interface{}
+	if t.SourceVersion != 0 {
+		sourceVersion = t.SourceVersion
+	}
+
+	upsert := "ON CONFLICT (product_id, locale) DO UPDATE SET name = EXCLUDED.name, description = EXCLUDED.description, " +
+		"source = EXCLUDED.source, source_version = EXCLUDED.source_version, updated_at = EXCLUDED.updated_at"
+	if t.Source == entity.TranslationSourceMachine {
+		upsert += " WHERE product_translations.source <> '" + entity.TranslationSourceHuman + "'"
+	}
+
+	sql, args, err := r.Builder.
+		Insert("product_translations").
+		Columns("product_id, locale, name, description, source, source_version, updated_at").
+		Values(t.ProductID, t.Locale, t.Name, t.Description, t.Source, sourceVersion, squirrel.Expr("NOW()")).
+		Suffix(upsert).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("ProductTranslationPostgresRepo - Save - r.Builder: %w", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("ProductTranslationPostgresRepo - Save - r.Pool.Exec: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// Delete -.
+func (r *ProductTranslationPostgresRepo) Delete(ctx context.Context, productID uint64, locale string) (bool, error) {
+	sql, args, err := r.Builder.
+		Delete("product_translations").
+		Where(squirrel.Eq{"product_id": productID, "locale": locale}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("ProductTranslationPostgresRepo - Delete - r.Builder: %w", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("ProductTranslationPostgresRepo - Delete - r.Pool.Exec: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// Missing -.
+func (r *ProductTranslationPostgresRepo) Missing(ctx context.Context, locales []string, limit uint64) ([]*entity.ProductLocale, error) {
+	if len(locales) == 0 {
+		return []*entity.ProductLocale{}, nil
+	}
+
+	sql, args, err := r.Builder.
+		Select("p.id, l.locale, COALESCE(f.attempts, 0)").
+		From("products p").
+		JoinClause("CROSS JOIN unnest(?::text[]) AS l(locale)", locales).
+		LeftJoin("product_translations t ON t.product_id = p.id AND t.locale = l.locale").
+		LeftJoin("product_translation_failures f ON f.product_id = p.id AND f.locale = l.locale").
+		Where(squirrel.Eq{"p.deleted_at": nil}).
+		Where(squirrel.Or{
+			squirrel.Eq{"t.product_id": nil},
+			squirrel.And{
+				squirrel.Eq{"t.source": entity.TranslationSourceMachine},
+				squirrel.Expr("t.source_version < p.version"),
+			},
+		}).
+		// retry_at is stored in UTC, whatever the time zone of the session.
+		Where(squirrel.Or{
+			squirrel.Eq{"f.retry_at": nil},
+			squirrel.LtOrEq{"f.retry_at": time.Now().UTC().Format(entity.DateTimeLayout)},
+		}).
+		OrderBy("COALESCE(f.attempts, 0)", "p.id", "l.locale").
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProductTranslationPostgresRepo - Missing - r.Builder: %w", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ProductTranslationPostgresRepo - Missing - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	missing := []*entity.ProductLocale{}
+
+	for rows.Next() {
+		m := &entity.ProductLocale{}
+
+		err = rows.Scan(&m.ProductID, &m.Locale, &m.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("ProductTranslationPostgresRepo - Missing - rows.Scan: %w", err)
+		}
+
+		missing = append(missing, m)
+	}
+
+	return missing, rows.Err()
+}
+
+// RecordFailure -.
+func (r *ProductTranslationPostgresRepo) RecordFailure(ctx context.Context, productID uint64, locale string, retryAt time.Time) error {
+	sql, args, err := r.Builder.
+		Insert("product_translation_failures").
+		Columns("product_id, locale, attempts, last_attempt_at, retry_at").
+		Values(productID, locale, 1, time.Now().UTC().Format(entity.DateTimeLayout), retryAt.UTC().Format(entity.DateTimeLayout)).
+		Suffix("ON CONFLICT (product_id, locale) DO UPDATE SET attempts = product_translation_failures.attempts + 1, " +
+			"last_attempt_at = EXCLUDED.last_attempt_at, retry_at = EXCLUDED.retry_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ProductTranslationPostgresRepo - RecordFailure - r.Builder: %w", err)
+	}
+
+	_, err = r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ProductTranslationPostgresRepo - RecordFailure - r.Pool.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// ClearFailures -.
+func (r *ProductTranslationPostgresRepo) ClearFailures(ctx context.Context, productID uint64, locale string) error {
+	sql, args, err := r.Builder.
+		Delete("product_translation_failures").
+		Where(squirrel.Eq{"product_id": productID, "locale": locale}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ProductTranslationPostgresRepo - ClearFailures - r.Builder: %w", err)
+	}
+
+	_, err = r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ProductTranslationPostgresRepo - ClearFailures - r.Pool.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ProductTranslationPostgresRepo) query(ctx context.Context, q squirrel.SelectBuilder) ([]*entity.ProductTranslation, error) {
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := []*entity.ProductTranslation{}
+
+	for rows.Next() {
+		t, err := scanProductTranslation(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		translations = append(translations, t)
+	}
+
+	return translations, rows.Err()
+}
+
+func scanProductTranslation(row pgx.Row) (*entity.ProductTranslation, error) {
+	var (
+		t             = &entity.ProductTranslation{}
+		sourceVersion *uint64
+		updatedAt     time.Time
+	)
+
+	err := row.Scan(&t.ProductID, &t.Locale, &t.Name, &t.Description, &t.Source, &sourceVersion, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if sourceVersion != nil {
+		t.SourceVersion = *sourceVersion
+	}
+
+	t.UpdatedAt = updatedAt.Format(_timeLayout)
+
+	return t, nil
+}
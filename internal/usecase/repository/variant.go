@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+//go:generate mockgen -source=variant.go -destination=../mocks_variant_test.go -package=usecase_test
+
+var (
+	// ErrVariantNotFound is returned by writes to a variant that does not exist or is soft-deleted.
+	ErrVariantNotFound = errors.New("variant not found")
+	// ErrDuplicateVariantSKU is returned by Create and Update when another variant already has the SKU.
+	ErrDuplicateVariantSKU = errors.New("variant sku already exists")
+	// ErrDuplicateVariantAttributes is returned by Create and Update when another live variant of the
+	// product has the same attributes.
+	ErrDuplicateVariantAttributes = errors.New("variant attributes already exist")
+)
+
+// _variantAttributesIndex is the unique index of the attributes_key of live variants.
+const _variantAttributesIndex = "product_variants_attributes_idx"
+
+// VariantRepository stores the variants of products and their versions, like ProductRepository
+// stores products: every write closes the current version of the variant and opens the next one
+// with the entity.Change carried by its ctx.
+type VariantRepository interface {
+	Create(ctx context.Context, v *entity.Variant) (uint64, error)
+	// GetByID returns nil for unknown variants, and for soft-deleted ones unless includeDeleted is set.
+	GetByID(ctx context.Context, id uint64, includeDeleted bool) (*entity.Variant, error)
+	// GetBySKU returns the variant with a SKU, soft-deleted or not, or nil when there is none.
+	GetBySKU(ctx context.Context, sku string) (*entity.Variant, error)
+	// ListByProduct returns the variants of a product ordered by id.
+	ListByProduct(ctx context.Context, productID uint64, includeDeleted bool) ([]*entity.Variant, error)
+	// Update stores the sku, attributes and price of v as its next version. A non-zero v.Version is
+	// checked like in ProductRepository.Update; unknown and soft-deleted variants give ErrVariantNotFound.
+	Update(ctx context.Context, v *entity.Variant) error
+	// Delete soft-deletes a variant, recording the deletion as its last version.
+	Delete(ctx context.Context, id, version uint64) error
+	// GetHistory returns the versions of a variant, oldest first.
+	GetHistory(ctx context.Context, id uint64) ([]*entity.VariantHistory, error)
+	// GetAt returns the version of a variant that was current at at, or nil when there was none.
+	GetAt(ctx context.Context, id uint64, at string) (*entity.VariantHistory, error)
+}
+
+type variantRepo struct {
+	db *sql.DB
+}
+
+const (
+	_variantColumns        = "id, product_id, sku, attributes, price, currency, created_at, updated_at, version, deleted_at"
+	_variantHistoryColumns = "id, variant_id, product_id, sku, attributes, price, currency, valid_from, valid_to, version, deleted_at, changed_by, change_reason, request_id"
+	// _mysqlOpenVariantVersionQuery records the stored state of a variant as its current, open-ended version; its arguments are changeArgs and the id.
+	_mysqlOpenVariantVersionQuery = `INSERT INTO variant_history (variant_id, product_id, sku, attributes, price, currency, valid_from, valid_to, version, deleted_at, changed_by, change_reason, request_id) SELECT id, product_id, sku, attributes, price, currency, updated_at, NULL, version, deleted_at, ?, ?, ? FROM product_variants WHERE id = ?`
+)
+
+func NewVariantRepository(db *sql.DB) VariantRepository {
+	return &variantRepo{db: db}
+}
+
+func (r *variantRepo) Create(ctx context.Context, v *entity.Variant) (id uint64, err error) {
+	attributes, err := encodeAttributes(v.Attributes)
+	if err != nil {
+		return 0, err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query := `INSERT INTO product_variants (product_id, sku, attributes, attributes_key, price, currency, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, NOW(), NOW())`
+	result, err := tx.ExecContext(ctx, query, v.ProductID, v.SKU, attributes, attributesKey(attributes), v.Price.Amount, v.Price.Currency)
+	if err != nil {
+		return 0, duplicateVariant(err)
+	}
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	// First version, open-ended
+	if _, err = tx.ExecContext(ctx, _mysqlOpenVariantVersionQuery, append(changeArgs(ctx), lastID)...); err != nil {
+		return 0, err
+	}
+	return uint64(lastID), nil
+}
+func (r *variantRepo) GetByID(ctx context.Context, id uint64, includeDeleted bool) (*entity.Variant, error) {
+	query := `SELECT ` + _variantColumns + ` FROM product_variants WHERE id = ?`
+	if !includeDeleted {
+		query += ` AND ` + _mysqlLive
+	}
+	v, err := scanMySQLVariant(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return v, err
+}
+func (r *variantRepo) GetBySKU(ctx context.Context, sku string) (*entity.Variant, error) {
+	v, err := scanMySQLVariant(r.db.QueryRowContext(ctx, `SELECT `+_variantColumns+` FROM product_variants WHERE sku = ?`, sku))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return v, err
+}
+func (r *variantRepo) ListByProduct(ctx context.Context, productID uint64, includeDeleted bool) ([]*entity.Variant, error) {
+	query := `SELECT ` + _variantColumns + ` FROM product_variants WHERE product_id = ?`
+	if !includeDeleted {
+		query += ` AND ` + _mysqlLive
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY id`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	variants := []*entity.Variant{}
+	for rows.Next() {
+		v, err := scanMySQLVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+	return variants, rows.Err()
+}
+func (r *variantRepo) Update(ctx context.Context, v *entity.Variant) error {
+	attributes, err := encodeAttributes(v.Attributes)
+	if err != nil {
+		return err
+	}
+	return r.writeVersion(ctx, v.ID, v.Version, `sku = ?, attributes = ?, attributes_key = ?, price = ?, currency = ?`, v.SKU, attributes, attributesKey(attributes), v.Price.Amount, v.Price.Currency)
+}
+func (r *variantRepo) Delete(ctx context.Context, id, version uint64) error {
+	return r.writeVersion(ctx, id, version, `deleted_at = NOW()`)
+}
+
+// writeVersion applies set to a live variant and records the result as its new version.
+func (r *variantRepo) writeVersion(ctx context.Context, id, version uint64, set string, setArgs ...interface{}) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query := `UPDATE product_variants SET ` + set + `, version = version + 1, updated_at = NOW() WHERE id = ? AND ` + _mysqlLive
+	args := append(append([]interface{}{}, setArgs...), id)
+	if version != 0 {
+		query += ` AND version = ?`
+		args = append(args, version)
+	}
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return duplicateVariant(err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		var actual uint64
+		err = tx.QueryRowContext(ctx, `SELECT version FROM product_variants WHERE id = ? AND `+_mysqlLive, id).Scan(&actual)
+		if err == sql.ErrNoRows {
+			return ErrVariantNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return &ConflictError{Entity: "variant", ID: id, Expected: version, Actual: actual}
+	}
+
+	// Close the previous version at the moment the new one became current
+	query = `UPDATE variant_history h JOIN product_variants v ON v.id = h.variant_id SET h.valid_to = v.updated_at WHERE h.variant_id = ? AND h.valid_to IS NULL`
+	if _, err = tx.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, _mysqlOpenVariantVersionQuery, append(changeArgs(ctx), id)...)
+	return err
+}
+func (r *variantRepo) GetHistory(ctx context.Context, id uint64) ([]*entity.VariantHistory, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+_variantHistoryColumns+` FROM variant_history WHERE variant_id = ? ORDER BY valid_from, id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var history []*entity.VariantHistory
+	for rows.Next() {
+		h, err := scanMySQLVariantHistory(rows)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// GetAt returns the version that was stored at at; intervals are half-open.
+func (r *variantRepo) GetAt(ctx context.Context, id uint64, at string) (*entity.VariantHistory, error) {
+	query := `SELECT ` + _variantHistoryColumns + ` FROM variant_history WHERE variant_id = ? AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?) ORDER BY valid_from DESC, id DESC LIMIT 1`
+	h, err := scanMySQLVariantHistory(r.db.QueryRowContext(ctx, query, id, at, at))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return h, err
+}
+
+// duplicateVariant turns the unique key violations of a variant write into ErrDuplicateVariantAttributes
+// or ErrDuplicateVariantSKU.
+func duplicateVariant(err error) error {
+	switch {
+	case isUniqueViolationOf(err, _variantAttributesIndex):
+		return fmt.Errorf("%w: %v", ErrDuplicateVariantAttributes, err)
+	case isUniqueViolation(err):
+		return fmt.Errorf("%w: %v", ErrDuplicateVariantSKU, err)
+	}
+	return err
+}
+
+// encodeAttributes is the attributes column of a variant; no attributes are stored as an empty object.
+func encodeAttributes(attributes map[string]string) (string, error) {
+	if attributes == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(attributes)
+	return string(b), err
+}
+
+// attributesKey is the attributes_key column of encoded attributes. Maps are encoded with sorted names,
+// so equal attributes have equal keys; the hash keeps the key short enough to index.
+func attributesKey(attributes string) string {
+	sum := sha256.Sum256([]byte(attributes))
+	return hex.EncodeToString(sum[:])
+}
+
+// decodeAttributes reads the attributes column; an empty object gives an empty, non-nil map.
+func decodeAttributes(b []byte) (map[string]string, error) {
+	attributes := map[string]string{}
+	if len(b) == 0 {
+		return attributes, nil
+	}
+	if err := json.Unmarshal(b, &attributes); err != nil {
+		return nil, fmt.Errorf("attributes: %w", err)
+	}
+	return attributes, nil
+}
+
+func scanMySQLVariant(row rowScanner) (*entity.Variant, error) {
+	v := &entity.Variant{}
+	var attributes []byte
+	var deletedAt sql.NullString
+	err := row.Scan(&v.ID, &v.ProductID, &v.SKU, &attributes, &v.Price.Amount, &v.Price.Currency, &v.CreatedAt, &v.UpdatedAt, &v.Version, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	if v.Attributes, err = decodeAttributes(attributes); err != nil {
+		return nil, err
+	}
+	v.DeletedAt = deletedAt.String
+	return v, nil
+}
+
+func scanMySQLVariantHistory(row rowScanner) (*entity.VariantHistory, error) {
+	h := &entity.VariantHistory{}
+	var attributes []byte
+	var validTo, deletedAt, changedBy, changeReason, requestID sql.NullString
+	err := row.Scan(&h.ID, &h.VariantID, &h.ProductID, &h.SKU, &attributes, &h.Price.Amount, &h.Price.Currency, &h.ValidFrom, &validTo, &h.Version, &deletedAt, &changedBy, &changeReason, &requestID)
+	if err != nil {
+		return nil, err
+	}
+	if h.Attributes, err = decodeAttributes(attributes); err != nil {
+		return nil, err
+	}
+	h.ValidTo = validTo.String
+	h.DeletedAt = deletedAt.String
+	h.ChangedBy = changedBy.String
+	h.ChangeReason = changeReason.String
+	h.RequestID = requestID.String
+	return h, nil
+}
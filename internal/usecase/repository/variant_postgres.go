@@ -0,0 +1,410 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/pkg/postgres"
+)
+
+// VariantPostgresRepo -.
+type VariantPostgresRepo struct {
+	*postgres.Postgres
+}
+
+// NewVariantPostgresRepository -.
+func NewVariantPostgresRepository(pg *postgres.Postgres) VariantRepository {
+	return &VariantPostgresRepo{pg}
+}
+
+// Create -.
+func (r *VariantPostgresRepo) Create(ctx context.Context, v *entity.Variant) (uint64, error) {
+	attributes, err := encodeAttributes(v.Attributes)
+	if err != nil {
+		return 0, fmt.Errorf("VariantPostgresRepo - Create - encodeAttributes: %w", err)
+	}
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("VariantPostgresRepo - Create - r.Pool.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	sql, args, err := r.Builder.
+		Insert("product_variants").
+		Columns("product_id, sku, attributes, attributes_key, price, currency").
+		Values(v.ProductID, v.SKU, squirrel.Expr("?::jsonb", attributes), attributesKey(attributes), v.Price.Amount, v.Price.Currency).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("VariantPostgresRepo - Create - r.Builder: %w", err)
+	}
+
+	var id uint64
+
+	err = tx.QueryRow(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("VariantPostgresRepo - Create - tx.QueryRow: %w", duplicateVariant(err))
+	}
+
+	// First version, open-ended
+	err = r.openVersion(ctx, tx, id)
+	if err != nil {
+		return 0, fmt.Errorf("VariantPostgresRepo - Create - r.openVersion: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("VariantPostgresRepo - Create - tx.Commit: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetByID -.
+func (r *VariantPostgresRepo) GetByID(ctx context.Context, id uint64, includeDeleted bool) (*entity.Variant, error) {
+	q := r.Builder.
+		Select(_variantColumns).
+		From("product_variants").
+		Where(squirrel.Eq{"id": id})
+	if !includeDeleted {
+		q = q.Where(_live)
+	}
+
+	v, err := r.getVariant(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("VariantPostgresRepo - GetByID - r.getVariant: %w", err)
+	}
+
+	return v, nil
+}
+
+// GetBySKU -.
+func (r *VariantPostgresRepo) GetBySKU(ctx context.Context, sku string) (*entity.Variant, error) {
+	q := r.Builder.
+		Select(_variantColumns).
+		From("product_variants").
+		Where(squirrel.Eq{"sku": sku})
+
+	v, err := r.getVariant(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("VariantPostgresRepo - GetBySKU - r.getVariant: %w", err)
+	}
+
+	return v, nil
+}
+
+// ListByProduct -.
+func (r *VariantPostgresRepo) ListByProduct(ctx context.Context, productID uint64, includeDeleted bool) ([]*entity.Variant, error) {
+	q := r.Builder.
+		Select(_variantColumns).
+		From("product_variants").
+		Where(squirrel.Eq{"product_id": productID}).
+		OrderBy("id")
+	if !includeDeleted {
+		q = q.Where(_live)
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("VariantPostgresRepo - ListByProduct - r.Builder: %w", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("VariantPostgresRepo - ListByProduct - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	variants := []*entity.Variant{}
+
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("VariantPostgresRepo - ListByProduct - scanVariant: %w", err)
+		}
+
+		variants = append(variants, v)
+	}
+
+	return variants, rows.Err()
+}
+
+// Update -.
+func (r *VariantPostgresRepo) Update(ctx context.Context, v *entity.Variant) error {
+	attributes, err := encodeAttributes(v.Attributes)
+	if err != nil {
+		return fmt.Errorf("VariantPostgresRepo - Update - encodeAttributes: %w", err)
+	}
+
+	err = r.writeVersion(ctx, v.ID, v.Version, map[string]interface{}{
+		"sku":            v.SKU,
+		"attributes":     squirrel.Expr("?::jsonb", attributes),
+		"attributes_key": attributesKey(attributes),
+		"price":          v.Price.Amount,
+		"currency":       v.Price.Currency,
+	})
+	if err != nil {
+		return fmt.Errorf("VariantPostgresRepo - Update - r.writeVersion: %w", err)
+	}
+
+	return nil
+}
+
+// Delete -.
+func (r *VariantPostgresRepo) Delete(ctx context.Context, id, version uint64) error {
+	err := r.writeVersion(ctx, id, version, map[string]interface{}{
+		"deleted_at": squirrel.Expr("NOW()"),
+	})
+	if err != nil {
+		return fmt.Errorf("VariantPostgresRepo - Delete - r.writeVersion: %w", err)
+	}
+
+	return nil
+}
+
+// writeVersion applies set to a live variant and records the result as its new version.
+func (r *VariantPostgresRepo) writeVersion(ctx context.Context, id, version uint64, set map[string]interface{}) error {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("r.Pool.Begin: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	// NOW() is the transaction start time, so the closed and the new version share one boundary.
+	sql, args, err := r.Builder.
+		Update("product_variants").
+		SetMap(set).
+		Set("version", squirrel.Expr("version + 1")).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Where(versionEq(id, version)).
+		Where(_live).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("r.Builder variant: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("tx.Exec variant: %w", duplicateVariant(err))
+	}
+
+	if tag.RowsAffected() == 0 {
+		return r.notWritten(ctx, tx, id, version)
+	}
+
+	sql, args, err = r.Builder.
+		Update("variant_history").
+		Set("valid_to", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{"variant_id": id, "valid_to": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("r.Builder close: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("tx.Exec close: %w", err)
+	}
+
+	err = r.openVersion(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("r.openVersion: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	return nil
+}
+
+// GetHistory -.
+func (r *VariantPostgresRepo) GetHistory(ctx context.Context, id uint64) ([]*entity.VariantHistory, error) {
+	sql, args, err := r.Builder.
+		Select(_variantHistoryColumns).
+		From("variant_history").
+		Where(squirrel.Eq{"variant_id": id}).
+		OrderBy("valid_from", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("VariantPostgresRepo - GetHistory - r.Builder: %w", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("VariantPostgresRepo - GetHistory - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var history []*entity.VariantHistory
+
+	for rows.Next() {
+		h, err := scanVariantHistory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("VariantPostgresRepo - GetHistory - scanVariantHistory: %w", err)
+		}
+
+		history = append(history, h)
+	}
+
+	return history, rows.Err()
+}
+
+// GetAt -. Intervals are half-open.
+func (r *VariantPostgresRepo) GetAt(ctx context.Context, id uint64, at string) (*entity.VariantHistory, error) {
+	sql, args, err := r.Builder.
+		Select(_variantHistoryColumns).
+		From("variant_history").
+		Where(squirrel.Eq{"variant_id": id}).
+		Where("valid_from <= ?::timestamp", at).
+		Where("(valid_to IS NULL OR valid_to > ?::timestamp)", at).
+		OrderBy("valid_from DESC", "id DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("VariantPostgresRepo - GetAt - r.Builder: %w", err)
+	}
+
+	h, err := scanVariantHistory(r.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("VariantPostgresRepo - GetAt - scanVariantHistory: %w", err)
+	}
+
+	return h, nil
+}
+
+// openVersion records the stored state of a variant as its current, open-ended version,
+// with the entity.Change in ctx.
+func (r *VariantPostgresRepo) openVersion(ctx context.Context, tx pgx.Tx, id uint64) error {
+	sql, args, err := r.Builder.
+		Insert("variant_history").
+		Columns("variant_id, product_id, sku, attributes, price, currency, valid_from, valid_to, version, deleted_at, changed_by, change_reason, request_id").
+		Select(r.Builder.
+			Select("id, product_id, sku, attributes, price, currency, updated_at, NULL, version, deleted_at").
+			Column("?::varchar, ?::varchar, ?::varchar", changeArgs(ctx)...).
+			From("product_variants").
+			Where(squirrel.Eq{"id": id})).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("r.Builder: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("tx.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// notWritten tells why a write matched no row: the variant is missing or expected was stale.
+func (r *VariantPostgresRepo) notWritten(ctx context.Context, q rowQuerier, id, expected uint64) error {
+	sql, args, err := r.Builder.
+		Select("version").
+		From("product_variants").
+		Where(squirrel.Eq{"id": id}).
+		Where(_live).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("r.Builder: %w", err)
+	}
+
+	var actual uint64
+
+	err = q.QueryRow(ctx, sql, args...).Scan(&actual)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrVariantNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("q.QueryRow: %w", err)
+	}
+
+	return &ConflictError{Entity: "variant", ID: id, Expected: expected, Actual: actual}
+}
+
+func (r *VariantPostgresRepo) getVariant(ctx context.Context, q squirrel.SelectBuilder) (*entity.Variant, error) {
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := scanVariant(r.Pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func scanVariant(row pgx.Row) (*entity.Variant, error) {
+	var (
+		v                    = &entity.Variant{}
+		attributes           []byte
+		createdAt, updatedAt time.Time
+		deletedAt            *time.Time
+	)
+
+	err := row.Scan(&v.ID, &v.ProductID, &v.SKU, &attributes, &v.Price.Amount, &v.Price.Currency, &createdAt, &updatedAt, &v.Version, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Attributes, err = decodeAttributes(attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	v.CreatedAt = createdAt.Format(_timeLayout)
+	v.UpdatedAt = updatedAt.Format(_timeLayout)
+	v.DeletedAt = formatTimePtr(deletedAt)
+
+	return v, nil
+}
+
+func scanVariantHistory(row pgx.Row) (*entity.VariantHistory, error) {
+	var (
+		h                    = &entity.VariantHistory{}
+		attributes           []byte
+		validFrom            time.Time
+		validTo, deletedAt   *time.Time
+		changedBy, requestID *string
+		changeReason         *string
+	)
+
+	err := row.Scan(&h.ID, &h.VariantID, &h.ProductID, &h.SKU, &attributes, &h.Price.Amount, &h.Price.Currency,
+		&validFrom, &validTo, &h.Version, &deletedAt, &changedBy, &changeReason, &requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	h.Attributes, err = decodeAttributes(attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	h.ValidFrom = validFrom.Format(_timeLayout)
+	h.ValidTo = formatTimePtr(validTo)
+	h.DeletedAt = formatTimePtr(deletedAt)
+	h.ChangedBy = stringOrEmpty(changedBy)
+	h.ChangeReason = stringOrEmpty(changeReason)
+	h.RequestID = stringOrEmpty(requestID)
+
+	return h, nil
+}
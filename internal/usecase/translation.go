@@ -9,16 +9,33 @@ import (
 
 // TranslationUseCase -.
 type TranslationUseCase struct {
-	repo   TranslationRepo
-	webAPI TranslationWebAPI
+	repo        TranslationRepo
+	webAPI      TranslationWebAPI
+	skipHistory bool
+}
+
+// TranslationOption -.
+type TranslationOption func(*TranslationUseCase)
+
+// SkipHistory makes Translate return translations without storing them in the history.
+func SkipHistory() TranslationOption {
+	return func(uc *TranslationUseCase) {
+		uc.skipHistory = true
+	}
 }
 
 // New -.
-func New(r TranslationRepo, w TranslationWebAPI) *TranslationUseCase {
-	return &TranslationUseCase{
+func New(r TranslationRepo, w TranslationWebAPI, opts ...TranslationOption) *TranslationUseCase {
+	uc := &TranslationUseCase{
 		repo:   r,
 		webAPI: w,
 	}
+
+	for _, opt := range opts {
+		opt(uc)
+	}
+
+	return uc
 }
 
 // History - getting translate history from store.
@@ -38,6 +55,10 @@ func (uc *TranslationUseCase) Translate(ctx context.Context, t entity.Translatio
 		return entity.Translation{}, fmt.Errorf("TranslationUseCase - Translate - s.webAPI.Translate: %w", err)
 	}
 
+	if uc.skipHistory {
+		return translation, nil
+	}
+
 	err = uc.repo.Store(context.Background(), translation)
 	if err != nil {
 		return entity.Translation{}, fmt.Errorf("TranslationUseCase - Translate - s.repository.Store: %w", err)
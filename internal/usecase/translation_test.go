@@ -124,3 +124,21 @@ func TestTranslate(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslateSkipHistory(t *testing.T) {
+	t.Parallel()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	webAPI := NewMockTranslationWebAPI(mockCtl)
+	translation := usecase.New(NewMockTranslationRepo(mockCtl), webAPI, usecase.SkipHistory())
+
+	translated := entity.Translation{Source: "auto", Destination: "de", Original: "Bag", Translation: "Tasche"}
+	webAPI.EXPECT().Translate(entity.Translation{Source: "auto", Destination: "de", Original: "Bag"}).Return(translated, nil)
+
+	// Nothing is stored.
+	res, err := translation.Translate(context.Background(), entity.Translation{Source: "auto", Destination: "de", Original: "Bag"})
+	require.NoError(t, err)
+	require.Equal(t, translated, res)
+}
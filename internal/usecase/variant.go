@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+// Limits of variant fields; sku matches its VARCHAR column.
+const (
+	MaxVariantSKULen            = 64
+	MaxVariantAttributes        = 20
+	MaxVariantAttributeNameLen  = 64
+	MaxVariantAttributeValueLen = 255
+)
+
+// VariantUseCase manages the variants of products. Variants are addressed below their product, so a
+// variant of another product is not found, and so are the variants of soft-deleted products.
+// Every write records the entity.Change carried by its ctx with the version it creates.
+type VariantUseCase interface {
+	// Create adds a variant to v.ProductID; no two live variants of a product have the same attributes.
+	Create(ctx context.Context, v *entity.Variant) (*entity.Variant, error)
+	Get(ctx context.Context, productID, id uint64, includeDeleted bool) (*entity.Variant, error)
+	// List returns the variants of a product whose attributes include every pair of attributes.
+	List(ctx context.Context, productID uint64, attributes map[string]string, includeDeleted bool) ([]*entity.Variant, error)
+	// Update stores the sku, attributes and price of v as its next version; a non-zero v.Version
+	// must be the stored version like in ProductUseCase.Update.
+	Update(ctx context.Context, v *entity.Variant) (*entity.Variant, error)
+	Delete(ctx context.Context, productID, id, version uint64) error
+	// History returns every version of a variant, oldest first, deleted variants included.
+	History(ctx context.Context, productID, id uint64) ([]*entity.VariantHistory, error)
+	// GetAt returns the version of a variant that was current at at, formatted as entity.DateTimeLayout.
+	GetAt(ctx context.Context, productID, id uint64, at string) (*entity.VariantHistory, error)
+}
+
+type variantUseCase struct {
+	repo     repository.VariantRepository
+	products repository.ProductRepository
+}
+
+func NewVariantUseCase(r repository.VariantRepository, products repository.ProductRepository) VariantUseCase {
+	return &variantUseCase{repo: r, products: products}
+}
+
+func (uc *variantUseCase) Create(ctx context.Context, v *entity.Variant) (*entity.Variant, error) {
+	if err := validateChange(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateVariant(v, true); err != nil {
+		return nil, err
+	}
+	if err := uc.checkProduct(ctx, v.ProductID); err != nil {
+		return nil, err
+	}
+	if err := uc.checkAttributes(ctx, v); err != nil {
+		return nil, err
+	}
+	id, err := uc.repo.Create(ctx, v)
+	if err != nil {
+		return nil, variantRepoError(err)
+	}
+	return uc.reload(ctx, id)
+}
+
+func (uc *variantUseCase) Get(ctx context.Context, productID, id uint64, includeDeleted bool) (*entity.Variant, error) {
+	if err := uc.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	v, err := uc.repo.GetByID(ctx, id, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil || v.ProductID != productID {
+		return nil, ErrVariantNotFound
+	}
+	return v, nil
+}
+
+func (uc *variantUseCase) List(ctx context.Context, productID uint64, attributes map[string]string, includeDeleted bool) ([]*entity.Variant, error) {
+	if err := uc.checkProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	variants, err := uc.repo.ListByProduct(ctx, productID, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	// A product has few variants, so they are filtered here rather than by the store.
+	matching := variants[:0]
+	for _, v := range variants {
+		if hasAttributes(v.Attributes, attributes) {
+			matching = append(matching, v)
+		}
+	}
+	return matching, nil
+}
+
+func (uc *variantUseCase) Update(ctx context.Context, v *entity.Variant) (*entity.Variant, error) {
+	if err := validateChange(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateVariant(v, false); err != nil {
+		return nil, err
+	}
+	if _, err := uc.Get(ctx, v.ProductID, v.ID, false); err != nil {
+		return nil, err
+	}
+	if err := uc.checkAttributes(ctx, v); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Update(ctx, v); err != nil {
+		return nil, variantRepoError(err)
+	}
+	return uc.reload(ctx, v.ID)
+}
+
+func (uc *variantUseCase) Delete(ctx context.Context, productID, id, version uint64) error {
+	if err := validateChange(ctx); err != nil {
+		return err
+	}
+	if _, err := uc.Get(ctx, productID, id, false); err != nil {
+		return err
+	}
+	return variantRepoError(uc.repo.Delete(ctx, id, version))
+}
+
+func (uc *variantUseCase) History(ctx context.Context, productID, id uint64) ([]*entity.VariantHistory, error) {
+	if _, err := uc.Get(ctx, productID, id, true); err != nil {
+		return nil, err
+	}
+	return uc.repo.GetHistory(ctx, id)
+}
+
+func (uc *variantUseCase) GetAt(ctx context.Context, productID, id uint64, at string) (*entity.VariantHistory, error) {
+	if _, err := time.Parse(entity.DateTimeLayout, at); err != nil {
+		var fe fieldErrors
+		fe.add("at", "must be formatted as "+entity.DateTimeLayout)
+		return nil, fe.err()
+	}
+	if _, err := uc.Get(ctx, productID, id, true); err != nil {
+		return nil, err
+	}
+	h, err := uc.repo.GetAt(ctx, id, at)
+	if err != nil {
+		return nil, err
+	}
+	if h == nil {
+		return nil, ErrVariantVersionNotFound
+	}
+	return h, nil
+}
+
+func (uc *variantUseCase) checkProduct(ctx context.Context, id uint64) error {
+	p, err := uc.products.GetByID(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// reload reads a variant back after a write.
+func (uc *variantUseCase) reload(ctx context.Context, id uint64) (*entity.Variant, error) {
+	v, err := uc.repo.GetByID(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, ErrVariantNotFound
+	}
+	return v, nil
+}
+
+// checkAttributes checks that no other live variant of the product of v has the attributes of v. It answers
+// most duplicates early; concurrent writes are settled by the unique attributes key of the store.
+func (uc *variantUseCase) checkAttributes(ctx context.Context, v *entity.Variant) error {
+	variants, err := uc.repo.ListByProduct(ctx, v.ProductID, false)
+	if err != nil {
+		return err
+	}
+	for _, other := range variants {
+		if other.ID != v.ID && len(other.Attributes) == len(v.Attributes) && hasAttributes(other.Attributes, v.Attributes) {
+			return ErrDuplicateVariantAttributes
+		}
+	}
+	return nil
+}
+
+// hasAttributes reports whether attributes contains every pair of want.
+func hasAttributes(attributes, want map[string]string) bool {
+	for k, v := range want {
+		if got, ok := attributes[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// validateVariant checks a payload for Create (create) or Update. The ids of a variant and its product
+// come from the path; timestamps are always maintained by the store.
+func validateVariant(v *entity.Variant, create bool) error {
+	var fe fieldErrors
+	if create && v.ID != 0 {
+		fe.add("id", "is read-only")
+	}
+	switch {
+	case strings.TrimSpace(v.SKU) == "":
+		fe.add("sku", "is required")
+	case utf8.RuneCountInString(v.SKU) > MaxVariantSKULen:
+		fe.add("sku", "must be at most "+strconv.Itoa(MaxVariantSKULen)+" characters")
+	}
+	if len(v.Attributes) > MaxVariantAttributes {
+		fe.add("attributes", "must have at most "+strconv.Itoa(MaxVariantAttributes)+" entries")
+	}
+	names := make([]string, 0, len(v.Attributes))
+	for k := range v.Attributes {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		switch value := v.Attributes[k]; {
+		case strings.TrimSpace(k) == "":
+			fe.add("attributes", "must not have an empty name")
+		case utf8.RuneCountInString(k) > MaxVariantAttributeNameLen:
+			fe.add("attributes."+k, "name must be at most "+strconv.Itoa(MaxVariantAttributeNameLen)+" characters")
+		case utf8.RuneCountInString(value) > MaxVariantAttributeValueLen:
+			fe.add("attributes."+k, "must be at most "+strconv.Itoa(MaxVariantAttributeValueLen)+" characters")
+		}
+	}
+	validatePrice(&fe, v.Price)
+	if v.CreatedAt != "" {
+		fe.add("created_at", "is read-only")
+	}
+	if v.UpdatedAt != "" {
+		fe.add("updated_at", "is read-only")
+	}
+	if v.DeletedAt != "" {
+		fe.add("deleted_at", "is read-only")
+	}
+	return fe.err()
+}
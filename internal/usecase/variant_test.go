@@ -0,0 +1,236 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+	"github.com/dariuszdroba/go-from-template/internal/usecase"
+	"github.com/dariuszdroba/go-from-template/internal/usecase/repository"
+)
+
+func variant(t *testing.T) (usecase.VariantUseCase, *MockVariantRepository, *MockProductRepository) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	repo := NewMockVariantRepository(mockCtl)
+	products := NewMockProductRepository(mockCtl)
+
+	return usecase.NewVariantUseCase(repo, products), repo, products
+}
+
+func shirts() []*entity.Variant {
+	return []*entity.Variant{
+		{ID: 1, ProductID: 7, SKU: "TS-M-RED", Attributes: map[string]string{"size": "M", "colour": "red"}, Price: *eur(1999), Version: 1},
+		{ID: 2, ProductID: 7, SKU: "TS-L-RED", Attributes: map[string]string{"size": "L", "colour": "red"}, Price: *eur(2199), Version: 1},
+		{ID: 3, ProductID: 7, SKU: "TS-M-BLUE", Attributes: map[string]string{"size": "M", "colour": "blue"}, Price: *eur(1999), Version: 2},
+	}
+}
+
+func TestCreateVariant(t *testing.T) {
+	t.Parallel()
+
+	ctx := entity.WithChange(context.Background(), entity.Change{Actor: "jane"})
+	created := &entity.Variant{ID: 4, ProductID: 7, SKU: "TS-L-BLUE", Attributes: map[string]string{"size": "L", "colour": "blue"}, Price: *eur(2199), Version: 1}
+
+	tests := []struct {
+		name    string
+		variant *entity.Variant
+		mock    func(repo *MockVariantRepository, products *MockProductRepository)
+		target  error
+	}{
+		{
+			name:    "new attributes",
+			variant: &entity.Variant{ProductID: 7, SKU: "TS-L-BLUE", Attributes: map[string]string{"size": "L", "colour": "blue"}, Price: *eur(2199)},
+			mock: func(repo *MockVariantRepository, products *MockProductRepository) {
+				products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+				repo.EXPECT().ListByProduct(ctx, uint64(7), false).Return(shirts(), nil)
+				repo.EXPECT().Create(ctx, gomock.Any()).Return(uint64(4), nil)
+				repo.EXPECT().GetByID(ctx, uint64(4), false).Return(created, nil)
+			},
+		},
+		{
+			name:    "same attributes as another variant",
+			variant: &entity.Variant{ProductID: 7, SKU: "TS-M-RED-2", Attributes: map[string]string{"colour": "red", "size": "M"}, Price: *eur(1999)},
+			mock: func(repo *MockVariantRepository, products *MockProductRepository) {
+				products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+				repo.EXPECT().ListByProduct(ctx, uint64(7), false).Return(shirts(), nil)
+			},
+			target: usecase.ErrDuplicateVariantAttributes,
+		},
+		{
+			name:    "duplicate sku",
+			variant: &entity.Variant{ProductID: 7, SKU: "TS-M-RED", Attributes: map[string]string{"size": "S"}, Price: *eur(1999)},
+			mock: func(repo *MockVariantRepository, products *MockProductRepository) {
+				products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+				repo.EXPECT().ListByProduct(ctx, uint64(7), false).Return(shirts(), nil)
+				repo.EXPECT().Create(ctx, gomock.Any()).Return(uint64(0), fmt.Errorf("%w: unique", repository.ErrDuplicateVariantSKU))
+			},
+			target: usecase.ErrDuplicateVariantSKU,
+		},
+		{
+			name:    "same attributes written concurrently",
+			variant: &entity.Variant{ProductID: 7, SKU: "TS-S-RED", Attributes: map[string]string{"size": "S", "colour": "red"}, Price: *eur(1999)},
+			mock: func(repo *MockVariantRepository, products *MockProductRepository) {
+				products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+				repo.EXPECT().ListByProduct(ctx, uint64(7), false).Return(shirts(), nil)
+				repo.EXPECT().Create(ctx, gomock.Any()).Return(uint64(0), fmt.Errorf("%w: unique", repository.ErrDuplicateVariantAttributes))
+			},
+			target: usecase.ErrDuplicateVariantAttributes,
+		},
+		{
+			name:    "unknown product",
+			variant: &entity.Variant{ProductID: 8, SKU: "X", Price: *eur(1)},
+			mock: func(repo *MockVariantRepository, products *MockProductRepository) {
+				products.EXPECT().GetByID(ctx, uint64(8), false).Return(nil, nil)
+			},
+			target: usecase.ErrProductNotFound,
+		},
+		{
+			name:    "invalid",
+			variant: &entity.Variant{ProductID: 7, Attributes: map[string]string{"": "x", "size": strings.Repeat("M", usecase.MaxVariantAttributeValueLen+1)}, Price: entity.Money{Amount: -1, Currency: "EUR"}},
+			mock:    func(repo *MockVariantRepository, products *MockProductRepository) {},
+			target:  usecase.ErrValidation,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc, repo, products := variant(t)
+			tc.mock(repo, products)
+
+			v, err := uc.Create(ctx, tc.variant)
+			if tc.target != nil {
+				require.ErrorIs(t, err, tc.target)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, created, v)
+		})
+	}
+}
+
+func TestCreateVariantValidationFields(t *testing.T) {
+	t.Parallel()
+
+	uc, _, _ := variant(t)
+	_, err := uc.Create(context.Background(), &entity.Variant{
+		ProductID:  7,
+		Attributes: map[string]string{"size": strings.Repeat("M", usecase.MaxVariantAttributeValueLen+1)},
+		Price:      *eur(1),
+	})
+
+	var ve *usecase.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, []usecase.FieldError{
+		{Field: "sku", Message: "is required"},
+		{Field: "attributes.size", Message: "must be at most 255 characters"},
+	}, ve.Fields)
+}
+
+func TestListVariants(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo, products := variant(t)
+
+	products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil).Times(2)
+	repo.EXPECT().ListByProduct(ctx, uint64(7), false).Return(shirts(), nil).Times(2)
+
+	all, err := uc.List(ctx, 7, nil, false)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+
+	medium, err := uc.List(ctx, 7, map[string]string{"size": "M"}, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"TS-M-RED", "TS-M-BLUE"}, []string{medium[0].SKU, medium[1].SKU})
+}
+
+func TestUpdateVariant(t *testing.T) {
+	t.Parallel()
+
+	ctx := entity.WithChange(context.Background(), entity.Change{Actor: "jane"})
+
+	t.Run("price change keeps own attributes", func(t *testing.T) {
+		t.Parallel()
+
+		uc, repo, products := variant(t)
+		v := &entity.Variant{ID: 1, ProductID: 7, SKU: "TS-M-RED", Attributes: map[string]string{"size": "M", "colour": "red"}, Price: *eur(1799), Version: 1}
+		updated := *v
+		updated.Version = 2
+
+		products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+		repo.EXPECT().GetByID(ctx, uint64(1), false).Return(shirts()[0], nil)
+		repo.EXPECT().ListByProduct(ctx, uint64(7), false).Return(shirts(), nil)
+		repo.EXPECT().Update(ctx, v).Return(nil)
+		repo.EXPECT().GetByID(ctx, uint64(1), false).Return(&updated, nil)
+
+		got, err := uc.Update(ctx, v)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), got.Version)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		t.Parallel()
+
+		uc, repo, products := variant(t)
+		v := &entity.Variant{ID: 3, ProductID: 7, SKU: "TS-M-BLUE", Attributes: map[string]string{"size": "M", "colour": "blue"}, Price: *eur(1799), Version: 1}
+
+		products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+		repo.EXPECT().GetByID(ctx, uint64(3), false).Return(shirts()[2], nil)
+		repo.EXPECT().ListByProduct(ctx, uint64(7), false).Return(shirts(), nil)
+		repo.EXPECT().Update(ctx, v).Return(&repository.ConflictError{Entity: "variant", ID: 3, Expected: 1, Actual: 2})
+
+		_, err := uc.Update(ctx, v)
+		require.ErrorIs(t, err, usecase.ErrVersionConflict)
+		require.ErrorIs(t, err, usecase.ErrConflict)
+		require.Contains(t, err.Error(), "variant 3 is at version 2")
+	})
+
+	t.Run("variant of another product", func(t *testing.T) {
+		t.Parallel()
+
+		uc, repo, products := variant(t)
+		other := *shirts()[0]
+		other.ProductID = 9
+
+		products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil)
+		repo.EXPECT().GetByID(ctx, uint64(1), false).Return(&other, nil)
+
+		_, err := uc.Update(ctx, &entity.Variant{ID: 1, ProductID: 7, SKU: "TS-M-RED", Price: *eur(1)})
+		require.ErrorIs(t, err, usecase.ErrVariantNotFound)
+	})
+}
+
+func TestGetVariantAt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	uc, repo, products := variant(t)
+
+	_, err := uc.GetAt(ctx, 7, 1, "yesterday")
+	require.ErrorIs(t, err, usecase.ErrValidation)
+
+	products.EXPECT().GetByID(ctx, uint64(7), false).Return(&entity.Product{ID: "7"}, nil).Times(2)
+	repo.EXPECT().GetByID(ctx, uint64(1), true).Return(shirts()[0], nil).Times(2)
+	repo.EXPECT().GetAt(ctx, uint64(1), "2020-01-01 00:00:00").Return(&entity.VariantHistory{ID: 10, VariantID: 1, Version: 1}, nil)
+	repo.EXPECT().GetAt(ctx, uint64(1), "2000-01-01 00:00:00").Return(nil, nil)
+
+	h, err := uc.GetAt(ctx, 7, 1, "2020-01-01 00:00:00")
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), h.ID)
+
+	_, err = uc.GetAt(ctx, 7, 1, "2000-01-01 00:00:00")
+	require.ErrorIs(t, err, usecase.ErrVariantVersionNotFound)
+}
@@ -0,0 +1,56 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dariuszdroba/go-from-template/internal/entity"
+)
+
+// ExchangeRatesFile provides exchange rates from a JSON file, e.g. a fixture for development.
+// The file is read on every call, so edits are picked up by the next sync.
+type ExchangeRatesFile struct {
+	path string
+}
+
+// fileExchangeRate is one entry of the file; rate may be a JSON number or a decimal string.
+type fileExchangeRate struct {
+	Base      string      `json:"base"`
+	Quote     string      `json:"quote"`
+	Rate      json.Number `json:"rate"`
+	ValidFrom string      `json:"valid_from"`
+}
+
+// NewExchangeRatesFile -.
+func NewExchangeRatesFile(path string) *ExchangeRatesFile {
+	return &ExchangeRatesFile{path: path}
+}
+
+// Rates -.
+func (f *ExchangeRatesFile) Rates(_ context.Context) ([]entity.ExchangeRate, error) {
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("ExchangeRatesFile - Rates - os.ReadFile: %w", err)
+	}
+
+	var entries []fileExchangeRate
+
+	err = json.Unmarshal(b, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("ExchangeRatesFile - Rates - json.Unmarshal: %w", err)
+	}
+
+	rates := make([]entity.ExchangeRate, 0, len(entries))
+	for _, e := range entries {
+		rates = append(rates, entity.ExchangeRate{
+			Base:      e.Base,
+			Quote:     e.Quote,
+			Rate:      e.Rate.String(),
+			ValidFrom: e.ValidFrom,
+		})
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS products(
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    price INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS product_history(
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    price INTEGER NOT NULL DEFAULT 0,
+    valid_from TIMESTAMP NOT NULL,
+    valid_to TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT product_history_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS product_history_product_id_idx ON product_history(product_id);
+CREATE INDEX IF NOT EXISTS product_history_valid_from_idx ON product_history(valid_from);
+CREATE INDEX IF NOT EXISTS product_history_valid_to_idx ON product_history(valid_to);
+CREATE INDEX IF NOT EXISTS product_history_product_validity_idx ON product_history(product_id, valid_from, valid_to);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS products_updated_at_idx;
+DROP INDEX IF EXISTS products_created_at_idx;
+DROP INDEX IF EXISTS products_price_idx;
+DROP INDEX IF EXISTS products_name_idx;
+
+ALTER TABLE product_history ALTER COLUMN created_at TYPE TIMESTAMP;
+ALTER TABLE product_history ALTER COLUMN valid_to TYPE TIMESTAMP;
+ALTER TABLE product_history ALTER COLUMN valid_from TYPE TIMESTAMP;
+ALTER TABLE products ALTER COLUMN updated_at TYPE TIMESTAMP;
+ALTER TABLE products ALTER COLUMN created_at TYPE TIMESTAMP;
@@ -0,0 +1,11 @@
+-- Second precision keeps timestamps identical to their text form, which listing cursors rely on.
+ALTER TABLE products ALTER COLUMN created_at TYPE TIMESTAMP(0);
+ALTER TABLE products ALTER COLUMN updated_at TYPE TIMESTAMP(0);
+ALTER TABLE product_history ALTER COLUMN valid_from TYPE TIMESTAMP(0);
+ALTER TABLE product_history ALTER COLUMN valid_to TYPE TIMESTAMP(0);
+ALTER TABLE product_history ALTER COLUMN created_at TYPE TIMESTAMP(0);
+
+CREATE INDEX IF NOT EXISTS products_name_idx ON products(name, id);
+CREATE INDEX IF NOT EXISTS products_price_idx ON products(price, id);
+CREATE INDEX IF NOT EXISTS products_created_at_idx ON products(created_at, id);
+CREATE INDEX IF NOT EXISTS products_updated_at_idx ON products(updated_at, id);
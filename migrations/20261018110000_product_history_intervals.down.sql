@@ -0,0 +1,5 @@
+DELETE FROM product_history WHERE valid_to IS NULL;
+
+ALTER TABLE product_history DROP COLUMN IF EXISTS effective_from;
+ALTER TABLE products DROP COLUMN IF EXISTS effective_from;
+ALTER TABLE product_history ALTER COLUMN valid_to SET NOT NULL;
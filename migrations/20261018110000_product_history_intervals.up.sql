@@ -0,0 +1,21 @@
+ALTER TABLE product_history ALTER COLUMN valid_to DROP NOT NULL;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS effective_from TIMESTAMP(0);
+ALTER TABLE product_history ADD COLUMN IF NOT EXISTS effective_from TIMESTAMP(0);
+
+-- Rows written so far carry valid_from = the moment they were replaced and a made-up valid_to.
+-- Rebuild the real interval: from the previous replacement (or creation) up to that moment.
+UPDATE product_history h
+SET valid_from = r.real_from, valid_to = r.real_to
+FROM (
+    SELECT ph.id,
+           COALESCE(LAG(ph.valid_from) OVER w, p.created_at) AS real_from,
+           ph.valid_from AS real_to
+    FROM product_history ph
+    JOIN products p ON p.id = ph.product_id
+    WINDOW w AS (PARTITION BY ph.product_id ORDER BY ph.valid_from, ph.id)
+) r
+WHERE h.id = r.id;
+
+-- The current version of every product is an open-ended history row.
+INSERT INTO product_history (product_id, name, description, price, valid_from, valid_to, created_at)
+SELECT id, name, description, price, updated_at, NULL, created_at FROM products;
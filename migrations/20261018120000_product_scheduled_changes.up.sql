@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS product_scheduled_changes(
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    name VARCHAR(255),
+    description TEXT,
+    price INTEGER,
+    effective_at TIMESTAMP(0) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+    status_changed_at TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+    CONSTRAINT product_scheduled_changes_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS product_scheduled_changes_product_id_idx ON product_scheduled_changes(product_id);
+CREATE INDEX IF NOT EXISTS product_scheduled_changes_due_idx ON product_scheduled_changes(status, effective_at);
@@ -0,0 +1,2 @@
+ALTER TABLE product_history DROP COLUMN IF EXISTS version;
+ALTER TABLE products DROP COLUMN IF EXISTS version;
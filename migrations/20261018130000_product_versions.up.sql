@@ -0,0 +1,16 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE product_history ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+-- Number existing versions in the order they were current.
+UPDATE product_history h
+SET version = r.n
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY valid_from, id) AS n
+    FROM product_history
+) r
+WHERE h.id = r.id;
+
+UPDATE products p
+SET version = h.version
+FROM product_history h
+WHERE h.product_id = p.id AND h.valid_to IS NULL;
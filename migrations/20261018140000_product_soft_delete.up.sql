@@ -0,0 +1,2 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP(0);
+ALTER TABLE product_history ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP(0);
@@ -0,0 +1,11 @@
+UPDATE product_scheduled_changes SET price = price / 100 WHERE price IS NOT NULL;
+ALTER TABLE product_scheduled_changes DROP COLUMN IF EXISTS currency;
+ALTER TABLE product_scheduled_changes ALTER COLUMN price TYPE INTEGER;
+
+UPDATE product_history SET price = price / 100;
+ALTER TABLE product_history DROP COLUMN IF EXISTS currency;
+ALTER TABLE product_history ALTER COLUMN price TYPE INTEGER;
+
+UPDATE products SET price = price / 100;
+ALTER TABLE products DROP COLUMN IF EXISTS currency;
+ALTER TABLE products ALTER COLUMN price TYPE INTEGER;
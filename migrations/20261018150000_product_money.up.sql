@@ -0,0 +1,13 @@
+-- Prices become minor units of an ISO 4217 currency. Existing prices carried no unit and are
+-- taken to be whole USD, so they are scaled to cents.
+ALTER TABLE products ALTER COLUMN price TYPE BIGINT;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE products SET price = price * 100;
+
+ALTER TABLE product_history ALTER COLUMN price TYPE BIGINT;
+ALTER TABLE product_history ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE product_history SET price = price * 100;
+
+ALTER TABLE product_scheduled_changes ALTER COLUMN price TYPE BIGINT;
+ALTER TABLE product_scheduled_changes ADD COLUMN IF NOT EXISTS currency CHAR(3);
+UPDATE product_scheduled_changes SET price = price * 100, currency = 'USD' WHERE price IS NOT NULL;
@@ -0,0 +1,14 @@
+-- Exchange rates keep their history like products: valid_from/valid_to is the half-open interval
+-- during which a rate was current; valid_to is NULL for the current rate of a pair.
+CREATE TABLE IF NOT EXISTS exchange_rates(
+    id BIGSERIAL PRIMARY KEY,
+    base CHAR(3) NOT NULL,
+    quote CHAR(3) NOT NULL,
+    rate NUMERIC(20,10) NOT NULL,
+    valid_from TIMESTAMP(0) NOT NULL,
+    valid_to TIMESTAMP(0),
+    created_at TIMESTAMP(0) NOT NULL DEFAULT NOW()
+);
+
+-- A pair has one rate per valid_from, so that concurrent syncs cannot both insert it.
+CREATE UNIQUE INDEX IF NOT EXISTS exchange_rates_pair_valid_from_idx ON exchange_rates(base, quote, valid_from);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS products_sku_idx;
+ALTER TABLE product_history DROP COLUMN IF EXISTS sku;
+ALTER TABLE products DROP COLUMN IF EXISTS sku;
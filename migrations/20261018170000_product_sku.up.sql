@@ -0,0 +1,4 @@
+-- sku is the key products are matched by when imported from other systems.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+ALTER TABLE product_history ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+CREATE UNIQUE INDEX IF NOT EXISTS products_sku_idx ON products(sku);
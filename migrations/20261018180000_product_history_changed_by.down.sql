@@ -0,0 +1 @@
+ALTER TABLE product_history DROP COLUMN IF EXISTS changed_by;
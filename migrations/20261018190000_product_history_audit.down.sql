@@ -0,0 +1,2 @@
+ALTER TABLE product_history DROP COLUMN IF EXISTS request_id;
+ALTER TABLE product_history DROP COLUMN IF EXISTS change_reason;
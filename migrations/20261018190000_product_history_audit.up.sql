@@ -0,0 +1,3 @@
+-- change_reason and request_id complete changed_by into an audit trail of every version.
+ALTER TABLE product_history ADD COLUMN IF NOT EXISTS change_reason VARCHAR(1000);
+ALTER TABLE product_history ADD COLUMN IF NOT EXISTS request_id VARCHAR(255);
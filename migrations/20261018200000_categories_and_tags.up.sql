@@ -0,0 +1,38 @@
+-- Categories form a tree through parent_id; a category with subcategories cannot be deleted.
+CREATE TABLE IF NOT EXISTS categories(
+    id BIGSERIAL PRIMARY KEY,
+    parent_id BIGINT,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+    CONSTRAINT categories_parent_id_fk FOREIGN KEY (parent_id) REFERENCES categories(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories(parent_id);
+
+CREATE TABLE IF NOT EXISTS product_categories(
+    product_id BIGINT NOT NULL,
+    category_id BIGINT NOT NULL,
+    PRIMARY KEY (product_id, category_id),
+    CONSTRAINT product_categories_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT product_categories_category_id_fk FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS product_categories_category_id_idx ON product_categories(category_id);
+
+CREATE TABLE IF NOT EXISTS tags(
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS tags_name_idx ON tags(name);
+
+CREATE TABLE IF NOT EXISTS product_tags(
+    product_id BIGINT NOT NULL,
+    tag_id BIGINT NOT NULL,
+    PRIMARY KEY (product_id, tag_id),
+    CONSTRAINT product_tags_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT product_tags_tag_id_fk FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS product_tags_tag_id_idx ON product_tags(tag_id);
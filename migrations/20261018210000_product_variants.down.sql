@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS variant_history;
+DROP TABLE IF EXISTS product_variants;
@@ -0,0 +1,41 @@
+-- Variants are the sellable SKUs of a product, told apart by attributes such as size and colour.
+-- Like products they are versioned in variant_history, whose open version has no valid_to.
+-- attributes_key is a hash of the attributes with sorted names, unique among the live variants of a product.
+CREATE TABLE IF NOT EXISTS product_variants(
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    sku VARCHAR(64) NOT NULL,
+    attributes JSONB NOT NULL DEFAULT '{}',
+    attributes_key CHAR(64) NOT NULL,
+    price BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+    version BIGINT NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP(0),
+    CONSTRAINT product_variants_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_sku_idx ON product_variants(sku);
+CREATE INDEX IF NOT EXISTS product_variants_product_id_idx ON product_variants(product_id, id);
+CREATE UNIQUE INDEX IF NOT EXISTS product_variants_attributes_idx ON product_variants(product_id, attributes_key) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS variant_history(
+    id BIGSERIAL PRIMARY KEY,
+    variant_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    sku VARCHAR(64) NOT NULL,
+    attributes JSONB NOT NULL DEFAULT '{}',
+    price BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    valid_from TIMESTAMP(0) NOT NULL,
+    valid_to TIMESTAMP(0),
+    version BIGINT NOT NULL,
+    deleted_at TIMESTAMP(0),
+    changed_by VARCHAR(255),
+    change_reason VARCHAR(1000),
+    request_id VARCHAR(255),
+    CONSTRAINT variant_history_variant_id_fk FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS variant_history_variant_validity_idx ON variant_history(variant_id, valid_from, valid_to);
@@ -0,0 +1,27 @@
+-- stock_movements is the ledger of the stock of every product; stock_levels holds what it adds up to,
+-- and its row of a product is locked while a movement is recorded so concurrent writes cannot oversell.
+-- A reference reserves, releases and fulfils a product at most once; movements without one are not compared.
+CREATE TABLE IF NOT EXISTS stock_levels(
+    product_id BIGINT PRIMARY KEY,
+    on_hand BIGINT NOT NULL DEFAULT 0,
+    reserved BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP(0),
+    CONSTRAINT stock_levels_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT stock_levels_reserved_check CHECK (reserved >= 0 AND reserved <= on_hand)
+);
+
+CREATE TABLE IF NOT EXISTS stock_movements(
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    quantity BIGINT NOT NULL,
+    reference VARCHAR(255),
+    created_at TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+    changed_by VARCHAR(255),
+    change_reason VARCHAR(1000),
+    request_id VARCHAR(255),
+    CONSTRAINT stock_movements_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS stock_movements_product_id_idx ON stock_movements(product_id, id);
+CREATE UNIQUE INDEX IF NOT EXISTS stock_movements_reference_idx ON stock_movements(product_id, reference, kind);
@@ -0,0 +1,7 @@
+-- search_vector ranks name matches above description matches. The simple configuration keeps words
+-- as written, so searches can match them by prefix in any language.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', name), 'A') || setweight(to_tsvector('simple', description), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS products_search_vector_idx ON products USING GIN (search_vector);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS product_translation_failures;
+DROP TABLE IF EXISTS product_translations;
@@ -0,0 +1,25 @@
+-- A machine translation records the product version it was made from, so it can be refreshed once the
+-- product changes; human translations are never replaced by machine ones.
+CREATE TABLE IF NOT EXISTS product_translations(
+    product_id BIGINT NOT NULL,
+    locale VARCHAR(35) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    source VARCHAR(16) NOT NULL,
+    source_version BIGINT NULL,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, locale),
+    CONSTRAINT product_translations_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+-- A failed machine translation is retried once retry_at has passed, after a wait that grows with its
+-- attempts, so failing pairs neither come first in every run nor are retried in a tight loop.
+CREATE TABLE IF NOT EXISTS product_translation_failures(
+    product_id BIGINT NOT NULL,
+    locale VARCHAR(35) NOT NULL,
+    attempts INT NOT NULL,
+    last_attempt_at TIMESTAMP(0) NOT NULL,
+    retry_at TIMESTAMP(0) NOT NULL,
+    PRIMARY KEY (product_id, locale),
+    CONSTRAINT product_translation_failures_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
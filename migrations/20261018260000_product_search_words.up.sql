@@ -0,0 +1,19 @@
+-- product_search_words keeps each word of the names and descriptions of products under the word itself
+-- and under the word less each one of its characters. Words one typo apart share one of these keys, so
+-- searches find the words a misspelled term may stand for by looking up its own keys.
+CREATE TABLE IF NOT EXISTS product_search_words(
+    search_key VARCHAR(64) NOT NULL,
+    word VARCHAR(64) NOT NULL,
+    PRIMARY KEY (search_key, word)
+);
+
+-- The words of the products written so far, split at anything but letters and digits like new ones.
+INSERT INTO product_search_words (search_key, word)
+SELECT DISTINCT CASE WHEN i = 0 THEN word ELSE overlay(word PLACING '' FROM i FOR 1) END, word
+FROM (
+    SELECT DISTINCT regexp_split_to_table(lower(concat_ws(' ', name, description)), '[^[:alnum:]]+') AS word
+    FROM products
+) words
+CROSS JOIN LATERAL generate_series(0, char_length(word)) AS i
+WHERE char_length(word) BETWEEN 3 AND 64
+ON CONFLICT DO NOTHING;
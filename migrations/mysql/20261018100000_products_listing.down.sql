@@ -0,0 +1,4 @@
+DROP INDEX products_updated_at_idx ON products;
+DROP INDEX products_created_at_idx ON products;
+DROP INDEX products_price_idx ON products;
+DROP INDEX products_name_idx ON products;
@@ -0,0 +1,4 @@
+CREATE INDEX products_name_idx ON products(name, id);
+CREATE INDEX products_price_idx ON products(price, id);
+CREATE INDEX products_created_at_idx ON products(created_at, id);
+CREATE INDEX products_updated_at_idx ON products(updated_at, id);
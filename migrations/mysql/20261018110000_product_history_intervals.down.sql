@@ -0,0 +1,5 @@
+DELETE FROM product_history WHERE valid_to IS NULL;
+
+ALTER TABLE product_history DROP COLUMN effective_from;
+ALTER TABLE products DROP COLUMN effective_from;
+ALTER TABLE product_history MODIFY valid_to DATETIME NOT NULL;
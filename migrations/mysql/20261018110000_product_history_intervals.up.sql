@@ -0,0 +1,20 @@
+ALTER TABLE product_history MODIFY valid_to DATETIME NULL;
+ALTER TABLE products ADD COLUMN effective_from DATETIME NULL;
+ALTER TABLE product_history ADD COLUMN effective_from DATETIME NULL;
+
+-- Rows written so far carry valid_from = the moment they were replaced and a made-up valid_to.
+-- Rebuild the real interval: from the previous replacement (or creation) up to that moment.
+UPDATE product_history h
+JOIN (
+    SELECT ph.id,
+           COALESCE(LAG(ph.valid_from) OVER w, p.created_at) AS real_from,
+           ph.valid_from AS real_to
+    FROM product_history ph
+    JOIN products p ON p.id = ph.product_id
+    WINDOW w AS (PARTITION BY ph.product_id ORDER BY ph.valid_from, ph.id)
+) r ON r.id = h.id
+SET h.valid_from = r.real_from, h.valid_to = r.real_to;
+
+-- The current version of every product is an open-ended history row.
+INSERT INTO product_history (product_id, name, description, price, valid_from, valid_to, created_at)
+SELECT id, name, description, price, updated_at, NULL, created_at FROM products;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS product_scheduled_changes(
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    product_id BIGINT UNSIGNED NOT NULL,
+    name VARCHAR(255) NULL,
+    description TEXT NULL,
+    price INT NULL,
+    effective_at DATETIME NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    status_changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX product_scheduled_changes_product_id_idx (product_id),
+    INDEX product_scheduled_changes_due_idx (status, effective_at),
+    CONSTRAINT product_scheduled_changes_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+ALTER TABLE product_history DROP COLUMN version;
+ALTER TABLE products DROP COLUMN version;
@@ -0,0 +1,14 @@
+ALTER TABLE products ADD COLUMN version BIGINT UNSIGNED NOT NULL DEFAULT 1;
+ALTER TABLE product_history ADD COLUMN version BIGINT UNSIGNED NOT NULL DEFAULT 1;
+
+-- Number existing versions in the order they were current.
+UPDATE product_history h
+JOIN (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY valid_from, id) AS n
+    FROM product_history
+) r ON r.id = h.id
+SET h.version = r.n;
+
+UPDATE products p
+JOIN product_history h ON h.product_id = p.id AND h.valid_to IS NULL
+SET p.version = h.version;
@@ -0,0 +1,3 @@
+DELETE FROM products WHERE deleted_at IS NOT NULL;
+ALTER TABLE product_history DROP COLUMN deleted_at;
+ALTER TABLE products DROP COLUMN deleted_at;
@@ -0,0 +1,2 @@
+ALTER TABLE products ADD COLUMN deleted_at DATETIME NULL;
+ALTER TABLE product_history ADD COLUMN deleted_at DATETIME NULL;
@@ -0,0 +1,8 @@
+UPDATE product_scheduled_changes SET price = price DIV 100 WHERE price IS NOT NULL;
+ALTER TABLE product_scheduled_changes DROP COLUMN currency, MODIFY price INT NULL;
+
+UPDATE product_history SET price = price DIV 100;
+ALTER TABLE product_history DROP COLUMN currency, MODIFY price INT NOT NULL DEFAULT 0;
+
+UPDATE products SET price = price DIV 100;
+ALTER TABLE products DROP COLUMN currency, MODIFY price INT NOT NULL DEFAULT 0;
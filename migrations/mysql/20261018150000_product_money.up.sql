@@ -0,0 +1,10 @@
+-- Prices become minor units of an ISO 4217 currency. Existing prices carried no unit and are
+-- taken to be whole USD, so they are scaled to cents.
+ALTER TABLE products MODIFY price BIGINT NOT NULL DEFAULT 0, ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE products SET price = price * 100;
+
+ALTER TABLE product_history MODIFY price BIGINT NOT NULL DEFAULT 0, ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE product_history SET price = price * 100;
+
+ALTER TABLE product_scheduled_changes MODIFY price BIGINT NULL, ADD COLUMN currency CHAR(3) NULL;
+UPDATE product_scheduled_changes SET price = price * 100, currency = 'USD' WHERE price IS NOT NULL;
@@ -0,0 +1,13 @@
+-- Exchange rates keep their history like products: valid_from/valid_to is the half-open interval
+-- during which a rate was current; valid_to is NULL for the current rate of a pair.
+CREATE TABLE IF NOT EXISTS exchange_rates(
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    base CHAR(3) NOT NULL,
+    quote CHAR(3) NOT NULL,
+    rate DECIMAL(20,10) NOT NULL,
+    valid_from DATETIME NOT NULL,
+    valid_to DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- A pair has one rate per valid_from, so that concurrent syncs cannot both insert it.
+    UNIQUE INDEX exchange_rates_pair_valid_from_idx (base, quote, valid_from)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
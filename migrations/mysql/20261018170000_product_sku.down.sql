@@ -0,0 +1,2 @@
+ALTER TABLE product_history DROP COLUMN sku;
+ALTER TABLE products DROP INDEX products_sku_idx, DROP COLUMN sku;
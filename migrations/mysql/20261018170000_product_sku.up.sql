@@ -0,0 +1,3 @@
+-- sku is the key products are matched by when imported from other systems.
+ALTER TABLE products ADD COLUMN sku VARCHAR(64) NULL, ADD UNIQUE INDEX products_sku_idx (sku);
+ALTER TABLE product_history ADD COLUMN sku VARCHAR(64) NULL;
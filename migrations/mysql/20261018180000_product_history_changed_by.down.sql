@@ -0,0 +1 @@
+ALTER TABLE product_history DROP COLUMN changed_by;
@@ -0,0 +1,2 @@
+-- changed_by is the actor whose write created a version, when known.
+ALTER TABLE product_history ADD COLUMN changed_by VARCHAR(255) NULL;
@@ -0,0 +1 @@
+ALTER TABLE product_history DROP COLUMN request_id, DROP COLUMN change_reason;
@@ -0,0 +1,2 @@
+-- change_reason and request_id complete changed_by into an audit trail of every version.
+ALTER TABLE product_history ADD COLUMN change_reason VARCHAR(1000) NULL, ADD COLUMN request_id VARCHAR(255) NULL;
@@ -0,0 +1,41 @@
+-- Variants are the sellable SKUs of a product, told apart by attributes such as size and colour.
+-- Like products they are versioned in variant_history, whose open version has no valid_to.
+-- attributes_key is a hash of the attributes with sorted names, unique among the live variants of a product;
+-- live_attributes_key is NULL for deleted variants, so they never collide in the unique index.
+CREATE TABLE IF NOT EXISTS product_variants(
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    product_id BIGINT UNSIGNED NOT NULL,
+    sku VARCHAR(64) NOT NULL,
+    attributes JSON NOT NULL,
+    attributes_key CHAR(64) NOT NULL,
+    price BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    version BIGINT NOT NULL DEFAULT 1,
+    deleted_at DATETIME NULL,
+    live_attributes_key CHAR(64) AS (IF(deleted_at IS NULL, attributes_key, NULL)) VIRTUAL,
+    UNIQUE INDEX product_variants_sku_idx (sku),
+    INDEX product_variants_product_id_idx (product_id, id),
+    UNIQUE INDEX product_variants_attributes_idx (product_id, live_attributes_key),
+    CONSTRAINT product_variants_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS variant_history(
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    variant_id BIGINT UNSIGNED NOT NULL,
+    product_id BIGINT UNSIGNED NOT NULL,
+    sku VARCHAR(64) NOT NULL,
+    attributes JSON NOT NULL,
+    price BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    valid_from DATETIME NOT NULL,
+    valid_to DATETIME NULL,
+    version BIGINT NOT NULL,
+    deleted_at DATETIME NULL,
+    changed_by VARCHAR(255) NULL,
+    change_reason VARCHAR(1000) NULL,
+    request_id VARCHAR(255) NULL,
+    INDEX variant_history_variant_validity_idx (variant_id, valid_from, valid_to),
+    CONSTRAINT variant_history_variant_id_fk FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,26 @@
+-- stock_movements is the ledger of the stock of every product; stock_levels holds what it adds up to,
+-- and its row of a product is locked while a movement is recorded so concurrent writes cannot oversell.
+-- A reference reserves, releases and fulfils a product at most once; movements without one are not compared.
+CREATE TABLE IF NOT EXISTS stock_levels(
+    product_id BIGINT UNSIGNED PRIMARY KEY,
+    on_hand BIGINT NOT NULL DEFAULT 0,
+    reserved BIGINT NOT NULL DEFAULT 0,
+    updated_at DATETIME NULL,
+    CONSTRAINT stock_levels_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT stock_levels_reserved_check CHECK (reserved >= 0 AND reserved <= on_hand)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS stock_movements(
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    product_id BIGINT UNSIGNED NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    quantity BIGINT NOT NULL,
+    reference VARCHAR(255) NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    changed_by VARCHAR(255) NULL,
+    change_reason VARCHAR(1000) NULL,
+    request_id VARCHAR(255) NULL,
+    INDEX stock_movements_product_id_idx (product_id, id),
+    UNIQUE INDEX stock_movements_reference_idx (product_id, reference, kind),
+    CONSTRAINT stock_movements_product_id_fk FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
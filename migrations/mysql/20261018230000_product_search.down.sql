@@ -0,0 +1,2 @@
+ALTER TABLE products DROP INDEX products_search_fulltext_idx;
+ALTER TABLE products DROP INDEX products_name_fulltext_idx;
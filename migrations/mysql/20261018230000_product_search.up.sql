@@ -0,0 +1,3 @@
+-- The name index lets searches rank name matches above description matches.
+ALTER TABLE products ADD FULLTEXT INDEX products_name_fulltext_idx (name);
+ALTER TABLE products ADD FULLTEXT INDEX products_search_fulltext_idx (name, description);
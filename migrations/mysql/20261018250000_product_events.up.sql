@@ -0,0 +1,9 @@
+-- product_events is the outbox of product changes: each row is written in the transaction of the
+-- change it describes and deleted once the event was published, so no event is lost while the broker is down.
+CREATE TABLE IF NOT EXISTS product_events(
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL,
+    product_id BIGINT UNSIGNED NOT NULL,
+    payload MEDIUMTEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,25 @@
+-- product_search_words keeps each word of the names and descriptions of products under the word itself
+-- and under the word less each one of its characters. Words one typo apart share one of these keys, so
+-- searches find the words a misspelled term may stand for by looking up its own keys. The binary
+-- collation keeps words that differ only in accents apart.
+CREATE TABLE IF NOT EXISTS product_search_words(
+    search_key VARCHAR(64) NOT NULL,
+    word VARCHAR(64) NOT NULL,
+    PRIMARY KEY (search_key, word)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+-- The words of the products written so far, split at anything but letters and digits like new ones:
+-- the separators become the commas of a JSON array of the words.
+INSERT INTO product_search_words (search_key, word)
+WITH RECURSIVE positions (i) AS (
+    SELECT 0 UNION ALL SELECT i + 1 FROM positions WHERE i < 64
+)
+SELECT DISTINCT IF(positions.i = 0, words.word, INSERT(words.word, positions.i, 1, '')), words.word
+FROM products
+JOIN JSON_TABLE(
+    CONCAT('["', REGEXP_REPLACE(LOWER(CONCAT(products.name, ' ', products.description)), '[^[:alnum:]]+', '","'), '"]'),
+    '$[*]' COLUMNS (word VARCHAR(2000) PATH '$')
+) words
+JOIN positions ON positions.i <= CHAR_LENGTH(words.word)
+WHERE CHAR_LENGTH(words.word) BETWEEN 3 AND 64
+ON DUPLICATE KEY UPDATE word = product_search_words.word;
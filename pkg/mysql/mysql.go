@@ -0,0 +1,79 @@
+// Package mysql implements mysql connection.
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	// MySQL driver.
+	_ "github.com/go-sql-driver/mysql"
+)
+
+const (
+	_defaultMaxPoolSize  = 1
+	_defaultConnAttempts = 10
+	_defaultConnTimeout  = time.Second
+)
+
+// MySQL -.
+type MySQL struct {
+	maxPoolSize  int
+	connAttempts int
+	connTimeout  time.Duration
+
+	DB *sql.DB
+}
+
+// New -.
+func New(url string, opts ...Option) (*MySQL, error) {
+	my := &MySQL{
+		maxPoolSize:  _defaultMaxPoolSize,
+		connAttempts: _defaultConnAttempts,
+		connTimeout:  _defaultConnTimeout,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(my)
+	}
+
+	var err error
+
+	my.DB, err = sql.Open("mysql", url)
+	if err != nil {
+		return nil, fmt.Errorf("mysql - NewMySQL - sql.Open: %w", err)
+	}
+
+	my.DB.SetMaxOpenConns(my.maxPoolSize)
+
+	for my.connAttempts > 0 {
+		err = my.DB.PingContext(context.Background())
+		if err == nil {
+			break
+		}
+
+		log.Printf("MySQL is trying to connect, attempts left: %d", my.connAttempts)
+
+		time.Sleep(my.connTimeout)
+
+		my.connAttempts--
+	}
+
+	if err != nil {
+		_ = my.DB.Close()
+
+		return nil, fmt.Errorf("mysql - NewMySQL - connAttempts == 0: %w", err)
+	}
+
+	return my, nil
+}
+
+// Close -.
+func (m *MySQL) Close() {
+	if m.DB != nil {
+		_ = m.DB.Close()
+	}
+}
@@ -0,0 +1,13 @@
+package publisher
+
+import "time"
+
+// Option -.
+type Option func(*Publisher)
+
+// ConfirmTimeout -.
+func ConfirmTimeout(timeout time.Duration) Option {
+	return func(p *Publisher) {
+		p.confirmTimeout = timeout
+	}
+}
@@ -0,0 +1,195 @@
+// Package publisher publishes persistent messages to a durable topic exchange.
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+const _defaultConfirmTimeout = 5 * time.Second
+
+var (
+	// ErrNotConfirmed is returned when the broker rejects a message or does not confirm it in time.
+	ErrNotConfirmed = errors.New("rabbitmq publisher - message not confirmed")
+	// ErrConnectionClosed -.
+	ErrConnectionClosed = errors.New("rabbitmq publisher - connection closed")
+)
+
+// Publisher -. It connects on the first Publish and again after a failed one, so a broker that is
+// down makes Publish fail instead of the application.
+type Publisher struct {
+	url      string
+	exchange string
+
+	confirmTimeout time.Duration
+	dial           func() (*session, error)
+
+	mu      sync.Mutex
+	session *session
+}
+
+// session is a connection to the broker with a channel in confirm mode.
+type session struct {
+	conn     io.Closer
+	channel  channel
+	confirms <-chan amqp.Confirmation
+}
+
+// channel is the part of *amqp.Channel a session publishes with.
+type channel interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
+// New -.
+func New(url, exchange string, opts ...Option) *Publisher {
+	p := &Publisher{
+		url:            url,
+		exchange:       exchange,
+		confirmTimeout: _defaultConfirmTimeout,
+	}
+	p.dial = p.connect
+
+	// Custom options
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Publish sends body with routingKey and returns once the broker confirmed it. messageID lets
+// consumers drop the duplicates a retry after an unconfirmed message may cause.
+func (p *Publisher) Publish(ctx context.Context, routingKey, messageID string, body []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.session == nil {
+		s, err := p.dial()
+		if err != nil {
+			return fmt.Errorf("rabbitmq publisher - Publish - p.dial: %w", err)
+		}
+
+		p.session = s
+	}
+
+	err := p.session.channel.Publish(p.exchange, routingKey, false, false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    messageID,
+			Timestamp:    time.Now(),
+			Type:         routingKey,
+			Body:         body,
+		})
+	if err != nil {
+		p.close()
+
+		return fmt.Errorf("rabbitmq publisher - Publish - p.session.channel.Publish: %w", err)
+	}
+
+	// A confirmation that arrives late would be taken for the one of the next message,
+	// so the channel is closed whenever the wait is given up.
+	timer := time.NewTimer(p.confirmTimeout)
+	defer timer.Stop()
+
+	select {
+	case confirm, ok := <-p.session.confirms:
+		if !ok {
+			p.close()
+
+			return ErrConnectionClosed
+		}
+
+		if !confirm.Ack {
+			return ErrNotConfirmed
+		}
+
+		return nil
+	case <-timer.C:
+		p.close()
+
+		return ErrNotConfirmed
+	case <-ctx.Done():
+		p.close()
+
+		return ctx.Err()
+	}
+}
+
+// Shutdown -.
+func (p *Publisher) Shutdown() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.session == nil {
+		return nil
+	}
+
+	err := p.session.conn.Close()
+
+	p.session = nil
+
+	if err != nil && !errors.Is(err, amqp.ErrClosed) {
+		return fmt.Errorf("rabbitmq publisher - Shutdown - p.session.conn.Close: %w", err)
+	}
+
+	return nil
+}
+
+// connect opens a session with the broker and declares the exchange.
+func (p *Publisher) connect() (*session, error) {
+	conn, err := amqp.Dial(p.url)
+	if err != nil {
+		return nil, fmt.Errorf("amqp.Dial: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close() //nolint:errcheck // the connection is dropped either way
+
+		return nil, fmt.Errorf("conn.Channel: %w", err)
+	}
+
+	err = ch.ExchangeDeclare(
+		p.exchange,
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		_ = conn.Close() //nolint:errcheck // the connection is dropped either way
+
+		return nil, fmt.Errorf("ch.ExchangeDeclare: %w", err)
+	}
+
+	err = ch.Confirm(false)
+	if err != nil {
+		_ = conn.Close() //nolint:errcheck // the connection is dropped either way
+
+		return nil, fmt.Errorf("ch.Confirm: %w", err)
+	}
+
+	return &session{
+		conn:     conn,
+		channel:  ch,
+		confirms: ch.NotifyPublish(make(chan amqp.Confirmation, 1)),
+	}, nil
+}
+
+// close drops the session so the next Publish opens a new one.
+func (p *Publisher) close() {
+	if p.session != nil {
+		_ = p.session.conn.Close() //nolint:errcheck // the connection is dropped either way
+	}
+
+	p.session = nil
+}
@@ -0,0 +1,183 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/require"
+)
+
+var errBroker = errors.New("broker down")
+
+// fakeBroker hands out sessions whose channel answers every message with the next of confirms.
+type fakeBroker struct {
+	dials    int
+	closed   int
+	dialErr  error
+	confirms []func(chan amqp.Confirmation)
+	sent     []amqp.Publishing
+}
+
+type fakeConn struct{ b *fakeBroker }
+
+func (c fakeConn) Close() error {
+	c.b.closed++
+
+	return nil
+}
+
+type fakeChannel struct {
+	b        *fakeBroker
+	confirms chan amqp.Confirmation
+}
+
+func (ch fakeChannel) Publish(_, _ string, _, _ bool, msg amqp.Publishing) error {
+	if len(ch.b.confirms) == 0 {
+		return amqp.ErrClosed
+	}
+
+	ch.b.sent = append(ch.b.sent, msg)
+	confirm := ch.b.confirms[0]
+	ch.b.confirms = ch.b.confirms[1:]
+	confirm(ch.confirms)
+
+	return nil
+}
+
+func (b *fakeBroker) dial() (*session, error) {
+	b.dials++
+	if b.dialErr != nil {
+		return nil, b.dialErr
+	}
+
+	confirms := make(chan amqp.Confirmation, 1)
+
+	return &session{conn: fakeConn{b}, channel: fakeChannel{b, confirms}, confirms: confirms}, nil
+}
+
+// Answers of the broker to a message.
+func ack(c chan amqp.Confirmation)    { c <- amqp.Confirmation{Ack: true} }
+func nack(c chan amqp.Confirmation)   { c <- amqp.Confirmation{Ack: false} }
+func lost(chan amqp.Confirmation)     {}
+func closed(c chan amqp.Confirmation) { close(c) }
+
+func publisher(b *fakeBroker) *Publisher {
+	p := New("amqp://broker", "products", ConfirmTimeout(10*time.Millisecond))
+	p.dial = b.dial
+
+	return p
+}
+
+func TestPublish(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		confirms []func(chan amqp.Confirmation)
+		target   error
+		// dials and closed are counted after a second, acknowledged message.
+		dials  int
+		closed int
+	}{
+		{name: "acknowledged", confirms: []func(chan amqp.Confirmation){ack, ack}, dials: 1},
+		{name: "rejected", confirms: []func(chan amqp.Confirmation){nack, ack}, target: ErrNotConfirmed, dials: 1},
+		{name: "not confirmed in time", confirms: []func(chan amqp.Confirmation){lost, ack}, target: ErrNotConfirmed, dials: 2, closed: 1},
+		{name: "confirms closed", confirms: []func(chan amqp.Confirmation){closed, ack}, target: ErrConnectionClosed, dials: 2, closed: 1},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := &fakeBroker{confirms: tc.confirms}
+			p := publisher(b)
+
+			err := p.Publish(context.Background(), "product.updated", "1", []byte(`{}`))
+			if tc.target != nil {
+				require.ErrorIs(t, err, tc.target)
+			} else {
+				require.NoError(t, err)
+			}
+
+			// A session given up on is replaced by the next message.
+			require.NoError(t, p.Publish(context.Background(), "product.updated", "2", []byte(`{}`)))
+			require.Equal(t, tc.dials, b.dials)
+			require.Equal(t, tc.closed, b.closed)
+			require.Len(t, b.sent, 2)
+		})
+	}
+}
+
+func TestPublishMessage(t *testing.T) {
+	t.Parallel()
+
+	b := &fakeBroker{confirms: []func(chan amqp.Confirmation){ack}}
+	p := publisher(b)
+
+	require.NoError(t, p.Publish(context.Background(), "product.created", "42", []byte(`{"id":1}`)))
+	require.Len(t, b.sent, 1)
+
+	msg := b.sent[0]
+	require.Equal(t, "42", msg.MessageId)
+	require.Equal(t, "product.created", msg.Type)
+	require.Equal(t, amqp.Persistent, msg.DeliveryMode)
+	require.Equal(t, "application/json", msg.ContentType)
+	require.Equal(t, []byte(`{"id":1}`), msg.Body)
+}
+
+func TestPublishReconnects(t *testing.T) {
+	t.Parallel()
+
+	b := &fakeBroker{dialErr: errBroker}
+	p := publisher(b)
+
+	err := p.Publish(context.Background(), "product.updated", "1", []byte(`{}`))
+	require.ErrorIs(t, err, errBroker)
+
+	// A failed publish drops the session as well.
+	b.dialErr = nil
+	err = p.Publish(context.Background(), "product.updated", "1", []byte(`{}`))
+	require.ErrorIs(t, err, amqp.ErrClosed)
+	require.Equal(t, 1, b.closed)
+
+	b.confirms = []func(chan amqp.Confirmation){ack}
+	require.NoError(t, p.Publish(context.Background(), "product.updated", "1", []byte(`{}`)))
+	require.Equal(t, 3, b.dials)
+}
+
+func TestPublishCanceled(t *testing.T) {
+	t.Parallel()
+
+	b := &fakeBroker{confirms: []func(chan amqp.Confirmation){lost}}
+	p := New("amqp://broker", "products", ConfirmTimeout(time.Minute))
+	p.dial = b.dial
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := p.Publish(ctx, "product.updated", "1", []byte(`{}`))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, b.closed)
+}
+
+func TestShutdown(t *testing.T) {
+	t.Parallel()
+
+	b := &fakeBroker{confirms: []func(chan amqp.Confirmation){ack}}
+	p := publisher(b)
+
+	// Without a session there is nothing to close.
+	require.NoError(t, p.Shutdown())
+	require.Zero(t, b.closed)
+
+	require.NoError(t, p.Publish(context.Background(), "product.updated", "1", []byte(`{}`)))
+	require.NoError(t, p.Shutdown())
+	require.Equal(t, 1, b.closed)
+	require.NoError(t, p.Shutdown())
+	require.Equal(t, 1, b.closed)
+}
@@ -0,0 +1,27 @@
+package worker
+
+import "time"
+
+// Option -.
+type Option func(*Worker)
+
+// Interval -.
+func Interval(interval time.Duration) Option {
+	return func(w *Worker) {
+		w.interval = interval
+	}
+}
+
+// ShutdownTimeout -.
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(w *Worker) {
+		w.shutdownTimeout = timeout
+	}
+}
+
+// RunOnStart runs the job once right away instead of waiting for the first interval.
+func RunOnStart() Option {
+	return func(w *Worker) {
+		w.runOnStart = true
+	}
+}
@@ -0,0 +1,96 @@
+// Package worker implements a periodic background job.
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dariuszdroba/go-from-template/pkg/logger"
+)
+
+const (
+	_defaultInterval        = time.Minute
+	_defaultShutdownTimeout = 3 * time.Second
+)
+
+// ErrShutdownTimeout -.
+var ErrShutdownTimeout = errors.New("worker - Shutdown - timeout")
+
+// Job -.
+type Job func(ctx context.Context) error
+
+// Worker runs a Job every interval until Shutdown. Job errors are logged, not fatal.
+type Worker struct {
+	name            string
+	job             Job
+	logger          logger.Interface
+	interval        time.Duration
+	shutdownTimeout time.Duration
+	runOnStart      bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New -.
+func New(name string, job Job, l logger.Interface, opts ...Option) *Worker {
+	w := &Worker{
+		name:            name,
+		job:             job,
+		logger:          l,
+		interval:        _defaultInterval,
+		shutdownTimeout: _defaultShutdownTimeout,
+		done:            make(chan struct{}),
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	go w.run(ctx)
+
+	return w
+}
+
+func (w *Worker) run(ctx context.Context) {
+	defer close(w.done)
+
+	if w.runOnStart {
+		w.runJob(ctx)
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runJob(ctx)
+		}
+	}
+}
+
+func (w *Worker) runJob(ctx context.Context) {
+	if err := w.job(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		w.logger.Error(err, "worker - "+w.name)
+	}
+}
+
+// Shutdown stops the ticker and waits for a running job to return.
+func (w *Worker) Shutdown() error {
+	w.cancel()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-time.After(w.shutdownTimeout):
+		return ErrShutdownTimeout
+	}
+}